@@ -134,7 +134,7 @@ export default function OceanMap() {
 
 function journeyLabel(t: string): string {
   switch (t) {
-    case "released":
+    case "cast":
       return "Cast";
     case "drift":
       return "Drift";
@@ -24,8 +24,17 @@ export type BottleEvent = {
   lat: number;
   lng: number;
   created_at: string;
+  // Shape depends on event_type; absent on events from before payloads.
+  payload?: EventPayload;
 };
 
+export type EventPayload =
+  | { nickname: string; bottle_style: number } // cast
+  | { bearing_deg: number; speed_kmh: number; distance_km: number } // drift
+  | { nickname: string; seal: string; note?: string } // stamp
+  | { nickname?: string } // re_released
+  | { reason: "beached" | "expired" | "moderated" }; // sink
+
 export type Journey = {
   bottle: Bottle;
   events: BottleEvent[];
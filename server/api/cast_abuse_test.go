@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 	"github.com/Polqt/ocealis/api"
 	"github.com/Polqt/ocealis/api/handler"
 	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/cast"
 	"github.com/Polqt/ocealis/internal/domain"
 	"github.com/Polqt/ocealis/internal/service"
 	"github.com/Polqt/ocealis/ws"
@@ -35,11 +37,15 @@ func (c captchaStub) Verify(ctx context.Context, token, ip string) error {
 type castRecordingSvc struct {
 	last service.CreateBottleInput
 	got  bool
+	err  error // returned instead of a Bottle when set
 }
 
 func (f *castRecordingSvc) CreateBottle(ctx context.Context, in service.CreateBottleInput) (*domain.Bottle, error) {
 	f.got = true
 	f.last = in
+	if f.err != nil {
+		return nil, f.err
+	}
 	return &domain.Bottle{
 		ID:          1,
 		Nickname:    in.Nickname,
@@ -55,14 +61,28 @@ func (f *castRecordingSvc) GetBottle(context.Context, int32) (*domain.Bottle, er
 func (f *castRecordingSvc) GetJourney(context.Context, int32) (*domain.Journey, error) {
 	return nil, nil
 }
+func (f *castRecordingSvc) GetJourneyHighlights(context.Context, int32, int) (*domain.Journey, error) {
+	return nil, nil
+}
+func (f *castRecordingSvc) TranslateBottle(context.Context, int32, string) (*domain.TranslatedBottle, error) {
+	return nil, service.ErrTranslationDisabled
+}
+func (f *castRecordingSvc) BottleVersion(context.Context, int32) (int32, error) {
+	return 0, nil
+}
 func (f *castRecordingSvc) DiscoverBottle(context.Context, service.DiscoverBottleInput) (*domain.Journey, error) {
 	return nil, nil
 }
-func (f *castRecordingSvc) ReleaseBottle(context.Context, int32, int32, float64, float64) (*domain.Bottle, error) {
+func (f *castRecordingSvc) ReleaseBottle(context.Context, service.ReleaseBottleInput) (*domain.Bottle, error) {
 	return nil, nil
 }
 
 func castApp(t *testing.T, verify middleware.TurnstileVerifier, svc service.BottleService) *fiber.App {
+	t.Helper()
+	return castAppWithGuard(t, verify, nil, svc)
+}
+
+func castAppWithGuard(t *testing.T, verify middleware.TurnstileVerifier, dupes middleware.DuplicateGuard, svc service.BottleService) *fiber.App {
 	t.Helper()
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c fiber.Ctx, err error) error {
@@ -77,7 +97,7 @@ func castApp(t *testing.T, verify middleware.TurnstileVerifier, svc service.Bott
 	})
 	api.RegisterRoutes(app, api.Handlers{
 		Health:    &handler.HealthHandler{},
-		Bottle:    handler.NewBottleHandler(svc, verify),
+		Bottle:    handler.NewBottleHandler(svc, verify, dupes),
 		Event:     handler.NewEventHandler(nil),
 		Discovery: handler.NewDiscoveryHandler(nil),
 	}, ws.NewHub(), zap.NewNop())
@@ -161,3 +181,64 @@ func TestCastRejectsOverLimitViaAPI(t *testing.T) {
 		t.Fatalf("want 422, got %d body=%s", resp.StatusCode, b)
 	}
 }
+
+type fingerprintStub struct {
+	fps []uint64
+}
+
+func (f fingerprintStub) RecentFingerprints(context.Context, time.Time) ([]uint64, error) {
+	return f.fps, nil
+}
+
+func TestCastRejectsNearDuplicateFlood(t *testing.T) {
+	spam := "Buy cheap pills at pharma-example.com now, best prices in the ocean"
+	seen := cast.Fingerprint(spam)
+	guard := &middleware.NearDuplicateGuard{
+		Source:      fingerprintStub{fps: []uint64{seen, seen, seen}},
+		Limit:       3,
+		Window:      10 * time.Minute,
+		MaxDistance: 10,
+	}
+
+	svc := &castRecordingSvc{}
+	app := castAppWithGuard(t, captchaStub{ok: true}, guard, svc)
+
+	body, _ := json.Marshal(map[string]any{
+		"nickname":        "sailor",
+		"message_text":    "BUY cheap pills at pharma-example.com today!! best prices in the ocean",
+		"turnstile_token": "ok",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bottles", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		b, _ := io.ReadAll(resp.Body)
+		t.Fatalf("want 429, got %d body=%s", resp.StatusCode, b)
+	}
+	if svc.got {
+		t.Fatal("near-duplicate Cast must not reach the service")
+	}
+}
+
+func TestCastDuplicateGuardSeesThroughZeroWidthPadding(t *testing.T) {
+	spam := "Buy cheap pills at pharma-example.com now, best prices in the ocean"
+	seen := cast.Fingerprint(spam)
+	guard := &middleware.NearDuplicateGuard{
+		Source:      fingerprintStub{fps: []uint64{seen, seen, seen}},
+		Limit:       3,
+		Window:      10 * time.Minute,
+		MaxDistance: 10,
+	}
+
+	padded := strings.Join(strings.Split(spam, ""), "\u200b")
+	if err := guard.Check(context.Background(), padded); !errors.Is(err, middleware.ErrNearDuplicate) {
+		t.Fatalf("zero-width padded flood: %v, want ErrNearDuplicate", err)
+	}
+	if err := guard.Check(context.Background(), "\u200b\u200b"); err != nil {
+		t.Fatalf("a Message cleaning refuses is Cast's to reject, got %v", err)
+	}
+}
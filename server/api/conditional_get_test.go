@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// versionedSvc reports a Bottle version and counts the loads a 304 should save.
+type versionedSvc struct {
+	fakeBottleSvc
+	version      int32
+	bottleLoads  int
+	journeyLoads int
+}
+
+func (f *versionedSvc) BottleVersion(context.Context, int32) (int32, error) { return f.version, nil }
+
+func (f *versionedSvc) GetBottle(ctx context.Context, id int32) (*domain.Bottle, error) {
+	f.bottleLoads++
+	return f.fakeBottleSvc.GetBottle(ctx, id)
+}
+
+func (f *versionedSvc) GetJourney(ctx context.Context, id int32) (*domain.Journey, error) {
+	f.journeyLoads++
+	return f.fakeBottleSvc.GetJourney(ctx, id)
+}
+
+func TestConditionalGetAnswers304ForCurrentCopies(t *testing.T) {
+	svc := &versionedSvc{fakeBottleSvc: fakeBottleSvc{bottle: &domain.Bottle{ID: 5, MessageText: "hi", Status: domain.BottleStatusDrifting}}, version: 41}
+	disc := &stubDiscovery{generation: 900}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(disc),
+	}, ws.NewHub(), zap.NewNop())
+	get := func(path, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get("/api/v1/bottles/5", "")
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp.StatusCode != http.StatusOK || etag != `W/"b41.w2"` || resp.Header.Get(fiber.HeaderCacheControl) != "public, no-cache" {
+		t.Fatalf("status %d, etag %q, cache-control %q", resp.StatusCode, etag, resp.Header.Get(fiber.HeaderCacheControl))
+	}
+	if !strings.Contains(resp.Header.Get(fiber.HeaderVary), "Ocealis-Wire-Version") {
+		t.Fatalf("vary %q", resp.Header.Get(fiber.HeaderVary))
+	}
+
+	resp = get("/api/v1/bottles/5", `"x", `+etag)
+	if resp.StatusCode != http.StatusNotModified || resp.Header.Get(fiber.HeaderETag) != etag || svc.bottleLoads != 1 {
+		t.Fatalf("status %d, etag %q, loads %d", resp.StatusCode, resp.Header.Get(fiber.HeaderETag), svc.bottleLoads)
+	}
+	// Strong spelling of the same tag matches weakly too.
+	if resp = get("/api/v1/bottles/5", `"b41.w2"`); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("strong spelling: status %d", resp.StatusCode)
+	}
+	// Wire version 1 bodies differ, so they carry their own tag.
+	if resp = get("/api/v1/bottles/5?wire_version=1", etag); resp.StatusCode != http.StatusOK || resp.Header.Get(fiber.HeaderETag) != `W/"b41.w1"` {
+		t.Fatalf("wire v1: status %d, etag %q", resp.StatusCode, resp.Header.Get(fiber.HeaderETag))
+	}
+	// Translations are tagged by language, and failures carry no validators.
+	if resp = get("/api/v1/bottles/5?lang=fr", etag); resp.StatusCode != http.StatusNotImplemented || resp.Header.Get(fiber.HeaderETag) != "" {
+		t.Fatalf("lang: status %d, etag %q", resp.StatusCode, resp.Header.Get(fiber.HeaderETag))
+	}
+
+	svc.version = 42 // an event landed
+	if resp = get("/api/v1/bottles/5", etag); resp.StatusCode != http.StatusOK || svc.bottleLoads != 3 {
+		t.Fatalf("after new event: status %d, loads %d", resp.StatusCode, svc.bottleLoads)
+	}
+
+	resp = get("/api/v1/bottles/5/journey", "")
+	if resp = get("/api/v1/bottles/5/journey", resp.Header.Get(fiber.HeaderETag)); resp.StatusCode != http.StatusNotModified || svc.journeyLoads != 1 {
+		t.Fatalf("journey: status %d, loads %d", resp.StatusCode, svc.journeyLoads)
+	}
+
+	const viewport = "/api/v1/discovery/map?min_lat=-10&max_lat=10&min_lng=-10&max_lng=10&zoom=3"
+	resp = get(viewport, "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(fiber.HeaderETag) != `W/"m900.w2"` || resp.Header.Get(fiber.HeaderCacheControl) != "public, max-age=30" {
+		t.Fatalf("map: status %d, etag %q, cache-control %q", resp.StatusCode, resp.Header.Get(fiber.HeaderETag), resp.Header.Get(fiber.HeaderCacheControl))
+	}
+	if resp = get(viewport, `W/"m900.w2"`); resp.StatusCode != http.StatusNotModified || disc.maps != 1 {
+		t.Fatalf("map revalidation: status %d, queries %d", resp.StatusCode, disc.maps)
+	}
+	disc.generation++ // drift tick
+	if resp = get(viewport, `W/"m900.w2"`); resp.StatusCode != http.StatusOK || disc.maps != 2 {
+		t.Fatalf("after tick: status %d, queries %d", resp.StatusCode, disc.maps)
+	}
+}
@@ -0,0 +1,193 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// stubDiscovery serves canned results and records what the handlers asked for.
+type stubDiscovery struct {
+	bottle   domain.Bottle
+	input    service.RandomInput
+	nearby   []service.BottleWithDistance
+	hits     *domain.CursorResult[domain.SearchHit]
+	searched service.SearchInput
+	// generation is what MapGeneration reports; maps counts BrowseMap calls.
+	generation int32
+	maps       int
+}
+
+func (f *stubDiscovery) FindNearby(context.Context, service.FindNearbyInput) (*domain.CursorResult[service.BottleWithDistance], error) {
+	return &domain.CursorResult[service.BottleWithDistance]{Data: f.nearby}, nil
+}
+
+func (f *stubDiscovery) BrowseMap(context.Context, service.BrowseMapInput) (discovery.MapResult, error) {
+	f.maps++
+	return discovery.MapResult{}, nil
+}
+
+func (f *stubDiscovery) MapGeneration(context.Context) (int32, error) {
+	return f.generation, nil
+}
+
+func (f *stubDiscovery) Search(_ context.Context, input service.SearchInput) (*domain.CursorResult[domain.SearchHit], error) {
+	f.searched = input
+	return f.hits, nil
+}
+
+func (f *stubDiscovery) Random(_ context.Context, input service.RandomInput) (*domain.Bottle, error) {
+	f.input = input
+	b := f.bottle
+	return &b, nil
+}
+
+// discoveryApp routes /api/v1/discovery to disc; get requests path under it.
+func discoveryApp(t *testing.T, disc *stubDiscovery) (get func(path string) *http.Response) {
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(nil, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(disc),
+	}, ws.NewHub(), zap.NewNop())
+
+	return func(path string) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/discovery"+path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+}
+
+func TestDiscoveryRandomServesOneCork(t *testing.T) {
+	disc := &stubDiscovery{bottle: domain.Bottle{ID: 11, MessageText: "hello, stranger", Status: domain.BottleStatusDrifting, IsReleased: true}}
+	get := discoveryApp(t, disc)
+
+	resp := get("/random?lat=-20.5&lng=75")
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Fatalf("status %d, cache-control %q", resp.StatusCode, resp.Header.Get("Cache-Control"))
+	}
+	var got domain.Bottle
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 11 || disc.input.Lat == nil || *disc.input.Lat != -20.5 || *disc.input.Lng != 75 {
+		t.Fatalf("got bottle %d, input %+v", got.ID, disc.input)
+	}
+
+	if resp := get("/random"); resp.StatusCode != fiber.StatusOK || disc.input.Lat != nil {
+		t.Fatalf("no position: status %d, input %+v", resp.StatusCode, disc.input)
+	}
+	for query, want := range map[string]int{
+		"?lat=10":          fiber.StatusUnprocessableEntity,
+		"?lng=10":          fiber.StatusUnprocessableEntity,
+		"?lat=91&lng=0":    fiber.StatusUnprocessableEntity,
+		"?lat=north&lng=0": fiber.StatusBadRequest,
+	} {
+		if resp := get("/random" + query); resp.StatusCode != want {
+			t.Fatalf("%s: status %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestDiscoveryNearbyKeepsDistance(t *testing.T) {
+	disc := &stubDiscovery{nearby: []service.BottleWithDistance{
+		{Bottle: domain.Bottle{ID: 9, MessageText: "ahoy", Status: domain.BottleStatusDrifting, IsReleased: true}, DistanceKm: 42.5},
+	}}
+	get := discoveryApp(t, disc)
+
+	resp := get("/?lat=10&lng=20")
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var page struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 {
+		t.Fatalf("page %+v", page)
+	}
+	got := page.Data[0]
+	if got["distance_km"] != 42.5 || got["id"] != float64(9) || got["wire_version"] != float64(domain.WireVersion) {
+		t.Fatalf("nearby Bottle %v", got)
+	}
+}
+
+func TestDiscoverySearchPagesByRankAndID(t *testing.T) {
+	lastID, lastRank := int32(40), float32(0.0607927)
+	disc := &stubDiscovery{hits: &domain.CursorResult[domain.SearchHit]{
+		Data:       []domain.SearchHit{{Bottle: domain.Bottle{ID: 40, MessageText: "the lighthouse keeper"}, Rank: lastRank}},
+		NextCursor: &domain.Cursor{LastID: &lastID, LastRank: &lastRank},
+		HasMore:    true,
+	}}
+	get := discoveryApp(t, disc)
+
+	resp := get("/search?q=%20lighthouse%20&bbox=-80,0,0,60&limit=1")
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var page struct {
+		Data       []map[string]any `json:"data"`
+		NextCursor struct {
+			LastID   int32   `json:"last_id"`
+			LastRank float32 `json:"last_rank"`
+		} `json:"next_cursor"`
+		HasMore bool `json:"has_more"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 1 || page.Data[0]["rank"] == nil || !page.HasMore {
+		t.Fatalf("page %+v", page)
+	}
+	if page.NextCursor.LastID != lastID || page.NextCursor.LastRank != lastRank {
+		t.Fatalf("next cursor %+v lost precision", page.NextCursor)
+	}
+	in := disc.searched
+	if in.Query != "lighthouse" || in.Limit != 1 || in.BBox == nil || in.BBox.MinLng != -80 || in.BBox.MaxLat != 60 || in.Cursor != nil {
+		t.Fatalf("search input %+v", in)
+	}
+
+	// The next page hands the cursor back exactly as served.
+	next := fmt.Sprintf("/search?q=lighthouse&cursor=%d&cursor_rank=%v", page.NextCursor.LastID, page.NextCursor.LastRank)
+	if resp := get(next); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("next page status %d", resp.StatusCode)
+	}
+	if c := disc.searched.Cursor; c == nil || *c.LastID != lastID || *c.LastRank != lastRank || disc.searched.BBox != nil {
+		t.Fatalf("next page input %+v", disc.searched)
+	}
+
+	for path, want := range map[string]int{
+		"/search":                       fiber.StatusUnprocessableEntity,
+		"/search?q=%20%20":              fiber.StatusUnprocessableEntity,
+		"/search?q=sea&cursor=3":        fiber.StatusUnprocessableEntity,
+		"/search?q=sea&limit=51":        fiber.StatusUnprocessableEntity,
+		"/search?q=sea&bbox=0,0,10":     fiber.StatusBadRequest,
+		"/search?q=sea&bbox=10,0,0,10":  fiber.StatusBadRequest,
+		"/search?q=sea&cursor_rank=0.5": fiber.StatusUnprocessableEntity,
+	} {
+		if resp := get(path); resp.StatusCode != want {
+			t.Fatalf("%s: status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
@@ -2,19 +2,25 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/Polqt/ocealis/api/middleware"
 	"github.com/Polqt/ocealis/internal/cast"
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+	"github.com/Polqt/ocealis/internal/export"
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/internal/translate"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
 )
 
 type createBottleRequest struct {
-	Nickname       string   `json:"nickname" validate:"required,min=1,max=24"`
-	MessageText    string   `json:"message_text" validate:"required,min=1,max=500"`
-	BottleStyle    int32    `json:"bottle_style" validate:"min=0,max=9"`
+	Nickname       string   `json:"nickname" validate:"required,max_graphemes=24"`
+	MessageText    string   `json:"message_text" validate:"required,max_graphemes=500"`
+	BottleStyle    int32    `json:"bottle_style" validate:"min=0"`
 	StartLat       *float64 `json:"start_lat" validate:"omitempty,min=-90,max=90"`
 	StartLng       *float64 `json:"start_lng" validate:"omitempty,min=-180,max=180"`
 	TurnstileToken string   `json:"turnstile_token" validate:"required"`
@@ -26,27 +32,55 @@ type DiscoverBottleRequest struct {
 }
 
 type releaseBottleRequest struct {
-	Lat float64 `json:"lat" validate:"required,min=-90,max=90"`
-	Lng float64 `json:"lng" validate:"required,min=-180,max=180"`
+	Lat      float64 `json:"lat" validate:"required,min=-90,max=90"`
+	Lng      float64 `json:"lng" validate:"required,min=-180,max=180"`
+	Nickname string  `json:"nickname" validate:"omitempty,max_graphemes=24"`
 }
 
+type getBottleRequest struct {
+	Lang string `query:"lang" validate:"omitempty,bcp47_language_tag,max=16"`
+}
+
+type journeyHighlightsRequest struct {
+	DriftSamples int `query:"drift_samples" validate:"omitempty,min=1,max=100"`
+}
+
+// defaultDriftSamples keeps a highlights Journey to roughly one waypoint per day of a month-long drift.
+const defaultDriftSamples = 24
+
 type BottleHandler struct {
-	svc      service.BottleService
+	svc       service.BottleService
 	turnstile middleware.TurnstileVerifier
-	validate *validator.Validate
+	dupes     middleware.DuplicateGuard
+	validate  *validator.Validate
 }
 
-func NewBottleHandler(svc service.BottleService, turnstile middleware.TurnstileVerifier) *BottleHandler {
+func NewBottleHandler(svc service.BottleService, turnstile middleware.TurnstileVerifier, dupes middleware.DuplicateGuard) *BottleHandler {
 	if turnstile == nil {
 		turnstile = middleware.AcceptTurnstile{}
 	}
+	if dupes == nil {
+		dupes = middleware.AcceptDuplicates{}
+	}
 	return &BottleHandler{
 		svc:       svc,
 		turnstile: turnstile,
-		validate:  validator.New(),
+		dupes:     dupes,
+		validate:  newTextValidator(),
 	}
 }
 
+// newTextValidator adds max_graphemes, a max that counts user-perceived characters
+// like cast does: the stock max counts runes, so a family emoji would cost seven.
+func newTextValidator() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("max_graphemes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		return err == nil && hygiene.Graphemes(fl.Field().String()) <= limit
+	})
+	return v
+}
+
 // CreateBottle is Cast — anonymous Visitor, no JWT, no caster tracking.
 func (h *BottleHandler) CreateBottle(c fiber.Ctx) error {
 	var req createBottleRequest
@@ -59,8 +93,17 @@ func (h *BottleHandler) CreateBottle(c fiber.Ctx) error {
 	}
 
 	if err := h.turnstile.Verify(c.Context(), req.TurnstileToken, c.IP()); err != nil {
+		metrics.TurnstileFailures.Inc()
 		return fiber.NewError(fiber.StatusForbidden, "cast blocked")
 	}
+	settlement := middleware.CastRefused
+	if single, ok := h.turnstile.(middleware.SingleUseVerifier); ok {
+		defer func() { single.Settle(req.TurnstileToken, settlement) }()
+	}
+
+	if err := h.dupes.Check(c.Context(), req.MessageText); err != nil {
+		return fiber.NewError(fiber.StatusTooManyRequests, "this message is already adrift")
+	}
 
 	// one of lat/lng missing → treat as no geo (basin fallback in service)
 	var lat, lng *float64
@@ -76,31 +119,84 @@ func (h *BottleHandler) CreateBottle(c fiber.Ctx) error {
 		StartLng:    lng,
 	})
 	if err != nil {
+		settlement = middleware.CastRetryable
 		switch {
 		case errors.Is(err, cast.ErrNicknameRequired),
 			errors.Is(err, cast.ErrNicknameTooLong),
+			errors.Is(err, cast.ErrNicknameReserved),
+			errors.Is(err, cast.ErrControlCharacters),
 			errors.Is(err, cast.ErrMessageRequired),
-			errors.Is(err, cast.ErrMessageTooLong):
+			errors.Is(err, cast.ErrMessageTooLong),
+			errors.Is(err, styles.ErrUnknownStyle),
+			errors.Is(err, styles.ErrReservedStyle):
 			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 		default:
 			return fiber.NewError(fiber.StatusInternalServerError, "could not cast bottle")
 		}
 	}
 
+	settlement = middleware.CastAccepted
 	return c.Status(fiber.StatusCreated).JSON(bottle)
 }
 
+// GetBottle serves one Bottle, weakly tagged with the Ocean generation for conditional GETs.
 func (h *BottleHandler) GetBottle(c fiber.Ctx) error {
 	id, err := parseID(c, "id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
 	}
 
+	var req getBottleRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid query")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	etag := h.bottleETag(c, "b", id, req.Lang)
+	if etagMatches(c, etag) {
+		return notModified(c, etag, bottleCacheControl)
+	}
+	if req.Lang != "" {
+		return h.translateBottle(c, id, req.Lang, etag)
+	}
+
 	bottle, err := h.svc.GetBottle(c.Context(), id)
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "bottle not found")
 	}
 
+	setValidators(c, etag, bottleCacheControl)
+	return c.Status(fiber.StatusOK).JSON(bottle)
+}
+
+// bottleETag tags a response derived from Bottle id by the Ocean generation. It is empty,
+// and the response served uncached, when the version cannot be read.
+func (h *BottleHandler) bottleETag(c fiber.Ctx, kind string, id int32, variant string) string {
+	version, err := h.svc.BottleVersion(c.Context(), id)
+	if err != nil || version == 0 {
+		return ""
+	}
+	return weakETag(c, kind, version, variant)
+}
+
+// translateBottle is GET /bottles/:id?lang=xx — the Bottle with its Message also in
+// the reader's language under "translation"; message_text stays the original.
+func (h *BottleHandler) translateBottle(c fiber.Ctx, id int32, target, etag string) error {
+	bottle, err := h.svc.TranslateBottle(c.Context(), id, target)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBottleNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "bottle not found")
+		case errors.Is(err, service.ErrTranslationDisabled):
+			return fiber.NewError(fiber.StatusNotImplemented, "translation is not enabled")
+		case errors.Is(err, translate.ErrUnsupported):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "cannot translate this message to "+target)
+		default:
+			return fiber.NewError(fiber.StatusBadGateway, "translation unavailable")
+		}
+	}
+	setValidators(c, etag, bottleCacheControl)
 	return c.Status(fiber.StatusOK).JSON(bottle)
 }
 
@@ -110,11 +206,75 @@ func (h *BottleHandler) GetJourney(c fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
 	}
 
+	etag := h.bottleETag(c, "j", id, "")
+	if etagMatches(c, etag) {
+		return notModified(c, etag, bottleCacheControl)
+	}
+
 	journey, err := h.svc.GetJourney(c.Context(), id)
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "journey not found")
 	}
 
+	setValidators(c, etag, bottleCacheControl)
+	return c.Status(fiber.StatusOK).JSON(journey)
+}
+
+// ExportJourney handles GET /bottles/:id/journey.{geojson,gpx,kml} — the Journey as a
+// download for map tools: drift as a line, milestones as points.
+func (h *BottleHandler) ExportJourney(c fiber.Ctx) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
+	}
+	format := export.Format(c.Params("format"))
+	switch format {
+	case export.FormatGeoJSON, export.FormatGPX, export.FormatKML:
+	default:
+		return fiber.NewError(fiber.StatusNotFound, "unknown export format")
+	}
+
+	journey, err := h.svc.GetJourney(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "journey not found")
+	}
+	body, err := export.Journey(format, journey)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "journey export failed")
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="bottle-%d.%s"`, id, format))
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// GetJourneyHighlights handles GET /bottles/:id/journey/highlights — every milestone
+// event plus ?drift_samples= (default 24) evenly spaced drift waypoints.
+func (h *BottleHandler) GetJourneyHighlights(c fiber.Ctx) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
+	}
+
+	var req journeyHighlightsRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid query parameters")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	if req.DriftSamples == 0 {
+		req.DriftSamples = defaultDriftSamples
+	}
+
+	journey, err := h.svc.GetJourneyHighlights(c.Context(), id, req.DriftSamples)
+	if err != nil {
+		if errors.Is(err, service.ErrBottleNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "journey not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "journey highlights failed")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(journey)
 }
 
@@ -155,7 +315,7 @@ func (h *BottleHandler) DiscoverBottle(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(journey)
 }
 
-// ReleaseBottle is Re-release — anonymous, Nickname in later issue.
+// ReleaseBottle is Re-release — anonymous, optional Nickname lands on the Journey event.
 func (h *BottleHandler) ReleaseBottle(c fiber.Ctx) error {
 	id, err := parseID(c, "id")
 	if err != nil {
@@ -170,9 +330,21 @@ func (h *BottleHandler) ReleaseBottle(c fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
 	}
 
-	bottle, err := h.svc.ReleaseBottle(c.Context(), id, 0, req.Lat, req.Lng)
+	bottle, err := h.svc.ReleaseBottle(c.Context(), service.ReleaseBottleInput{
+		BottleID: id,
+		Lat:      req.Lat,
+		Lng:      req.Lng,
+		Nickname: req.Nickname,
+	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, "could not re-release bottle")
+		switch {
+		case errors.Is(err, cast.ErrNicknameTooLong),
+			errors.Is(err, cast.ErrNicknameReserved),
+			errors.Is(err, cast.ErrControlCharacters):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, "could not re-release bottle")
+		}
 	}
 
 	return c.Status(fiber.StatusOK).JSON(bottle)
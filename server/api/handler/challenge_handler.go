@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ChallengeIssuer hands out proof-of-work challenges for the Cast abuse seam.
+type ChallengeIssuer interface {
+	Issue() (middleware.Challenge, error)
+}
+
+type ChallengeHandler struct {
+	issuer ChallengeIssuer
+}
+
+func NewChallengeHandler(issuer ChallengeIssuer) *ChallengeHandler {
+	return &ChallengeHandler{issuer: issuer}
+}
+
+// Issue handles GET /challenge — a signed nonce + difficulty to solve before Cast.
+func (h *ChallengeHandler) Issue(c fiber.Ctx) error {
+	challenge, err := h.issuer.Issue()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "could not issue challenge")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(challenge)
+}
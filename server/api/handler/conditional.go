@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Cache-Control for conditional GETs. A Bottle changes on any Open or Re-release, so
+// caches revalidate every time — a 304 costs one counter read. The map only moves on
+// drift ticks and releases, so a CDN may serve it a little while unchecked.
+const (
+	bottleCacheControl = "public, no-cache"
+	mapCacheControl    = "public, max-age=30"
+)
+
+// weakETag names one rendering of a versioned resource: kind and version, the wire
+// version the client negotiated, and any non-empty variant (a translation language)
+// that changes the body. Tags are weak because equal versions promise equivalent JSON,
+// not identical bytes — Seeds' timestamps shift between renderings.
+func weakETag(c fiber.Ctx, kind string, version int32, variants ...string) string {
+	parts := []string{fmt.Sprintf("%s%d", kind, version), "w" + c.GetRespHeader(middleware.WireVersionHeader)}
+	for _, v := range variants {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return `W/"` + strings.Join(parts, ".") + `"`
+}
+
+// etagMatches reports whether If-None-Match names etag, comparing weakly (RFC 9110
+// §13.1.2). An empty etag — version unknown — never matches.
+func etagMatches(c fiber.Ctx, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// setValidators marks a 200 or 304 cacheable under etag. Without an etag the response
+// is left as the handler would have sent it.
+func setValidators(c fiber.Ctx, etag, cacheControl string) {
+	if etag == "" {
+		return
+	}
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	c.Vary(middleware.WireVersionHeader)
+}
+
+// notModified answers a conditional GET whose copy is current.
+func notModified(c fiber.Ctx, etag, cacheControl string) error {
+	setValidators(c, etag, cacheControl)
+	return c.SendStatus(fiber.StatusNotModified)
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 
+	"github.com/Polqt/ocealis/internal/domain"
 	"github.com/Polqt/ocealis/internal/service"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
@@ -24,6 +26,21 @@ type browseMapRequest struct {
 	Zoom   *float64 `query:"zoom"    validate:"required,min=0,max=22"`
 }
 
+// searchRequest pages with the previous response's next_cursor: cursor is its last_id and
+// cursor_rank its last_rank.
+type searchRequest struct {
+	Q          string   `query:"q"           validate:"required,max=200"`
+	Limit      int32    `query:"limit"       validate:"omitempty,min=1,max=50"`
+	Cursor     *int32   `query:"cursor"      validate:"required_with=CursorRank"`
+	CursorRank *float32 `query:"cursor_rank" validate:"required_with=Cursor"`
+}
+
+// randomRequest places the caller optionally; lat and lng come together or not at all.
+type randomRequest struct {
+	Lat *float64 `query:"lat" validate:"required_with=Lng,omitempty,min=-90,max=90"`
+	Lng *float64 `query:"lng" validate:"required_with=Lat,omitempty,min=-180,max=180"`
+}
+
 type DiscoveryHandler struct {
 	svc      service.DiscoveryService
 	validate *validator.Validate
@@ -33,7 +50,8 @@ func NewDiscoveryHandler(svc service.DiscoveryService) *DiscoveryHandler {
 	return &DiscoveryHandler{svc: svc, validate: validator.New()}
 }
 
-// BrowseMap handles GET /discovery/map — heat vs Corks by zoom/viewport.
+// BrowseMap handles GET /discovery/map — heat vs Corks by zoom/viewport. Responses are
+// tagged with the Ocean generation so polling clients mostly get 304s.
 func (h *DiscoveryHandler) BrowseMap(c fiber.Ctx) error {
 	var req browseMapRequest
 	if err := c.Bind().Query(&req); err != nil {
@@ -46,6 +64,14 @@ func (h *DiscoveryHandler) BrowseMap(c fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "viewport bounds inverted")
 	}
 
+	var etag string
+	if gen, err := h.svc.MapGeneration(c.Context()); err == nil {
+		etag = weakETag(c, "m", gen)
+	}
+	if etagMatches(c, etag) {
+		return notModified(c, etag, mapCacheControl)
+	}
+
 	result, err := h.svc.BrowseMap(c.Context(), service.BrowseMapInput{
 		MinLat: *req.MinLat,
 		MaxLat: *req.MaxLat,
@@ -56,6 +82,7 @@ func (h *DiscoveryHandler) BrowseMap(c fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "map query failed")
 	}
+	setValidators(c, etag, mapCacheControl)
 	return c.Status(fiber.StatusOK).JSON(result)
 }
 
@@ -92,3 +119,55 @@ func (h *DiscoveryHandler) FindNearby(c fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(result)
 }
+
+// Search handles GET /discovery/search?q=&bbox=minLng,minLat,maxLng,maxLat — visible
+// Corks whose Message matches q (web-search syntax: "quoted phrases", or, -not), best
+// match first.
+func (h *DiscoveryHandler) Search(c fiber.Ctx) error {
+	var req searchRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid query parameters")
+	}
+	req.Q = strings.TrimSpace(req.Q)
+	if err := h.validate.Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	input := service.SearchInput{Query: req.Q, Limit: req.Limit}
+	if raw := c.Query("bbox"); raw != "" {
+		bbox, err := parseBBox(raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		input.BBox = &bbox
+	}
+	if req.Cursor != nil {
+		input.Cursor = &domain.Cursor{LastID: req.Cursor, LastRank: req.CursorRank}
+	}
+
+	result, err := h.svc.Search(c.Context(), input)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "search failed")
+	}
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// Random handles GET /discovery/random — one visible Cork picked at random, favouring
+// Bottles few have found or Stamped and, given ?lat=&lng=, the caller's basin.
+func (h *DiscoveryHandler) Random(c fiber.Ctx) error {
+	var req randomRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid query parameters")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	bottle, err := h.svc.Random(c.Context(), service.RandomInput{Lat: req.Lat, Lng: req.Lng})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "discovery failed")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store") // every call is a fresh draw
+	return c.Status(fiber.StatusOK).JSON(bottle)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import "github.com/gofiber/fiber/v3"
+
+// ErrorResponse is the body of every error the API answers with.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ErrorHandler renders a fiber.Error as ErrorResponse under its status; anything else
+// is a 500 that leaks nothing.
+func ErrorHandler(c fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	msg := "Internal Server Error"
+	if e, ok := err.(*fiber.Error); ok {
+		code = e.Code
+		msg = e.Message
+	}
+	return c.Status(code).JSON(ErrorResponse{Error: msg})
+}
@@ -10,6 +10,16 @@ import (
 
 var startTime = time.Now()
 
+// healthStatus is GET /api/health's body; Status and Database are "ok" or "unreachable".
+type healthStatus struct {
+	Status    string `json:"status"`
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	Uptime    string `json:"uptime"`
+	WSClients int    `json:"ws_clients"`
+	Database  string `json:"database"`
+}
+
 type HealthHandler struct {
 	pool *pgxpool.Pool
 	hub  *ws.Hub
@@ -34,12 +44,12 @@ func (h *HealthHandler) Check(c fiber.Ctx) error {
 		status = fiber.StatusServiceUnavailable
 	}
 
-	return c.Status(status).JSON(fiber.Map{
-		"status":     dbStatus,
-		"service":    "ocealis",
-		"version":    "1.0.0",
-		"uptime":     time.Since(startTime).String(),
-		"ws_clients": h.hub.ClientCount(),
-		"database":   dbStatus,
+	return c.Status(status).JSON(healthStatus{
+		Status:    dbStatus,
+		Service:   "ocealis",
+		Version:   "1.0.0",
+		Uptime:    time.Since(startTime).String(),
+		WSClients: h.hub.ClientCount(),
+		Database:  dbStatus,
 	})
 }
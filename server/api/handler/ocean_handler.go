@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// MIMEApplicationNDJSON is one JSON document per line, flushed as it is written.
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// defaultReplayStep matches the drift tick, so each frame is one tick.
+const defaultReplayStep = 15 * time.Minute
+
+// defaultNotableLimit is how many Bottles each ranking holds unless ?limit= asks otherwise.
+const defaultNotableLimit = 10
+
+type notableRequest struct {
+	Limit int32 `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type OceanHandler struct {
+	svc      service.OceanService
+	validate *validator.Validate
+}
+
+func NewOceanHandler(svc service.OceanService) *OceanHandler {
+	return &OceanHandler{svc: svc, validate: validator.New()}
+}
+
+// Stats handles GET /ocean/stats — Bottle counts for the splash globe. Clients watching
+// the Ocean live get the same document as ocean_stats WebSocket messages.
+func (h *OceanHandler) Stats(c fiber.Ctx) error {
+	stats, err := h.svc.Stats(c.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "ocean stats unavailable")
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=15")
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+// Notable handles GET /ocean/notable?limit= — anonymous rankings of drifting Bottles
+// by distance travelled, re-releases, Stamps, basins visited and age.
+func (h *OceanHandler) Notable(c fiber.Ctx) error {
+	var req notableRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid query parameters")
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultNotableLimit
+	}
+
+	rankings, err := h.svc.Notable(c.Context(), req.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "ocean rankings unavailable")
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	return c.Status(fiber.StatusOK).JSON(rankings)
+}
+
+// Replay handles GET /ocean/replay?from=&to=&bbox=minLng,minLat,maxLng,maxLat&step=15m —
+// Cork positions at every step, one NDJSON frame per line, interpolated between drift events.
+func (h *OceanHandler) Replay(c fiber.Ctx) error {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "from must be an RFC3339 time")
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "to must be an RFC3339 time")
+	}
+	bbox, err := parseBBox(c.Query("bbox"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	step := defaultReplayStep
+	if raw := c.Query("step"); raw != "" {
+		if step, err = time.ParseDuration(raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "step must be a duration like 15m or 1h")
+		}
+	}
+
+	timeline, err := h.svc.Replay(c.Context(), replay.Window{From: from, To: to, Step: step}, bbox)
+	if err != nil {
+		if errors.Is(err, replay.ErrEmptyWindow) || errors.Is(err, replay.ErrStepTooSmall) || errors.Is(err, replay.ErrTooManyFrames) {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "replay failed")
+	}
+
+	c.Set(fiber.HeaderContentType, MIMEApplicationNDJSON)
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		enc := json.NewEncoder(w)
+		for k := range timeline.Frames() {
+			if enc.Encode(timeline.Frame(k)) != nil || w.Flush() != nil {
+				return // client went away
+			}
+		}
+	})
+}
+
+// parseBBox reads minLng,minLat,maxLng,maxLat (GeoJSON order).
+func parseBBox(raw string) (replay.BBox, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return replay.BBox{}, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return replay.BBox{}, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+		}
+		v[i] = f
+	}
+	b := replay.BBox{MinLng: v[0], MinLat: v[1], MaxLng: v[2], MaxLat: v[3]}
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLng < -180 || b.MaxLng > 180 {
+		return replay.BBox{}, errors.New("bbox out of range")
+	}
+	if b.MinLat > b.MaxLat || b.MinLng > b.MaxLng {
+		return replay.BBox{}, errors.New("bbox bounds inverted")
+	}
+	return b, nil
+}
@@ -0,0 +1,543 @@
+package handler
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/export"
+	"github.com/Polqt/ocealis/internal/openapi"
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+)
+
+// OpenAPI is the document describing every route api.RegisterRoutes wires. Request
+// schemas are generated from the structs handlers bind, response schemas from the types
+// they render; the contract tests hold the rest — paths, statuses, hand-parsed
+// parameters — to what the handlers do.
+var OpenAPI = sync.OnceValue(buildOpenAPI)
+
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	return json.Marshal(OpenAPI())
+})
+
+// ServeOpenAPI handles GET /api/openapi.json. The document only changes with a deploy.
+func ServeOpenAPI(c fiber.Ctx) error {
+	body, err := openAPIJSON()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "openapi document unavailable")
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// apiSpec accumulates operations; its helpers keep the common responses uniform.
+type apiSpec struct {
+	doc *openapi.Document
+	gen *openapi.Generator
+}
+
+// add documents method on a Fiber route. Everything under /api/v1 negotiates a wire
+// version, so those operations gain its parameters and response header.
+func (s *apiSpec) add(method, route string, op *openapi.Operation) {
+	if strings.HasPrefix(route, "/api/v1/") {
+		wire := &openapi.Schema{Type: openapi.Types{"integer"}, Enum: []any{domain.WireV1, domain.WireV2}}
+		op.Parameters = append(op.Parameters,
+			&openapi.Parameter{Name: middleware.WireVersionHeader, In: "header", Description: "1 pins the pre-rename vocabulary; anything else is the current one.", Schema: wire},
+			&openapi.Parameter{Name: "wire_version", In: "query", Description: "Same as the " + middleware.WireVersionHeader + " header, for clients that cannot set headers.", Schema: wire},
+		)
+		for _, resp := range op.Responses {
+			if resp.Headers == nil {
+				resp.Headers = map[string]*openapi.Header{}
+			}
+			resp.Headers[middleware.WireVersionHeader] = &openapi.Header{Description: "The vocabulary the body uses.", Required: true, Schema: wire}
+		}
+	}
+	path := openapi.PathFromRoute(route)
+	if s.doc.Paths[path] == nil {
+		s.doc.Paths[path] = &openapi.PathItem{}
+	}
+	(*s.doc.Paths[path])[strings.ToLower(method)] = op
+}
+
+// ok is a JSON response rendering sample's type.
+func (s *apiSpec) ok(description string, sample any) *openapi.Response {
+	return s.content(description, fiber.MIMEApplicationJSON, s.gen.Response(sample))
+}
+
+func (s *apiSpec) content(description, mime string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: map[string]*openapi.MediaType{mime: {Schema: schema}}}
+}
+
+// fail is an error response; every error carries ErrorResponse.
+func (s *apiSpec) fail(description string) *openapi.Response {
+	return s.ok(description, ErrorResponse{})
+}
+
+// cached is a 200 that conditional GETs tag with a weak ETag.
+func cached(resp *openapi.Response) *openapi.Response {
+	resp.Headers = map[string]*openapi.Header{
+		fiber.HeaderETag:         {Description: "Weak tag of the resource's version; absent when the version is unknown.", Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+		fiber.HeaderCacheControl: {Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+	}
+	return resp
+}
+
+func notModifiedResponse() *openapi.Response {
+	return cached(&openapi.Response{Description: "If-None-Match named the current version."})
+}
+
+var (
+	bottleIDParam  = &openapi.Parameter{Name: "id", In: "path", Required: true, Description: "Bottle ID; Seeds have negative IDs.", Schema: &openapi.Schema{Type: openapi.Types{"integer"}, Format: "int32"}}
+	ifNoneMatch    = &openapi.Parameter{Name: fiber.HeaderIfNoneMatch, In: "header", Description: "ETags of copies the client holds; compared weakly.", Schema: &openapi.Schema{Type: openapi.Types{"string"}}}
+	idempotencyKey = &openapi.Parameter{
+		Name: middleware.IdempotencyKeyHeader, In: "header",
+		Description: "Retries with the same key replay the first response instead of repeating the mutation.",
+		Schema:      &openapi.Schema{Type: openapi.Types{"string"}, MaxLength: ptr(middleware.MaxIdempotencyKeyLen)},
+	}
+	bboxParam = func(required bool) *openapi.Parameter {
+		return &openapi.Parameter{Name: "bbox", In: "query", Required: required, Description: "minLng,minLat,maxLng,maxLat (GeoJSON order).", Schema: &openapi.Schema{Type: openapi.Types{"string"}}}
+	}
+)
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: map[string]*openapi.MediaType{fiber.MIMEApplicationJSON: {Schema: schema}}}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func buildOpenAPI() *openapi.Document {
+	s := &apiSpec{
+		doc: &openapi.Document{
+			OpenAPI: openapi.Version,
+			Info: openapi.Info{
+				Title:       "Ocealis API",
+				Version:     "1.0.0",
+				Description: "Anonymous Visitors Cast messages in bottles that drift across a shared Ocean. Bodies use wire version 2 unless a client pins version 1.",
+			},
+			Paths: map[string]*openapi.PathItem{},
+		},
+		gen: openapi.NewGenerator(),
+	}
+	describeTypes(s.gen)
+
+	s.add(fiber.MethodGet, "/", &openapi.Operation{
+		OperationID: "getServiceInfo",
+		Tags:        []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": s.content("Service name and version.", fiber.MIMEApplicationJSON, s.gen.Define("ServiceInfo", &openapi.Schema{
+				Type: openapi.Types{"object"},
+				Properties: map[string]*openapi.Schema{
+					"service": {Type: openapi.Types{"string"}},
+					"version": {Type: openapi.Types{"string"}},
+				},
+				Required:             []string{"service", "version"},
+				AdditionalProperties: false,
+			})),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/health", &openapi.Operation{
+		OperationID: "getHealth",
+		Tags:        []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("The database answers.", healthStatus{}),
+			"503": s.ok("The database is unreachable.", healthStatus{}),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/openapi.json", &openapi.Operation{
+		OperationID: "getOpenAPI",
+		Tags:        []string{"meta"},
+		Summary:     "This document.",
+		Responses: map[string]*openapi.Response{
+			"200": s.content("OpenAPI 3.1 document.", fiber.MIMEApplicationJSON, &openapi.Schema{Type: openapi.Types{"object"}, Required: []string{"openapi", "info", "paths"}}),
+		},
+	})
+	s.add(fiber.MethodGet, "/metrics", &openapi.Operation{
+		OperationID: "getMetrics",
+		Tags:        []string{"meta"},
+		Summary:     "Prometheus exposition.",
+		Responses: map[string]*openapi.Response{
+			"200": s.content("Metrics in the Prometheus text format.", fiber.MIMETextPlain, &openapi.Schema{Type: openapi.Types{"string"}}),
+		},
+	})
+	s.add(fiber.MethodGet, "/ws", &openapi.Operation{
+		OperationID: "streamOcean",
+		Tags:        []string{"realtime"},
+		Summary:     "WebSocket stream of drift, Open, Re-release and Ocean stats messages.",
+		Description: "Upgrade to a WebSocket. The server sends StreamMessage frames; the client may send StreamSubscription frames.",
+		Responses: map[string]*openapi.Response{
+			"101": {Description: "Switching to the WebSocket protocol."},
+			"426": s.fail("The request did not ask to upgrade."),
+		},
+		Messages: &openapi.Messages{Server: openapi.Ref("StreamMessage"), Client: s.gen.Response(ws.SubMessage{})},
+	})
+	s.add(fiber.MethodGet, "/b/:id", &openapi.Operation{
+		OperationID: "getSharePage",
+		Tags:        []string{"share"},
+		Summary:     "Open Graph page for a Bottle's share link; redirects people to the client.",
+		Parameters:  []*openapi.Parameter{bottleIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": s.content("HTML carrying og: tags for the share card.", fiber.MIMETextHTMLCharsetUTF8, &openapi.Schema{Type: openapi.Types{"string"}}),
+			"400": s.fail("The ID is not a number."),
+			"404": s.fail("No such Bottle."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The page could not be rendered."),
+		},
+	})
+
+	s.add(fiber.MethodGet, "/api/v1/challenge", &openapi.Operation{
+		OperationID: "issueChallenge",
+		Tags:        []string{"bottles"},
+		Summary:     "Proof-of-work challenge to solve before Cast.",
+		Description: "Served only when Cast is guarded by the self-hosted proof-of-work verifier.",
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("A signed challenge.", middleware.Challenge{}),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("No challenge could be issued."),
+		},
+	})
+	s.bottles()
+	s.add(fiber.MethodGet, "/api/v1/styles", &openapi.Operation{
+		OperationID: "listStyles",
+		Tags:        []string{"bottles"},
+		Summary:     "The bottle_style catalog, reserved styles included.",
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("Every style.", []styles.Style{}),
+			"429": s.fail("Rate limited."),
+		},
+	})
+	s.discovery()
+	s.ocean()
+
+	s.doc.Components.Schemas = s.gen.Schemas()
+	return s.doc
+}
+
+// describeTypes adds what reflection cannot see: enums, and the wire_version that the
+// domain types' MarshalJSON stamps.
+func describeTypes(g *openapi.Generator) {
+	g.Name(ws.DriftPayload{}, "StreamDriftPayload")
+	g.Name(healthStatus{}, "HealthStatus")
+	g.Name(replay.Cork{}, "ReplayCork")
+	g.Name(replay.Frame{}, "ReplayFrame")
+	g.Name(ws.SubMessage{}, "StreamSubscription")
+
+	wireVersioned := func(s *openapi.Schema) {
+		s.Properties["wire_version"] = &openapi.Schema{Type: openapi.Types{"integer"}, Enum: []any{domain.WireV1, domain.WireV2}}
+		s.Required = append(s.Required, "wire_version")
+	}
+	for _, sample := range []any{domain.Bottle{}, domain.BottleEvent{}, domain.SearchHit{}, domain.TranslatedBottle{}, service.BottleWithDistance{}} {
+		g.Extend(sample, wireVersioned)
+	}
+
+	g.Extend(domain.BottleStatus(""), func(s *openapi.Schema) {
+		s.Enum = []any{domain.BottleStatusDrifting, domain.BottleStatusMysteryDelay, domain.BottleStatusSunk, domain.BottleStatusClaimed}
+	})
+	g.Extend(domain.EventType(""), func(s *openapi.Schema) {
+		s.Enum = []any{domain.EventTypeCast, domain.EventTypeDrift, domain.EventTypeStamp, domain.EventTypeReReleased, domain.EventTypeSink, domain.EventTypeOpenedLegacy}
+	})
+	g.Extend(domain.SinkReason(""), func(s *openapi.Schema) {
+		s.Enum = []any{domain.SinkReasonBeached, domain.SinkReasonExpired, domain.SinkReasonModerated}
+	})
+	g.Extend(discovery.MapResult{}, func(s *openapi.Schema) {
+		s.Properties["mode"].Enum = []any{"heat", "corks"}
+	})
+	g.Extend(ws.SubMessage{}, func(s *openapi.Schema) {
+		s.Properties["action"].Enum = []any{"subscribe", "unsubscribe"}
+		s.Properties["topic"].Description = `"bottle:<id>" or "region:<basin>".`
+	})
+	g.Extend(domain.BottleEvent{}, func(s *openapi.Schema) {
+		s.Properties["payload"] = &openapi.Schema{
+			Description: "Detail for event_type; absent on events recorded before payloads.",
+			OneOf: []*openapi.Schema{
+				g.Response(domain.CastPayload{}), g.Response(domain.DriftPayload{}), g.Response(domain.StampPayload{}),
+				g.Response(domain.ReReleasePayload{}), g.Response(domain.SinkPayload{}),
+			},
+		}
+	})
+
+	// One component per stream message, each pinning its type to its payload.
+	stream := &openapi.Schema{Discriminator: &openapi.Discriminator{PropertyName: "type", Mapping: map[string]string{}}}
+	types := make([]ws.MessageType, 0, len(ws.Payloads))
+	for t := range ws.Payloads {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	for _, t := range types {
+		var name string
+		for _, word := range strings.Split(string(t), "_") {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+		ref := g.Define(name+"Message", &openapi.Schema{
+			Type: openapi.Types{"object"},
+			Properties: map[string]*openapi.Schema{
+				"type":    {Type: openapi.Types{"string"}, Const: string(t)},
+				"payload": g.Response(ws.Payloads[t]),
+			},
+			Required:             []string{"type", "payload"},
+			AdditionalProperties: false,
+		})
+		stream.OneOf = append(stream.OneOf, ref)
+		stream.Discriminator.Mapping[string(t)] = ref.Ref
+	}
+	g.Define("StreamMessage", stream)
+}
+
+func (s *apiSpec) bottles() {
+	s.add(fiber.MethodPost, "/api/v1/bottles/", &openapi.Operation{
+		OperationID: "castBottle",
+		Tags:        []string{"bottles"},
+		Summary:     "Cast — set a new Bottle adrift. Anonymous; a Turnstile or proof-of-work token is required.",
+		Parameters:  []*openapi.Parameter{idempotencyKey},
+		RequestBody: jsonBody(s.gen.Body(createBottleRequest{})),
+		Responses: map[string]*openapi.Response{
+			"201": s.ok("The Bottle, usually in Mystery Delay.", domain.Bottle{}),
+			"400": s.fail("The body is not JSON, or the idempotency key is too long."),
+			"403": s.fail("The abuse check failed."),
+			"409": s.fail("A request with this idempotency key is still in flight."),
+			"422": s.fail("A field is out of bounds, the style cannot be Cast, or the idempotency key was reused for another body."),
+			"429": s.fail("Rate limited, or the same Message is already adrift."),
+			"500": s.fail("The Bottle could not be Cast."),
+		},
+	})
+	getBottle := s.gen.Query(getBottleRequest{})
+	getBottle[0].Description = "Also render the Message in this language, under translation."
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id", &openapi.Operation{
+		OperationID: "getBottle",
+		Tags:        []string{"bottles"},
+		Summary:     "Open — read a Bottle, optionally with its Message translated.",
+		Parameters:  append([]*openapi.Parameter{bottleIDParam, ifNoneMatch}, getBottle...),
+		Responses: map[string]*openapi.Response{
+			"200": cached(s.content("The Bottle; with lang, a TranslatedBottle.", fiber.MIMEApplicationJSON, &openapi.Schema{
+				OneOf: []*openapi.Schema{s.gen.Response(domain.Bottle{}), s.gen.Response(domain.TranslatedBottle{})},
+			})),
+			"304": notModifiedResponse(),
+			"400": s.fail("The ID or query is malformed."),
+			"404": s.fail("No such Bottle."),
+			"422": s.fail("lang is not a language tag, or the Message cannot be translated to it."),
+			"429": s.fail("Rate limited."),
+			"501": s.fail("Translation is not enabled."),
+			"502": s.fail("The translation backend failed."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id/journey", &openapi.Operation{
+		OperationID: "getJourney",
+		Tags:        []string{"bottles"},
+		Summary:     "A Bottle and every Journey event.",
+		Parameters:  []*openapi.Parameter{bottleIDParam, ifNoneMatch},
+		Responses: map[string]*openapi.Response{
+			"200": cached(s.ok("The Journey.", domain.Journey{})),
+			"304": notModifiedResponse(),
+			"400": s.fail("The ID is not a number."),
+			"404": s.fail("No such Bottle."),
+			"429": s.fail("Rate limited."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id/journey.:format", &openapi.Operation{
+		OperationID: "exportJourney",
+		Tags:        []string{"bottles"},
+		Summary:     "The Journey as a map-tool download: drift as a line, milestones as points.",
+		Parameters: []*openapi.Parameter{bottleIDParam, {
+			Name: "format", In: "path", Required: true,
+			Schema: &openapi.Schema{Type: openapi.Types{"string"}, Enum: []any{export.FormatGeoJSON, export.FormatGPX, export.FormatKML}},
+		}},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The export, as an attachment.", Content: map[string]*openapi.MediaType{
+				export.FormatGeoJSON.ContentType(): {Schema: &openapi.Schema{Type: openapi.Types{"object"}}},
+				export.FormatGPX.ContentType():     {Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+				export.FormatKML.ContentType():     {Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+			}},
+			"400": s.fail("The ID is not a number."),
+			"404": s.fail("No such Bottle, or an unknown format."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The export failed."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id/card.png", &openapi.Operation{
+		OperationID: "getShareCard",
+		Tags:        []string{"share"},
+		Summary:     "The Bottle's postcard image for share links.",
+		Parameters:  []*openapi.Parameter{bottleIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": s.content("The card.", "image/png", &openapi.Schema{Type: openapi.Types{"string"}, Format: "binary"}),
+			"400": s.fail("The ID is not a number."),
+			"404": s.fail("No such Bottle."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The card could not be rendered."),
+		},
+	})
+	highlights := s.gen.Query(journeyHighlightsRequest{})
+	highlights[0].Description = "Evenly spaced drift waypoints to keep; defaults to 24."
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id/journey/highlights", &openapi.Operation{
+		OperationID: "getJourneyHighlights",
+		Tags:        []string{"bottles"},
+		Summary:     "A Journey thinned to its milestones and a sample of drift.",
+		Parameters:  append([]*openapi.Parameter{bottleIDParam}, highlights...),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("The thinned Journey.", domain.Journey{}),
+			"400": s.fail("The ID or query is malformed."),
+			"404": s.fail("No such Bottle."),
+			"422": s.fail("drift_samples is out of bounds."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The highlights could not be read."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/bottles/:id/events", &openapi.Operation{
+		OperationID: "listBottleEvents",
+		Tags:        []string{"bottles"},
+		Summary:     "A Bottle's Journey events, a page at a time, oldest first.",
+		Parameters: []*openapi.Parameter{
+			bottleIDParam,
+			{Name: "cursor", In: "query", Description: "last_id from the previous page's next_cursor.", Schema: &openapi.Schema{Type: openapi.Types{"integer"}, Format: "int32", Minimum: ptr(1.0)}},
+			{Name: "limit", In: "query", Description: "Page size; defaults to 20.", Schema: &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: ptr(1.0), Maximum: ptr(100.0)}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("One page of events.", domain.CursorResult[domain.BottleEvent]{}),
+			"400": s.fail("The ID, cursor or limit is malformed or out of bounds."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The events could not be read."),
+		},
+	})
+	s.add(fiber.MethodPost, "/api/v1/bottles/:id/discover", &openapi.Operation{
+		OperationID: "discoverBottle",
+		Tags:        []string{"bottles"},
+		Summary:     "Legacy claim path, kept for old clients; Open is GET /bottles/{id}.",
+		Parameters:  []*openapi.Parameter{bottleIDParam},
+		RequestBody: jsonBody(s.gen.Body(DiscoverBottleRequest{})),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("The Journey after the claim.", domain.Journey{}),
+			"400": s.fail("The ID or body is malformed."),
+			"403": s.fail("A sender cannot claim their own Bottle."),
+			"404": s.fail("No such Bottle."),
+			"409": s.fail("The Bottle was already claimed."),
+			"422": s.fail("A coordinate is missing or out of bounds."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The Bottle could not be opened."),
+		},
+	})
+	s.add(fiber.MethodPost, "/api/v1/bottles/:id/release", &openapi.Operation{
+		OperationID: "reReleaseBottle",
+		Tags:        []string{"bottles"},
+		Summary:     "Re-release — send a found Bottle back out from the finder's Shoreline.",
+		Parameters:  []*openapi.Parameter{bottleIDParam, idempotencyKey},
+		RequestBody: jsonBody(s.gen.Body(releaseBottleRequest{})),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("The Bottle, back in Mystery Delay.", domain.Bottle{}),
+			"400": s.fail("The ID or body is malformed, or the idempotency key is too long."),
+			"409": s.fail("A request with this idempotency key is still in flight."),
+			"422": s.fail("A field is out of bounds, or the idempotency key was reused for another body."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The Bottle could not be re-released."),
+		},
+	})
+}
+
+func (s *apiSpec) discovery() {
+	s.add(fiber.MethodGet, "/api/v1/discovery/", &openapi.Operation{
+		OperationID: "findNearby",
+		Tags:        []string{"discovery"},
+		Summary:     "Visible Corks near a point, nearest first.",
+		Parameters:  s.gen.Query(findNearbyRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("One page of Bottles with their distance.", domain.CursorResult[service.BottleWithDistance]{}),
+			"400": s.fail("The query is malformed."),
+			"422": s.fail("A parameter is missing or out of bounds."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("Discovery failed."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/discovery/map", &openapi.Operation{
+		OperationID: "browseMap",
+		Tags:        []string{"discovery"},
+		Summary:     "Heat cells when zoomed out, Corks when zoomed in, for a viewport.",
+		Parameters:  append(s.gen.Query(browseMapRequest{}), ifNoneMatch),
+		Responses: map[string]*openapi.Response{
+			"200": cached(s.ok("The viewport's heat or Corks.", discovery.MapResult{})),
+			"304": notModifiedResponse(),
+			"400": s.fail("The query is malformed or the bounds are inverted."),
+			"422": s.fail("A bound is missing or out of range."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The map query failed."),
+		},
+	})
+	search := s.gen.Query(searchRequest{})
+	search[0].Description = `Web-search syntax: "quoted phrases", or, -not.`
+	s.add(fiber.MethodGet, "/api/v1/discovery/search", &openapi.Operation{
+		OperationID: "searchMessages",
+		Tags:        []string{"discovery"},
+		Summary:     "Visible Corks whose Message matches q, best match first.",
+		Parameters:  append(search, bboxParam(false)),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("One page of hits; page on with next_cursor's last_id and last_rank.", domain.CursorResult[domain.SearchHit]{}),
+			"400": s.fail("The query or bbox is malformed."),
+			"422": s.fail("q is missing or too long, or a cursor half is missing."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("Search failed."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/discovery/random", &openapi.Operation{
+		OperationID: "randomBottle",
+		Tags:        []string{"discovery"},
+		Summary:     "One visible Cork at random, favouring the caller's basin; a Seed on an empty Ocean.",
+		Parameters:  s.gen.Query(randomRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("A Bottle.", domain.Bottle{}),
+			"400": s.fail("The query is malformed."),
+			"422": s.fail("Only one of lat and lng, or one out of range."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("Discovery failed."),
+		},
+	})
+}
+
+func (s *apiSpec) ocean() {
+	s.add(fiber.MethodGet, "/api/v1/ocean/stats", &openapi.Operation{
+		OperationID: "getOceanStats",
+		Tags:        []string{"ocean"},
+		Summary:     "Bottle counts for the splash globe; also streamed as ocean_stats messages.",
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("The counts.", domain.OceanStats{}),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The counts are unavailable."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/ocean/replay", &openapi.Operation{
+		OperationID: "replayOcean",
+		Tags:        []string{"ocean"},
+		Summary:     "Cork positions at every step of a window, one NDJSON frame per line.",
+		Parameters: []*openapi.Parameter{
+			{Name: "from", In: "query", Required: true, Schema: &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"}},
+			{Name: "to", In: "query", Required: true, Schema: &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"}},
+			bboxParam(true),
+			{Name: "step", In: "query", Description: "A Go duration such as 15m or 1h; defaults to the drift tick, 15m.", Schema: &openapi.Schema{Type: openapi.Types{"string"}}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": s.content("A stream of frames, each line one ReplayFrame.", MIMEApplicationNDJSON, s.gen.Response(replay.Frame{})),
+			"400": s.fail("A parameter is malformed."),
+			"422": s.fail("The window is empty, the step too small, or the frames too many."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The replay failed."),
+		},
+	})
+	s.add(fiber.MethodGet, "/api/v1/ocean/notable", &openapi.Operation{
+		OperationID: "getNotableBottles",
+		Tags:        []string{"ocean"},
+		Summary:     "Anonymous rankings of drifting Bottles with remarkable Journeys.",
+		Parameters:  s.gen.Query(notableRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": s.ok("Every ranking, best first; defaults to 10 Bottles each.", domain.NotableRankings{}),
+			"400": s.fail("The query is malformed."),
+			"422": s.fail("limit is out of bounds."),
+			"429": s.fail("Rate limited."),
+			"500": s.fail("The rankings are unavailable."),
+		},
+	})
+}
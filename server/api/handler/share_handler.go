@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/Polqt/ocealis/internal/card"
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/gofiber/fiber/v3"
+)
+
+// shareDescriptionRunes keeps og:description within what chat apps show before truncating.
+const shareDescriptionRunes = 180
+
+// sharePage is what a crawler unfurls; people are sent straight on to the client's Bottle page.
+var sharePage = template.Must(template.New("share").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="Ocealis">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.Image}}">
+<meta property="og:image:type" content="image/png">
+<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta http-equiv="refresh" content="0; url={{.URL}}">
+<link rel="canonical" href="{{.URL}}">
+</head>
+<body><a href="{{.URL}}">{{.Title}}</a></body>
+</html>
+`))
+
+type ShareHandler struct {
+	svc       service.BottleService
+	cards     *card.Cache
+	clientURL string
+}
+
+// NewShareHandler serves share cards from cards (a default-sized cache when nil) and
+// points the share page at clientURL's /bottle/:id route.
+func NewShareHandler(svc service.BottleService, cards *card.Cache, clientURL string) *ShareHandler {
+	if cards == nil {
+		cards = card.NewCache(card.DefaultCacheSize)
+	}
+	return &ShareHandler{svc: svc, cards: cards, clientURL: strings.TrimRight(clientURL, "/")}
+}
+
+// Card handles GET /bottles/:id/card.png — the Bottle's postcard image, re-rendered
+// only after new Journey events.
+func (h *ShareHandler) Card(c fiber.Ctx) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
+	}
+
+	journey, err := h.svc.GetJourney(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "bottle not found")
+	}
+	png, err := h.cards.Card(journey)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "card render failed")
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).Send(png)
+}
+
+// Page handles GET /b/:id — a tiny HTML page carrying Open Graph tags for the card,
+// which redirects people on to the client.
+func (h *ShareHandler) Page(c fiber.Ctx) error {
+	id, err := parseID(c, "id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid bottle id")
+	}
+
+	bottle, err := h.svc.GetBottle(c.Context(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "bottle not found")
+	}
+
+	description := []rune(strings.Join(strings.Fields(bottle.MessageText), " "))
+	if len(description) > shareDescriptionRunes {
+		description = append(description[:shareDescriptionRunes-1], '…')
+	}
+	var buf bytes.Buffer
+	if err := sharePage.Execute(&buf, map[string]any{
+		"Title":       fmt.Sprintf("A message in a bottle from %s", hygiene.Isolate(bottle.Nickname)),
+		"Description": string(description),
+		"URL":         fmt.Sprintf("%s/bottle/%d", h.clientURL, id),
+		"Image":       fmt.Sprintf("%s/api/v1/bottles/%d/card.png", c.BaseURL(), id),
+		"Width":       card.Width,
+		"Height":      card.Height,
+	}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "share page render failed")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
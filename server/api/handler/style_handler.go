@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ListStyles handles GET /styles — the bottle_style catalog, reserved styles included so
+// clients can draw Seeds. It only changes with a deploy.
+func ListStyles(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Status(fiber.StatusOK).JSON(styles.All())
+}
@@ -0,0 +1,189 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// memoryIdempotency is an in-process IdempotencyStore for HTTP seam tests.
+type memoryIdempotency struct {
+	mu   sync.Mutex
+	rows map[string]*domain.IdempotentResponse
+}
+
+func (m *memoryIdempotency) Claim(_ context.Context, key, hash string, _ time.Time) (*domain.IdempotentResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rows == nil {
+		m.rows = map[string]*domain.IdempotentResponse{}
+	}
+	if row, ok := m.rows[key]; ok {
+		stored := *row
+		return &stored, false, nil
+	}
+	m.rows[key] = &domain.IdempotentResponse{RequestHash: hash}
+	return nil, true, nil
+}
+
+func (m *memoryIdempotency) Complete(_ context.Context, key string, status int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[key].StatusCode = status
+	m.rows[key].Body = body
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rows, key)
+	return nil
+}
+
+type countingCastSvc struct {
+	castRecordingSvc
+	calls int
+}
+
+func (f *countingCastSvc) CreateBottle(ctx context.Context, in service.CreateBottleInput) (*domain.Bottle, error) {
+	f.calls++
+	b, err := f.castRecordingSvc.CreateBottle(ctx, in)
+	if b != nil {
+		b.ID = int32(f.calls)
+	}
+	return b, err
+}
+
+func idempotentCast(t *testing.T, app *fiber.App, key, message string) (int, []byte) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"nickname":        "sailor",
+		"message_text":    message,
+		"turnstile_token": "ok",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bottles", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, b
+}
+
+func TestIdempotentCastReplaysOriginalBottle(t *testing.T) {
+	svc := &countingCastSvc{}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:      &handler.HealthHandler{},
+		Bottle:      handler.NewBottleHandler(svc, captchaStub{ok: true}, nil),
+		Event:       handler.NewEventHandler(nil),
+		Discovery:   handler.NewDiscoveryHandler(nil),
+		Idempotency: &memoryIdempotency{},
+	}, ws.NewHub(), zap.NewNop())
+
+	code, first := idempotentCast(t, app, "retry-me", "hello ocean")
+	if code != http.StatusCreated {
+		t.Fatalf("first Cast want 201, got %d body=%s", code, first)
+	}
+	code, second := idempotentCast(t, app, "retry-me", "hello ocean")
+	if code != http.StatusCreated {
+		t.Fatalf("retry want replayed 201, got %d body=%s", code, second)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("retry must replay the original body\nfirst=%s\nsecond=%s", first, second)
+	}
+	if svc.calls != 1 {
+		t.Fatalf("retry must not Cast twice; service called %d times", svc.calls)
+	}
+
+	code, _ = idempotentCast(t, app, "retry-me", "a different message")
+	if code != http.StatusUnprocessableEntity {
+		t.Fatalf("key reused with different body want 422, got %d", code)
+	}
+}
+
+// blockingCastSvc holds every Cast until release is closed.
+type blockingCastSvc struct {
+	castRecordingSvc
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingCastSvc) CreateBottle(ctx context.Context, in service.CreateBottleInput) (*domain.Bottle, error) {
+	f.entered <- struct{}{}
+	<-f.release
+	return f.castRecordingSvc.CreateBottle(ctx, in)
+}
+
+func TestIdempotentCastInFlightAnswersConflict(t *testing.T) {
+	svc := &blockingCastSvc{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:      &handler.HealthHandler{},
+		Bottle:      handler.NewBottleHandler(svc, captchaStub{ok: true}, nil),
+		Event:       handler.NewEventHandler(nil),
+		Discovery:   handler.NewDiscoveryHandler(nil),
+		Idempotency: &memoryIdempotency{},
+	}, ws.NewHub(), zap.NewNop())
+
+	post := func() *http.Response {
+		body, _ := json.Marshal(map[string]any{
+			"nickname":        "sailor",
+			"message_text":    "hello ocean",
+			"turnstile_token": "ok",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/bottles", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "twice-at-once")
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 5 * time.Second})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return resp
+	}
+
+	first := make(chan *http.Response, 1)
+	go func() { first <- post() }()
+	<-svc.entered
+
+	resp := post()
+	if resp == nil {
+		t.FailNow()
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict || resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Fatalf("concurrent retry: status %d, Retry-After %q", resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter))
+	}
+
+	close(svc.release)
+	if resp := <-first; resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("original Cast: %+v", resp)
+	} else {
+		resp.Body.Close()
+	}
+	if resp := post(); resp == nil || resp.StatusCode != http.StatusCreated || resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry after completion: %+v", resp)
+	} else {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+func TestJourneyExportFormats(t *testing.T) {
+	svc := &journeySvc{
+		fakeBottleSvc: fakeBottleSvc{bottle: &domain.Bottle{ID: 7, Status: domain.BottleStatusDrifting}},
+		events: []domain.BottleEvent{
+			{ID: 1, BottleID: 7, EventType: domain.EventTypeCast, Lat: 10, Lng: 179},
+			{ID: 2, BottleID: 7, EventType: domain.EventTypeDrift, Lat: 12, Lng: -179},
+		},
+	}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	get := func(target string) (*http.Response, string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	for format, want := range map[string]struct{ contentType, marker string }{
+		"geojson": {"application/geo+json", `"MultiLineString"`},
+		"gpx":     {"application/gpx+xml", "<trkseg>"},
+		"kml":     {"application/vnd.google-earth.kml+xml", "<MultiGeometry>"},
+	} {
+		resp, body := get("/api/v1/bottles/7/journey." + format)
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("%s: status %d", format, resp.StatusCode)
+		}
+		if ct := resp.Header.Get(fiber.HeaderContentType); ct != want.contentType {
+			t.Fatalf("%s: content type %q", format, ct)
+		}
+		if cd := resp.Header.Get(fiber.HeaderContentDisposition); cd != `attachment; filename="bottle-7.`+format+`"` {
+			t.Fatalf("%s: disposition %q", format, cd)
+		}
+		if !strings.Contains(body, want.marker) {
+			t.Fatalf("%s: body missing %s:\n%s", format, want.marker, body)
+		}
+	}
+
+	if resp, _ := get("/api/v1/bottles/7/journey.shp"); resp.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("unknown format: want 404, got %d", resp.StatusCode)
+	}
+	if resp, _ := get("/api/v1/bottles/7/journey"); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("plain journey: status %d", resp.StatusCode)
+	}
+}
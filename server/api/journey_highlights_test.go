@@ -0,0 +1,69 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type highlightsSvc struct {
+	fakeBottleSvc
+	samples []int
+	missing bool
+}
+
+func (f *highlightsSvc) GetJourneyHighlights(_ context.Context, _ int32, driftSamples int) (*domain.Journey, error) {
+	f.samples = append(f.samples, driftSamples)
+	if f.missing {
+		return nil, service.ErrBottleNotFound
+	}
+	return &domain.Journey{Bottle: f.bottle}, nil
+}
+
+func TestJourneyHighlightsDriftSamples(t *testing.T) {
+	svc := &highlightsSvc{fakeBottleSvc: fakeBottleSvc{bottle: &domain.Bottle{ID: 7}}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	status := func(target string) int {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := status("/api/v1/bottles/7/journey/highlights"); got != fiber.StatusOK {
+		t.Fatalf("default: status %d", got)
+	}
+	if got := status("/api/v1/bottles/7/journey/highlights?drift_samples=5"); got != fiber.StatusOK {
+		t.Fatalf("drift_samples=5: status %d", got)
+	}
+	if got := status("/api/v1/bottles/7/journey/highlights?drift_samples=500"); got != fiber.StatusUnprocessableEntity {
+		t.Fatalf("drift_samples=500: want 422, got %d", got)
+	}
+	if len(svc.samples) != 2 || svc.samples[0] != 24 || svc.samples[1] != 5 {
+		t.Fatalf("want samples [24 5], got %v", svc.samples)
+	}
+
+	svc.missing = true
+	if got := status("/api/v1/bottles/7/journey/highlights"); got != fiber.StatusNotFound {
+		t.Fatalf("missing bottle: want 404, got %d", got)
+	}
+}
@@ -0,0 +1,57 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+func TestMetricsEndpointExposesRouteLatency(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.Metrics())
+	bottle := &domain.Bottle{ID: 1, MessageText: "hello ocean", Status: domain.BottleStatusDrifting, CreatedAt: time.Now()}
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{bottle: bottle}, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/bottles/1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	text := string(body)
+
+	want := `ocealis_http_request_duration_seconds_count{method="GET",route="/api/v1/bottles/:id",status="200"}`
+	if !strings.Contains(text, want) {
+		t.Fatalf("want route-template latency series %s in:\n%s", want, text)
+	}
+	for _, name := range []string{"ocealis_ws_connected_clients", "go_goroutines"} {
+		if !strings.Contains(text, name) {
+			t.Fatalf("want %s in /metrics", name)
+		}
+	}
+}
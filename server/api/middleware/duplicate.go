@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/cast"
+	"github.com/Polqt/ocealis/util"
+)
+
+var ErrNearDuplicate = errors.New("near-duplicate cast")
+
+// DuplicateGuard rejects Messages the Ocean has already seen too often, across all IPs.
+// It sits at the Cast abuse seam next to TurnstileVerifier.
+type DuplicateGuard interface {
+	Check(ctx context.Context, message string) error
+}
+
+// FingerprintSource lists Message fingerprints of Bottles cast since a point in time.
+type FingerprintSource interface {
+	RecentFingerprints(ctx context.Context, since time.Time) ([]uint64, error)
+}
+
+// NearDuplicateGuard counts recent Casts whose fingerprint is within MaxDistance bits
+// of the new Message and refuses once Limit of them landed inside Window.
+type NearDuplicateGuard struct {
+	Source      FingerprintSource
+	Limit       int
+	Window      time.Duration
+	MaxDistance int
+	Now         func() time.Time
+}
+
+// NewNearDuplicateGuard reads CAST_DUPLICATE_LIMIT / CAST_DUPLICATE_WINDOW_MIN from env.
+func NewNearDuplicateGuard(source FingerprintSource) *NearDuplicateGuard {
+	return &NearDuplicateGuard{
+		Source:      source,
+		Limit:       util.EnvInt("CAST_DUPLICATE_LIMIT", 3),
+		Window:      time.Duration(util.EnvInt("CAST_DUPLICATE_WINDOW_MIN", 10)) * time.Minute,
+		MaxDistance: 10,
+	}
+}
+
+func (g *NearDuplicateGuard) Check(ctx context.Context, message string) error {
+	// Same cleaning as Cast so the fingerprint matches what gets stored. A Message
+	// cleaning refuses is not a duplicate; Cast rejects it with the real reason.
+	message, err := cast.CleanMessage(message)
+	if err != nil || g.Limit <= 0 {
+		return nil
+	}
+
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	recent, err := g.Source.RecentFingerprints(ctx, now().Add(-g.Window))
+	if err != nil {
+		// ponytail: fail open — Turnstile + rate limit still stand if the store hiccups.
+		return nil
+	}
+
+	fp := cast.Fingerprint(message)
+	near := 0
+	for _, other := range recent {
+		if cast.Distance(fp, other) <= g.MaxDistance {
+			near++
+		}
+	}
+	if near >= g.Limit {
+		return ErrNearDuplicate
+	}
+	return nil
+}
+
+// AcceptDuplicates never rejects — tests / deployments without a fingerprint store.
+type AcceptDuplicates struct{}
+
+func (AcceptDuplicates) Check(context.Context, string) error { return nil }
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	idempotencyTTL       = 24 * time.Hour
+	MaxIdempotencyKeyLen = 255
+	// idempotencyRetryAfter is the Retry-After, in seconds, for a key still in flight.
+	idempotencyRetryAfter = "1"
+)
+
+// IdempotencyStore keeps Idempotency-Key claims and their replayable responses.
+type IdempotencyStore interface {
+	Claim(ctx context.Context, key, requestHash string, expiresAt time.Time) (*domain.IdempotentResponse, bool, error)
+	Complete(ctx context.Context, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the original response when a flaky client retries a mutation
+// with the same Idempotency-Key. Mount it before the rate limiter so a replay
+// does not spend the Visitor's Cast budget. Nil store → pass-through.
+func Idempotency(store IdempotencyStore) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		raw := c.Get(IdempotencyKeyHeader)
+		if store == nil || raw == "" {
+			return c.Next()
+		}
+		if len(raw) > MaxIdempotencyKeyLen {
+			return fiber.NewError(fiber.StatusBadRequest, "idempotency key too long")
+		}
+
+		// Scope keys per route so one key cannot replay a Cast as a Re-release.
+		key := c.Method() + " " + c.Path() + " " + raw
+		sum := sha256.Sum256(c.Body())
+		hash := hex.EncodeToString(sum[:])
+
+		existing, claimed, err := store.Claim(c.Context(), key, hash, time.Now().Add(idempotencyTTL))
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "could not check idempotency key")
+		}
+		if !claimed {
+			switch {
+			case existing.RequestHash != hash:
+				return fiber.NewError(fiber.StatusUnprocessableEntity, "idempotency key reused with a different request")
+			case existing.StatusCode == 0:
+				c.Set(fiber.HeaderRetryAfter, idempotencyRetryAfter)
+				return fiber.NewError(fiber.StatusConflict, "request with this idempotency key is still in flight")
+			}
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(existing.StatusCode).Send(existing.Body)
+		}
+
+		err = c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status < 200 || status >= 300 {
+			// Only successes are replayed; a failed attempt frees the key for a real retry.
+			_ = store.Release(context.WithoutCancel(c.Context()), key)
+			return err
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := store.Complete(context.WithoutCancel(c.Context()), key, status, body); err != nil {
+			_ = store.Release(context.WithoutCancel(c.Context()), key)
+		}
+		return nil
+	}
+}
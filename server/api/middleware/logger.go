@@ -3,6 +3,7 @@ package middleware
 import (
 	"time"
 
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/gofiber/fiber/v3"
 	"go.uber.org/zap"
 )
@@ -29,6 +30,8 @@ func RequestLogger(log *zap.Logger) fiber.Handler {
 			zap.Duration("latency", duration),
 			zap.String("ip", c.IP()),
 		}
+		// trace_id/span_id from Tracing, when it ran first.
+		fields = append(fields, telemetry.LogFields(c.Context())...)
 
 		switch {
 		case status >= 500:
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/metrics"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Metrics records request latency per route template (/api/v1/bottles/:id, not /bottles/42)
+// so label cardinality stays bounded.
+func Metrics() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Method(), c.Route().Path, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+		return err
+	}
+}
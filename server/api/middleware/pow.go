@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	powBaseDifficulty = 16 // leading zero bits when the Ocean is calm
+	powMaxDifficulty  = 24
+	powTTL            = 5 * time.Minute
+	// powCalmCasts is how many Casts per powVolumeWindow count as calm;
+	// every doubling above it adds one bit of work.
+	powCalmCasts    = 20
+	powVolumeWindow = 10 * time.Minute
+)
+
+var errChallengeMalformed = errors.New("malformed challenge")
+
+// Challenge is what GET /api/v1/challenge hands a Visitor before Cast.
+// The client finds a counter so sha256("<challenge>:<counter>") starts with
+// Difficulty zero bits, then sends "<challenge>:<counter>" as turnstile_token.
+type Challenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ProofOfWork is a self-hosted hashcash TurnstileVerifier — no Cloudflare, no network.
+// Challenges are HMAC-signed so the server keeps no state until a solution is spent.
+type ProofOfWork struct {
+	Secret         []byte
+	BaseDifficulty int
+	MaxDifficulty  int
+	TTL            time.Duration
+	Now            func() time.Time
+
+	mu    sync.Mutex
+	spent map[string]time.Time // challenge → expiry, replay guard; held from Verify to Settle
+	casts []time.Time          // recent accepted Casts, drives adaptive difficulty
+}
+
+// NewProofOfWork builds a verifier with default difficulty bounds.
+// Empty secret → random per-process key (single instance / local only).
+func NewProofOfWork(secret string) *ProofOfWork {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &ProofOfWork{
+		Secret:         key,
+		BaseDifficulty: powBaseDifficulty,
+		MaxDifficulty:  powMaxDifficulty,
+		TTL:            powTTL,
+	}
+}
+
+// Issue signs a fresh nonce at the difficulty the current Cast volume calls for.
+func (p *ProofOfWork) Issue() (Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return Challenge{}, fmt.Errorf("challenge nonce: %w", err)
+	}
+
+	now := p.now()
+	p.mu.Lock()
+	p.prune(now)
+	difficulty := p.difficulty(now)
+	p.mu.Unlock()
+
+	expires := now.Add(p.ttl()).Truncate(time.Second)
+	body := fmt.Sprintf("%s.%d.%d", base64.RawURLEncoding.EncodeToString(nonce), difficulty, expires.Unix())
+	return Challenge{
+		Challenge:  body + "." + p.sign(body),
+		Difficulty: difficulty,
+		ExpiresAt:  expires,
+	}, nil
+}
+
+// Verify checks the solution and holds its challenge, so a concurrent replay fails
+// while the Cast it unlocked is still being handled. Settle spends or releases it.
+func (p *ProofOfWork) Verify(_ context.Context, token, _ string) error {
+	challenge, counter, ok := splitSolution(token)
+	if !ok {
+		return ErrTurnstileFailed
+	}
+
+	difficulty, expires, err := p.open(challenge)
+	if err != nil {
+		return ErrTurnstileFailed
+	}
+	now := p.now()
+	if !now.Before(expires) {
+		return ErrTurnstileFailed
+	}
+	if leadingZeroBits(challenge, counter) < difficulty {
+		return ErrTurnstileFailed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prune(now)
+	if _, used := p.spent[challenge]; used {
+		return ErrTurnstileFailed
+	}
+	if p.spent == nil {
+		p.spent = make(map[string]time.Time)
+	}
+	p.spent[challenge] = expires
+	return nil
+}
+
+// Settle spends the challenge behind a verified token unless its Cast is retryable.
+// Only accepted Casts count toward difficulty.
+func (p *ProofOfWork) Settle(token string, outcome Settlement) {
+	challenge, _, ok := splitSolution(token)
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch outcome {
+	case CastAccepted:
+		p.casts = append(p.casts, p.now())
+	case CastRetryable:
+		delete(p.spent, challenge)
+	}
+}
+
+// splitSolution splits "<challenge>:<counter>".
+func splitSolution(token string) (challenge, counter string, ok bool) {
+	token = strings.TrimSpace(token)
+	sep := strings.LastIndexByte(token, ':')
+	if sep <= 0 {
+		return "", "", false
+	}
+	return token[:sep], token[sep+1:], true
+}
+
+// Solve brute-forces a counter for a challenge — local end-to-end tests and tooling.
+func Solve(challenge string, difficulty int) string {
+	for n := 0; ; n++ {
+		counter := strconv.Itoa(n)
+		if leadingZeroBits(challenge, counter) >= difficulty {
+			return challenge + ":" + counter
+		}
+	}
+}
+
+// open checks the HMAC and returns the signed difficulty and expiry.
+func (p *ProofOfWork) open(challenge string) (int, time.Time, error) {
+	dot := strings.LastIndexByte(challenge, '.')
+	if dot <= 0 {
+		return 0, time.Time{}, errChallengeMalformed
+	}
+	body, sig := challenge[:dot], challenge[dot+1:]
+	if !hmac.Equal([]byte(sig), []byte(p.sign(body))) {
+		return 0, time.Time{}, errChallengeMalformed
+	}
+	parts := strings.Split(body, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, errChallengeMalformed
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, time.Time{}, errChallengeMalformed
+	}
+	unix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, errChallengeMalformed
+	}
+	return difficulty, time.Unix(unix, 0), nil
+}
+
+func (p *ProofOfWork) sign(body string) string {
+	mac := hmac.New(sha256.New, p.Secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// difficulty adds a bit of work per doubling of Casts above calm. Caller holds mu.
+func (p *ProofOfWork) difficulty(now time.Time) int {
+	d := p.BaseDifficulty
+	for volume := p.recentCasts(now); volume > powCalmCasts; volume /= 2 {
+		d++
+	}
+	if p.MaxDifficulty > 0 && d > p.MaxDifficulty {
+		d = p.MaxDifficulty
+	}
+	return d
+}
+
+func (p *ProofOfWork) recentCasts(now time.Time) int {
+	cutoff := now.Add(-powVolumeWindow)
+	n := 0
+	for _, at := range p.casts {
+		if at.After(cutoff) {
+			n++
+		}
+	}
+	return n
+}
+
+// prune drops expired spent challenges and Casts outside the volume window. Caller holds mu.
+func (p *ProofOfWork) prune(now time.Time) {
+	for c, exp := range p.spent {
+		if !now.Before(exp) {
+			delete(p.spent, c)
+		}
+	}
+	cutoff := now.Add(-powVolumeWindow)
+	keep := p.casts[:0]
+	for _, at := range p.casts {
+		if at.After(cutoff) {
+			keep = append(keep, at)
+		}
+	}
+	p.casts = keep
+}
+
+func (p *ProofOfWork) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+func (p *ProofOfWork) ttl() time.Duration {
+	if p.TTL > 0 {
+		return p.TTL
+	}
+	return powTTL
+}
+
+func leadingZeroBits(challenge, counter string) int {
+	sum := sha256.Sum256([]byte(challenge + ":" + counter))
+	n := 0
+	for _, b := range sum {
+		if b == 0 {
+			n += 8
+			continue
+		}
+		n += bits.LeadingZeros8(b)
+		break
+	}
+	return n
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/limiter"
 )
@@ -17,6 +18,7 @@ func RateLimit() fiber.Handler {
 			return c.IP()
 		},
 		LimitReached: func(c fiber.Ctx) error {
+			metrics.RateLimitRejections.WithLabelValues("default").Inc()
 			return fiber.NewError(fiber.StatusTooManyRequests, "slow down, the ocean is patient")
 		},
 	})
@@ -31,6 +33,7 @@ func StrictRateLimit() fiber.Handler {
 			return c.IP()
 		},
 		LimitReached: func(c fiber.Ctx) error {
+			metrics.RateLimitRejections.WithLabelValues("strict").Inc()
 			return fiber.NewError(fiber.StatusTooManyRequests, "one bottle at a time")
 		},
 	})
@@ -50,6 +53,7 @@ func UserRateLimit(max int, expiration time.Duration) fiber.Handler {
 			return c.IP()
 		},
 		LimitReached: func(c fiber.Ctx) error {
+			metrics.RateLimitRejections.WithLabelValues("user").Inc()
 			return fiber.NewError(fiber.StatusTooManyRequests, "you are moving too fast")
 		},
 	})
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing opens a server span per request, continuing any W3C traceparent the client sent.
+// Handlers reach it through c.Context(), so service/repository spans nest underneath.
+func Tracing() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.Context(), headerCarrier{c})
+		ctx, span := telemetry.Tracer().Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("client.address", c.IP()),
+			),
+		)
+		defer span.End()
+		c.SetContext(ctx)
+
+		err := c.Next()
+
+		// Route template is only known once routing ran.
+		route := c.Route().Path
+		status := responseStatus(c, err)
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fiber.ErrInternalServerError.Message)
+		}
+		return err
+	}
+}
+
+// headerCarrier adapts fasthttp request headers to the OTel propagator.
+type headerCarrier struct {
+	c fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string { return h.c.Get(key) }
+
+func (h headerCarrier) Set(key, value string) { h.c.Request().Header.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, 8)
+	for k := range h.c.Request().Header.All() {
+		keys = append(keys, string(k))
+	}
+	return keys
+}
+
+// responseStatus is the status the client will see — the error handler
+// has not written it yet when err is a *fiber.Error.
+func responseStatus(c fiber.Ctx, err error) int {
+	if e, ok := err.(*fiber.Error); ok {
+		return e.Code
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError
+	}
+	return c.Response().StatusCode()
+}
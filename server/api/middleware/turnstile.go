@@ -17,6 +17,27 @@ type TurnstileVerifier interface {
 	Verify(ctx context.Context, token, ip string) error
 }
 
+// SingleUseVerifier is a TurnstileVerifier whose tokens unlock one Cast. Verify holds
+// the token until Settle says how the Cast ended.
+type SingleUseVerifier interface {
+	TurnstileVerifier
+	Settle(token string, outcome Settlement)
+}
+
+// Settlement is how a Cast unlocked by a single-use token ended.
+type Settlement int
+
+const (
+	// CastRefused spends the token: the Cast was turned away as abuse, and the same
+	// solution must not buy another try.
+	CastRefused Settlement = iota
+	// CastAccepted spends the token; the Cast landed.
+	CastAccepted
+	// CastRetryable hands the token back after a validation error or a server failure,
+	// so the Visitor can fix the request without solving again.
+	CastRetryable
+)
+
 // Turnstile is the production Cloudflare siteverify client.
 type Turnstile struct {
 	Secret string
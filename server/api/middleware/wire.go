@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/gofiber/fiber/v3"
+)
+
+// WireVersionHeader pins a client to a JSON vocabulary; ?wire_version= works too.
+const WireVersionHeader = "Ocealis-Wire-Version"
+
+// WireVersion serves wire_version 1 (pre-rename spellings) to clients that ask for it
+// and everything else the current vocabulary. Request bodies accept both spellings
+// regardless, via domain.ParseBottleStatus / ParseEventType.
+func WireVersion() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		requested := c.Get(WireVersionHeader)
+		if requested == "" {
+			requested = c.Query("wire_version")
+		}
+		if v, err := strconv.Atoi(requested); err != nil || v != domain.WireV1 {
+			c.Set(WireVersionHeader, strconv.Itoa(domain.WireVersion))
+			return c.Next()
+		}
+
+		c.Set(WireVersionHeader, strconv.Itoa(domain.WireV1))
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+			return nil
+		}
+		body, err := downgradeJSON(c.Response().Body())
+		if err != nil {
+			return nil // leave an unparseable body as the handler wrote it
+		}
+		c.Response().SetBodyRaw(body)
+		return nil
+	}
+}
+
+// downgradeJSON rewrites every object stamped wire_version 2 into its version 1 shape.
+func downgradeJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	downgrade(doc)
+	return json.Marshal(doc)
+}
+
+func downgrade(v any) {
+	switch node := v.(type) {
+	case []any:
+		for _, item := range node {
+			downgrade(item)
+		}
+	case map[string]any:
+		for _, child := range node {
+			downgrade(child)
+		}
+		if version, ok := node["wire_version"].(json.Number); !ok || version.String() != strconv.Itoa(domain.WireV2) {
+			return
+		}
+		node["wire_version"] = domain.WireV1
+		if s, ok := node["status"].(string); ok {
+			node["status"] = domain.BottleStatus(s).Legacy()
+		}
+		if s, ok := node["event_type"].(string); ok {
+			node["event_type"] = domain.EventType(s).Legacy()
+		}
+		if at, ok := node["visible_at"]; ok {
+			node["scheduled_release"] = at
+		}
+	}
+}
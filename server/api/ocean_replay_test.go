@@ -0,0 +1,218 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type replayOcean struct {
+	points []replay.Point
+	bbox   replay.BBox
+	stats  *domain.OceanStats
+	// notable is served by Notable, which records the limit it was asked for.
+	notable *domain.NotableRankings
+	limit   int32
+}
+
+func (f *replayOcean) Stats(context.Context) (*domain.OceanStats, error) {
+	return f.stats, nil
+}
+
+func (f *replayOcean) PublishStats(context.Context) error { return nil }
+
+func (f *replayOcean) Notable(_ context.Context, limit int32) (*domain.NotableRankings, error) {
+	f.limit = limit
+	return f.notable, nil
+}
+
+func (f *replayOcean) Replay(_ context.Context, w replay.Window, bbox replay.BBox) (*replay.Timeline, error) {
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	f.bbox = bbox
+	return replay.NewTimeline(w, bbox, f.points), nil
+}
+
+func TestOceanReplayStreamsNDJSONFrames(t *testing.T) {
+	t0 := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	ocean := &replayOcean{points: []replay.Point{
+		{BottleID: 4, BottleStyle: 3, EventType: domain.EventTypeCast, At: t0, Lat: 10, Lng: 20},
+		{BottleID: 4, BottleStyle: 3, EventType: domain.EventTypeDrift, At: t0.Add(time.Hour), Lat: 11, Lng: 21},
+	}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{}, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+		Ocean:     handler.NewOceanHandler(ocean),
+	}, ws.NewHub(), zap.NewNop())
+
+	get := func(query string) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ocean/replay?"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get("from=2026-02-01T00:00:00Z&to=2026-02-01T01:00:00Z&step=30m&bbox=0,0,40,40")
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Content-Type") != handler.MIMEApplicationNDJSON {
+		t.Fatalf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if ocean.bbox != (replay.BBox{MinLng: 0, MinLat: 0, MaxLng: 40, MaxLat: 40}) {
+		t.Fatalf("bbox parsed as %+v", ocean.bbox)
+	}
+
+	var frames []replay.Frame
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var f replay.Frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("want 3 frames, got %d", len(frames))
+	}
+	mid := frames[1]
+	if mid.Index != 1 || !mid.At.Equal(t0.Add(30*time.Minute)) || len(mid.Corks) != 1 || mid.Corks[0].Lat != 10.5 || mid.Corks[0].BottleStyle != 3 {
+		t.Fatalf("middle frame %+v", mid)
+	}
+
+	for query, want := range map[string]int{
+		"to=2026-02-01T01:00:00Z&bbox=0,0,40,40":                                      fiber.StatusBadRequest,
+		"from=2026-02-01T00:00:00Z&to=2026-02-01T01:00:00Z&bbox=40,0,0,40":            fiber.StatusBadRequest,
+		"from=2026-02-01T00:00:00Z&to=2026-03-01T00:00:00Z&step=1m&bbox=0,0,40,40":    fiber.StatusUnprocessableEntity,
+		"from=2026-02-01T00:00:00Z&to=2026-02-01T01:00:00Z&step=often&bbox=0,0,40,40": fiber.StatusBadRequest,
+	} {
+		resp := get(query)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: want %d, got %d", query, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestOceanStatsServesCounts(t *testing.T) {
+	ocean := &replayOcean{stats: &domain.OceanStats{
+		Drifting: 12, MysteryDelay: 3, Sunk: 1, Casts24h: 5, Stamps24h: 2, KmDrifted: 1234.5,
+		Basins: map[string]int64{"north_pacific": 9, "indian_ocean": 3},
+	}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(nil, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+		Ocean:     handler.NewOceanHandler(ocean),
+	}, ws.NewHub(), zap.NewNop())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ocean/stats", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]float64{
+		"drifting": 12, "mystery_delay": 3, "sunk": 1, "casts_24h": 5, "stamps_24h": 2, "km_drifted": 1234.5,
+	} {
+		if got[key] != want {
+			t.Fatalf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	if basins, _ := got["basins"].(map[string]any); basins["north_pacific"] != float64(9) {
+		t.Fatalf("basins = %v", got["basins"])
+	}
+}
+
+func TestOceanNotableServesRankings(t *testing.T) {
+	castAt := time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)
+	voyager := domain.NotableBottle{
+		BottleID: 7, BottleStyle: 2, Lat: -12, Lng: 80, CastAt: castAt,
+		DistanceKm: 8421.3, ReReleases: 2, Stamps: 4, Basins: []string{"indian_ocean", "south_atlantic"},
+	}
+	ocean := &replayOcean{notable: &domain.NotableRankings{
+		LongestVoyages: []domain.NotableBottle{voyager},
+		MostReReleased: []domain.NotableBottle{voyager},
+		MostStamped:    []domain.NotableBottle{voyager},
+		MostBasins:     []domain.NotableBottle{voyager},
+		Oldest:         []domain.NotableBottle{},
+	}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(nil, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+		Ocean:     handler.NewOceanHandler(ocean),
+	}, ws.NewHub(), zap.NewNop())
+
+	get := func(query string) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ocean/notable"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get("")
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if ocean.limit != 10 {
+		t.Fatalf("default limit %d, want 10", ocean.limit)
+	}
+	var got map[string][]map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	for _, ranking := range []string{"longest_voyages", "most_re_released", "most_stamped", "most_basins", "oldest"} {
+		rows, ok := got[ranking]
+		if !ok || rows == nil {
+			t.Fatalf("%s missing or null: %v", ranking, got)
+		}
+	}
+	top := got["longest_voyages"][0]
+	if top["bottle_id"] != float64(7) || top["distance_km"] != 8421.3 || top["cast_at"] != "2025-11-02T00:00:00Z" {
+		t.Fatalf("longest voyage %v", top)
+	}
+	for _, private := range []string{"nickname", "message_text", "sender_id"} {
+		if _, ok := top[private]; ok {
+			t.Fatalf("ranking leaks %s: %v", private, top)
+		}
+	}
+
+	if resp := get("?limit=25"); resp.StatusCode != fiber.StatusOK || ocean.limit != 25 {
+		t.Fatalf("limit=25: status %d, limit %d", resp.StatusCode, ocean.limit)
+	}
+	for query, want := range map[string]int{"?limit=51": fiber.StatusUnprocessableEntity, "?limit=0x": fiber.StatusBadRequest} {
+		if resp := get(query); resp.StatusCode != want {
+			t.Fatalf("%s: status %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/openapi"
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+const contractMissingID = 404
+
+var contractT0 = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+func contractBottle() domain.Bottle {
+	return domain.Bottle{
+		ID: 7, Nickname: "Petrel", MessageText: "hello ocean", BottleStyle: 1,
+		StartLat: 10, StartLng: 179, CurrentLat: 12, CurrentLng: -179, Hops: 1,
+		VisibleAt: contractT0, IsReleased: true, Status: domain.BottleStatusDrifting, CreatedAt: contractT0,
+	}
+}
+
+// contractJourney carries one event of every type, so every payload shape is rendered.
+func contractJourney() *domain.Journey {
+	b := contractBottle()
+	return &domain.Journey{Bottle: &b, Events: []domain.BottleEvent{
+		{ID: 1, BottleID: 7, EventType: domain.EventTypeCast, Lat: 10, Lng: 179, CreatedAt: contractT0, Payload: domain.CastPayload{Nickname: "Petrel", BottleStyle: 1}},
+		{ID: 2, BottleID: 7, EventType: domain.EventTypeDrift, Lat: 11, Lng: 180, CreatedAt: contractT0.Add(time.Hour), Payload: domain.DriftPayload{BearingDeg: 90, SpeedKmH: 2, DistanceKm: 30, Ticks: 4}},
+		{ID: 3, BottleID: 7, EventType: domain.EventTypeStamp, Lat: 11, Lng: 180, CreatedAt: contractT0.Add(2 * time.Hour), Payload: domain.StampPayload{Nickname: "Gull", Seal: "anchor"}},
+		{ID: 4, BottleID: 7, EventType: domain.EventTypeReReleased, Lat: 12, Lng: -179, CreatedAt: contractT0.Add(3 * time.Hour), Payload: domain.ReReleasePayload{}},
+		{ID: 5, BottleID: 7, EventType: domain.EventTypeSink, Lat: 12, Lng: -179, CreatedAt: contractT0.Add(4 * time.Hour), Payload: domain.SinkPayload{Reason: domain.SinkReasonBeached}},
+		{ID: 6, BottleID: 7, EventType: domain.EventTypeDrift, Lat: 12, Lng: -179, CreatedAt: contractT0.Add(5 * time.Hour)},
+	}}
+}
+
+// contractBottles answers every BottleService call with a realistic Bottle, except
+// for contractMissingID.
+type contractBottles struct{}
+
+func (contractBottles) CreateBottle(context.Context, service.CreateBottleInput) (*domain.Bottle, error) {
+	b := contractBottle()
+	b.Status, b.IsReleased = domain.BottleStatusMysteryDelay, false
+	return &b, nil
+}
+func (contractBottles) GetBottle(_ context.Context, id int32) (*domain.Bottle, error) {
+	if id == contractMissingID {
+		return nil, service.ErrBottleNotFound
+	}
+	b := contractBottle()
+	return &b, nil
+}
+func (contractBottles) BottleVersion(context.Context, int32) (int32, error) { return 41, nil }
+func (contractBottles) GetJourney(_ context.Context, id int32) (*domain.Journey, error) {
+	if id == contractMissingID {
+		return nil, service.ErrBottleNotFound
+	}
+	return contractJourney(), nil
+}
+func (contractBottles) GetJourneyHighlights(context.Context, int32, int) (*domain.Journey, error) {
+	return contractJourney(), nil
+}
+func (contractBottles) DiscoverBottle(context.Context, service.DiscoverBottleInput) (*domain.Journey, error) {
+	return contractJourney(), nil
+}
+func (contractBottles) ReleaseBottle(context.Context, service.ReleaseBottleInput) (*domain.Bottle, error) {
+	b := contractBottle()
+	b.Status = domain.BottleStatusMysteryDelay
+	return &b, nil
+}
+func (contractBottles) TranslateBottle(_ context.Context, _ int32, target string) (*domain.TranslatedBottle, error) {
+	return &domain.TranslatedBottle{Bottle: contractBottle(), Translation: domain.Translation{Lang: target, SourceLang: "en", MessageText: "bonjour l'océan"}}, nil
+}
+
+// contractDiscovery fills the results stubDiscovery leaves empty.
+type contractDiscovery struct {
+	stubDiscovery
+}
+
+func (contractDiscovery) FindNearby(context.Context, service.FindNearbyInput) (*domain.CursorResult[service.BottleWithDistance], error) {
+	last := int32(7)
+	return &domain.CursorResult[service.BottleWithDistance]{
+		Data:       []service.BottleWithDistance{{Bottle: contractBottle(), DistanceKm: 12.5}},
+		NextCursor: &domain.Cursor{LastID: &last},
+		HasMore:    true,
+	}, nil
+}
+
+func (contractDiscovery) BrowseMap(_ context.Context, in service.BrowseMapInput) (discovery.MapResult, error) {
+	if in.Zoom >= discovery.CorkZoomMin {
+		return discovery.MapResult{Mode: "corks", Corks: []discovery.Cork{{ID: 7, Lat: 1, Lng: 2}, {ID: -1, Lat: 3, Lng: 4, IsSeed: true}}}, nil
+	}
+	return discovery.MapResult{Mode: "heat", Heat: []discovery.HeatCell{{Lat: 1, Lng: 2, Count: 3}}}, nil
+}
+
+// contractEvents serves one page of events; the rest of EventRepository is unused.
+type contractEvents struct {
+	repository.EventRepository
+}
+
+func (contractEvents) GetPaginated(context.Context, repository.GetEventParams) (*domain.CursorResult[domain.BottleEvent], error) {
+	return &domain.CursorResult[domain.BottleEvent]{Data: contractJourney().Events}, nil
+}
+
+// contractApp wires every handler the way main does, error envelope and strict
+// routing included. Each request gets a fresh app so rate limits never interfere.
+func contractApp(hub *ws.Hub) *fiber.App {
+	app := fiber.New(fiber.Config{CaseSensitive: true, StrictRouting: true, ErrorHandler: handler.ErrorHandler})
+	b := contractBottle()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(contractBottles{}, nil, nil),
+		Event:     handler.NewEventHandler(contractEvents{}),
+		Discovery: handler.NewDiscoveryHandler(&contractDiscovery{stubDiscovery{bottle: b, generation: 900, hits: &domain.CursorResult[domain.SearchHit]{Data: []domain.SearchHit{{Bottle: b, Rank: 0.5}}}}}),
+		Ocean: handler.NewOceanHandler(&replayOcean{
+			stats:   &domain.OceanStats{Drifting: 3, Basins: map[string]int64{"north_pacific": 3}},
+			notable: &domain.NotableRankings{LongestVoyages: []domain.NotableBottle{{BottleID: 7, CastAt: contractT0, Basins: []string{"north_pacific"}}}},
+			points:  []replay.Point{{BottleID: 7, At: contractT0, Lat: 10, Lng: 20}, {BottleID: 7, At: contractT0.Add(time.Hour), Lat: 11, Lng: 21}},
+		}),
+		Share:       handler.NewShareHandler(contractBottles{}, nil, "https://ocealis.test"),
+		Challenge:   handler.NewChallengeHandler(middleware.NewProofOfWork("contract-secret")),
+		Idempotency: &memoryIdempotency{},
+	}, hub, zap.NewNop())
+	return app
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	doc := handler.OpenAPI()
+	registered := map[string]bool{}
+	for _, r := range contractApp(ws.NewHub()).GetRoutes(true) {
+		key := r.Method + " " + openapi.PathFromRoute(r.Path)
+		registered[key] = true
+		if doc.Operation(r.Method, openapi.PathFromRoute(r.Path)) == nil {
+			t.Errorf("%s is routed but not in the OpenAPI document", key)
+		}
+	}
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if key := strings.ToUpper(method) + " " + path; !registered[key] {
+				t.Errorf("%s is documented but not routed", key)
+			}
+		}
+	}
+}
+
+// contractRequest is one call against a documented operation, and the status it must get.
+type contractRequest struct {
+	method, path string // the operation, as documented
+	target       string
+	header       map[string]string
+	body         map[string]any
+	status       int
+	// probe derives boundary requests from the operation's documented parameters.
+	probe bool
+	// conflicts are at-bound values rejected for another documented reason.
+	conflicts []string
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	doc := handler.OpenAPI()
+	json200 := map[string]string{fiber.HeaderContentType: fiber.MIMEApplicationJSON}
+	cast := map[string]any{"nickname": "Petrel", "message_text": "hello ocean", "bottle_style": 1, "start_lat": 10.5, "start_lng": -20.5, "turnstile_token": "ok"}
+	position := map[string]any{"lat": 10.5, "lng": -20.5, "nickname": "Gull"}
+	const replayWindow = "/api/v1/ocean/replay?from=2026-02-01T00:00:00Z&to=2026-02-01T01:00:00Z&bbox=0,0,40,40"
+	cases := []contractRequest{
+		{method: "GET", path: "/", target: "/", status: 200},
+		{method: "GET", path: "/api/openapi.json", target: "/api/openapi.json", status: 200},
+		{method: "GET", path: "/metrics", target: "/metrics", status: 200},
+		{method: "GET", path: "/ws", target: "/ws", status: 426},
+		{method: "GET", path: "/b/{id}", target: "/b/7", status: 200},
+		{method: "GET", path: "/b/{id}", target: "/b/x", status: 400},
+		{method: "GET", path: "/b/{id}", target: "/b/404", status: 404},
+		{method: "GET", path: "/api/v1/challenge", target: "/api/v1/challenge", status: 200},
+
+		{method: "POST", path: "/api/v1/bottles/", target: "/api/v1/bottles/", header: map[string]string{fiber.HeaderContentType: fiber.MIMEApplicationJSON, middleware.IdempotencyKeyHeader: "k"}, body: cast, status: 201, probe: true},
+		{method: "POST", path: "/api/v1/bottles/", target: "/api/v1/bottles/", header: json200, status: 400},
+		{method: "GET", path: "/api/v1/bottles/{id}", target: "/api/v1/bottles/7", status: 200, probe: true},
+		{method: "GET", path: "/api/v1/bottles/{id}", target: "/api/v1/bottles/7?lang=fr", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}", target: "/api/v1/bottles/7", header: map[string]string{fiber.HeaderIfNoneMatch: `W/"b41.w2"`}, status: 304},
+		{method: "GET", path: "/api/v1/bottles/{id}", target: "/api/v1/bottles/404", status: 404},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey", target: "/api/v1/bottles/7/journey", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey", target: "/api/v1/bottles/7/journey", header: map[string]string{fiber.HeaderIfNoneMatch: `W/"j41.w2"`}, status: 304},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey.{format}", target: "/api/v1/bottles/7/journey.geojson", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey.{format}", target: "/api/v1/bottles/7/journey.gpx", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey.{format}", target: "/api/v1/bottles/7/journey.kml", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey.{format}", target: "/api/v1/bottles/7/journey.shp", status: 404},
+		{method: "GET", path: "/api/v1/bottles/{id}/card.png", target: "/api/v1/bottles/7/card.png", status: 200},
+		{method: "GET", path: "/api/v1/bottles/{id}/journey/highlights", target: "/api/v1/bottles/7/journey/highlights?drift_samples=12", status: 200, probe: true},
+		{method: "GET", path: "/api/v1/bottles/{id}/events", target: "/api/v1/bottles/7/events?cursor=3&limit=20", status: 200, probe: true},
+		{method: "POST", path: "/api/v1/bottles/{id}/discover", target: "/api/v1/bottles/7/discover", header: json200, body: map[string]any{"user_lat": 10.5, "user_lng": -20.5}, status: 200, probe: true},
+		{method: "POST", path: "/api/v1/bottles/{id}/release", target: "/api/v1/bottles/7/release", header: map[string]string{fiber.HeaderContentType: fiber.MIMEApplicationJSON, middleware.IdempotencyKeyHeader: "k"}, body: position, status: 200, probe: true},
+		{method: "GET", path: "/api/v1/styles", target: "/api/v1/styles", status: 200},
+
+		{method: "GET", path: "/api/v1/discovery/", target: "/api/v1/discovery/?lat=10&lng=20&radius_km=50&limit=10", status: 200, probe: true},
+		{method: "GET", path: "/api/v1/discovery/map", target: "/api/v1/discovery/map?min_lat=-10&max_lat=10&min_lng=-10&max_lng=10&zoom=3", status: 200, probe: true,
+			conflicts: []string{"min_lat=90", "max_lat=-90", "min_lng=180", "max_lng=-180"}}, // inverted viewports
+		{method: "GET", path: "/api/v1/discovery/map", target: "/api/v1/discovery/map?min_lat=-10&max_lat=10&min_lng=-10&max_lng=10&zoom=12", status: 200},
+		{method: "GET", path: "/api/v1/discovery/map", target: "/api/v1/discovery/map?min_lat=10&max_lat=-10&min_lng=-10&max_lng=10&zoom=3", status: 400},
+		{method: "GET", path: "/api/v1/discovery/search", target: "/api/v1/discovery/search?q=ocean&limit=10&bbox=-10,-10,10,10", status: 200, probe: true},
+		{method: "GET", path: "/api/v1/discovery/search", target: "/api/v1/discovery/search?q=ocean&cursor=3", status: 422},
+		{method: "GET", path: "/api/v1/discovery/random", target: "/api/v1/discovery/random?lat=10&lng=20", status: 200, probe: true},
+
+		{method: "GET", path: "/api/v1/ocean/stats", target: "/api/v1/ocean/stats", status: 200},
+		{method: "GET", path: "/api/v1/ocean/replay", target: replayWindow + "&step=15m", status: 200, probe: true},
+		{method: "GET", path: "/api/v1/ocean/replay", target: replayWindow + "&step=soon", status: 400},
+		{method: "GET", path: "/api/v1/ocean/replay", target: replayWindow + "&step=1s", status: 422},
+		{method: "GET", path: "/api/v1/ocean/notable", target: "/api/v1/ocean/notable?limit=5", status: 200, probe: true},
+	}
+	// The health check pings the database, which these tests do not have.
+	untested := map[string]bool{"GET /api/health": true}
+
+	exercised := map[string]bool{}
+	for _, tc := range cases {
+		op := doc.Operation(tc.method, tc.path)
+		if op == nil {
+			t.Fatalf("%s %s is not documented", tc.method, tc.path)
+		}
+		exercised[tc.method+" "+tc.path] = true
+		checkContract(t, doc, op, tc, tc.status)
+		if tc.probe {
+			for _, p := range contractProbes(op, tc) {
+				checkContract(t, doc, op, p.req, p.want)
+			}
+		}
+	}
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if key := strings.ToUpper(method) + " " + path; !exercised[key] && !untested[key] {
+				t.Errorf("%s has no contract case", key)
+			}
+		}
+	}
+}
+
+// checkContract sends tc and holds the response to op: the wanted status (0 means any
+// 400 or 422), which must be documented, with its documented headers and content.
+func checkContract(t *testing.T, doc *openapi.Document, op *openapi.Operation, tc contractRequest, want int) {
+	t.Helper()
+	var body io.Reader
+	if tc.body != nil {
+		raw, _ := json.Marshal(tc.body)
+		body = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(tc.method, tc.target, body)
+	for k, v := range tc.header {
+		req.Header.Set(k, v)
+	}
+	resp, err := contractApp(ws.NewHub()).Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	name := fmt.Sprintf("%s %s", tc.method, tc.target)
+	if tc.body != nil {
+		name += fmt.Sprintf(" %v", tc.body)
+	}
+
+	if want == 0 && resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("%s: status %d, want it rejected as out of bounds: %s", name, resp.StatusCode, raw)
+		return
+	}
+	if want != 0 && resp.StatusCode != want {
+		t.Errorf("%s: status %d, want %d: %s", name, resp.StatusCode, want, raw)
+		return
+	}
+	documented := op.Responses[strconv.Itoa(resp.StatusCode)]
+	if documented == nil {
+		t.Errorf("%s: status %d is not documented for %s", name, resp.StatusCode, op.OperationID)
+		return
+	}
+	for header, h := range documented.Headers {
+		if h.Required && resp.Header.Get(header) == "" {
+			t.Errorf("%s: documented header %s missing", name, header)
+		}
+	}
+	if len(documented.Content) == 0 {
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get(fiber.HeaderContentType))
+	var media *openapi.MediaType
+	for documentedType, m := range documented.Content {
+		if base, _, _ := mime.ParseMediaType(documentedType); base == contentType {
+			media = m
+		}
+	}
+	if media == nil {
+		t.Errorf("%s: content type %q is not documented for %d", name, contentType, resp.StatusCode)
+		return
+	}
+	var docs [][]byte
+	switch contentType {
+	case fiber.MIMEApplicationJSON:
+		docs = [][]byte{raw}
+	case handler.MIMEApplicationNDJSON:
+		sc := bufio.NewScanner(bytes.NewReader(raw))
+		for sc.Scan() {
+			docs = append(docs, slices.Clone(sc.Bytes()))
+		}
+	}
+	for _, d := range docs {
+		var v any
+		if err := json.Unmarshal(d, &v); err != nil {
+			t.Errorf("%s: body is not JSON: %v", name, err)
+			continue
+		}
+		if err := doc.Validate(media.Schema, v); err != nil {
+			t.Errorf("%s: body does not match the documented schema: %v\n%s", name, err, d)
+		}
+	}
+}
+
+type contractProbe struct {
+	req  contractRequest
+	want int // 0: any 400 or 422
+}
+
+// contractProbes derives requests at and just past every documented bound of op's
+// query and header parameters and JSON body properties, and without each required
+// one. Past a bound must be rejected; at it must still get tc's status.
+func contractProbes(op *openapi.Operation, tc contractRequest) []contractProbe {
+	var probes []contractProbe
+	bounds := func(name string, s *openapi.Schema, required bool, with func(v any, omit bool) contractRequest) {
+		if required {
+			probes = append(probes, contractProbe{with(nil, true), 0})
+		}
+		atBound := func(v any) {
+			if !slices.Contains(tc.conflicts, fmt.Sprintf("%s=%v", name, v)) {
+				probes = append(probes, contractProbe{with(v, false), tc.status})
+			}
+		}
+		if s.Maximum != nil {
+			probes = append(probes, contractProbe{with(*s.Maximum+1, false), 0})
+			atBound(*s.Maximum)
+		}
+		if s.Minimum != nil {
+			below := *s.Minimum - 1
+			if below == 0 {
+				below = -1 // zero reads as unset for omitempty fields
+			}
+			probes = append(probes, contractProbe{with(below, false), 0})
+			atBound(*s.Minimum)
+		}
+		for _, limit := range []*int{s.MaxLength, s.MaxGraphemes} {
+			if limit == nil {
+				continue
+			}
+			probes = append(probes, contractProbe{with(strings.Repeat("a", *limit+1), false), 0})
+			if s.Format == "" {
+				atBound(strings.Repeat("a", *limit))
+			}
+		}
+	}
+
+	for _, p := range op.Parameters {
+		if p.Name == "wire_version" || p.Name == middleware.WireVersionHeader {
+			continue // enum, and unknown values fall back to the current version
+		}
+		switch p.In {
+		case "query":
+			bounds(p.Name, p.Schema, p.Required, func(v any, omit bool) contractRequest {
+				u, _ := url.Parse(tc.target)
+				q := u.Query()
+				if omit {
+					q.Del(p.Name)
+				} else {
+					q.Set(p.Name, fmt.Sprint(v))
+				}
+				u.RawQuery = q.Encode()
+				out := tc
+				out.target = u.String()
+				return out
+			})
+		case "header":
+			if _, sent := tc.header[p.Name]; !sent {
+				continue
+			}
+			bounds(p.Name, p.Schema, p.Required, func(v any, _ bool) contractRequest {
+				out := tc
+				out.header = map[string]string{}
+				for k, h := range tc.header {
+					out.header[k] = h
+				}
+				out.header[p.Name] = fmt.Sprint(v)
+				return out
+			})
+		}
+	}
+	if op.RequestBody != nil {
+		schema := op.RequestBody.Content[fiber.MIMEApplicationJSON].Schema
+		for name, prop := range schema.Properties {
+			bounds(name, prop, slices.Contains(schema.Required, name), func(v any, omit bool) contractRequest {
+				out := tc
+				out.body = map[string]any{}
+				for k, b := range tc.body {
+					out.body[k] = b
+				}
+				if omit {
+					delete(out.body, name)
+				} else {
+					out.body[name] = v
+				}
+				return out
+			})
+		}
+	}
+	return probes
+}
+
+func TestOpenAPIDescribesStreamMessages(t *testing.T) {
+	doc := handler.OpenAPI()
+	hub := ws.NewHub()
+	app := contractApp(hub)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) }()
+	defer func() { _ = app.Shutdown() }()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for deadline := time.Now().Add(2 * time.Second); hub.ClientCount() == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("client never registered")
+		}
+	}
+
+	b := ws.NewBroadcaster(hub, zap.NewNop())
+	b.BroadcastDrift(ws.DriftPayload{BottleID: 7, Lat: 10, Lng: 20, Hops: 1, BottleStyle: 1, Timestamp: contractT0})
+	b.BroadcastDiscovered(7)
+	b.BroadcastReleased(7)
+	b.BroadcastOceanStats(&domain.OceanStats{Drifting: 3, Basins: map[string]int64{"north_pacific": 3}})
+
+	messages := doc.Operation(http.MethodGet, "/ws").Messages
+	seen := map[ws.MessageType]bool{}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(seen) < len(ws.Payloads) {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("after %v: %v", seen, err)
+		}
+		var v map[string]any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Validate(messages.Server, v); err != nil {
+			t.Errorf("stream message does not match the documented schema: %v\n%s", err, raw)
+		}
+		seen[ws.MessageType(fmt.Sprint(v["type"]))] = true
+	}
+
+	var sub map[string]any
+	raw, _ := json.Marshal(ws.SubMessage{Action: "subscribe", Topic: "bottle:7"})
+	_ = json.Unmarshal(raw, &sub)
+	if err := doc.Validate(messages.Client, sub); err != nil {
+		t.Errorf("subscription does not match the documented schema: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/cast"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+func powApp(t *testing.T, pow *middleware.ProofOfWork, svc *castRecordingSvc) *fiber.App {
+	t.Helper()
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, pow, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+		Challenge: handler.NewChallengeHandler(pow),
+	}, ws.NewHub(), zap.NewNop())
+	return app
+}
+
+func castWithToken(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"nickname":        "sailor",
+		"message_text":    "hello ocean",
+		"turnstile_token": token,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/bottles", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestProofOfWorkChallengeUnlocksCastOnce(t *testing.T) {
+	pow := middleware.NewProofOfWork("test-secret")
+	pow.BaseDifficulty = 8 // keep the test fast
+	svc := &castRecordingSvc{}
+	app := powApp(t, pow, svc)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/challenge", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		t.Fatalf("want 200, got %d body=%s", resp.StatusCode, b)
+	}
+	var ch middleware.Challenge
+	if err := json.NewDecoder(resp.Body).Decode(&ch); err != nil {
+		t.Fatal(err)
+	}
+	if ch.Difficulty != 8 {
+		t.Fatalf("calm Ocean want base difficulty 8, got %d", ch.Difficulty)
+	}
+
+	token := middleware.Solve(ch.Challenge, ch.Difficulty)
+	if code := castWithToken(t, app, token); code != http.StatusCreated {
+		t.Fatalf("solved challenge want 201, got %d", code)
+	}
+	if code := castWithToken(t, app, token); code != http.StatusForbidden {
+		t.Fatalf("replayed solution want 403, got %d", code)
+	}
+}
+
+func TestProofOfWorkRejectedCastKeepsChallenge(t *testing.T) {
+	pow := middleware.NewProofOfWork("test-secret")
+	pow.BaseDifficulty = 1
+	svc := &castRecordingSvc{err: cast.ErrNicknameReserved}
+	app := powApp(t, pow, svc)
+
+	ch, err := pow.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := middleware.Solve(ch.Challenge, ch.Difficulty)
+	if code := castWithToken(t, app, token); code != http.StatusUnprocessableEntity {
+		t.Fatalf("rejected cast want 422, got %d", code)
+	}
+	for i := 0; i < 100; i++ { // past the per-IP Cast limit, straight at the verifier
+		if err := pow.Verify(t.Context(), token, ""); err != nil {
+			t.Fatalf("released challenge %d: %v", i, err)
+		}
+		pow.Settle(token, middleware.CastRetryable)
+	}
+	if after, err := pow.Issue(); err != nil || after.Difficulty != ch.Difficulty {
+		t.Fatalf("rejected Casts must not raise difficulty: %d → %+v (%v)", ch.Difficulty, after, err)
+	}
+
+	svc.err = nil
+	if code := castWithToken(t, app, token); code != http.StatusCreated {
+		t.Fatalf("fixed cast with the same solution want 201, got %d", code)
+	}
+	if code := castWithToken(t, app, token); code != http.StatusForbidden {
+		t.Fatalf("spent solution want 403, got %d", code)
+	}
+}
+
+func TestProofOfWorkDuplicateRefusalSpendsChallenge(t *testing.T) {
+	pow := middleware.NewProofOfWork("test-secret")
+	pow.BaseDifficulty = 1
+	seen := cast.Fingerprint("hello ocean")
+	guard := &middleware.NearDuplicateGuard{
+		Source:      fingerprintStub{fps: []uint64{seen, seen, seen}},
+		Limit:       3,
+		Window:      10 * time.Minute,
+		MaxDistance: 10,
+	}
+	app := castAppWithGuard(t, pow, guard, &castRecordingSvc{})
+
+	ch, err := pow.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := middleware.Solve(ch.Challenge, ch.Difficulty)
+	if code := castWithToken(t, app, token); code != http.StatusTooManyRequests {
+		t.Fatalf("duplicate cast want 429, got %d", code)
+	}
+	if code := castWithToken(t, app, token); code != http.StatusForbidden {
+		t.Fatalf("solution refused as a duplicate must be spent, got %d", code)
+	}
+}
+
+func TestProofOfWorkRejectsForgedDifficulty(t *testing.T) {
+	pow := middleware.NewProofOfWork("test-secret")
+	pow.BaseDifficulty = 8
+	app := powApp(t, pow, &castRecordingSvc{})
+
+	ch, err := pow.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lower the signed difficulty without re-signing — HMAC must catch it.
+	forged := bytes.Replace([]byte(ch.Challenge), []byte(".8."), []byte(".1."), 1)
+	if code := castWithToken(t, app, middleware.Solve(string(forged), 1)); code != http.StatusForbidden {
+		t.Fatalf("forged challenge want 403, got %d", code)
+	}
+}
+
+func TestProofOfWorkDifficultyRisesWithCastVolume(t *testing.T) {
+	pow := middleware.NewProofOfWork("test-secret")
+	pow.BaseDifficulty = 1
+
+	calm, err := pow.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		ch, err := pow.Issue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := middleware.Solve(ch.Challenge, ch.Difficulty)
+		if err := pow.Verify(t.Context(), token, ""); err != nil {
+			t.Fatalf("cast %d: %v", i, err)
+		}
+		pow.Settle(token, middleware.CastAccepted)
+	}
+	busy, err := pow.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if busy.Difficulty <= calm.Difficulty {
+		t.Fatalf("busy Ocean want more work than %d, got %d", calm.Difficulty, busy.Difficulty)
+	}
+}
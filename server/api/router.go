@@ -5,8 +5,10 @@ import (
 
 	"github.com/Polqt/ocealis/api/handler"
 	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/Polqt/ocealis/ws"
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
 	"go.uber.org/zap"
 )
 
@@ -15,12 +17,20 @@ type Handlers struct {
 	Bottle    *handler.BottleHandler
 	Event     *handler.EventHandler
 	Discovery *handler.DiscoveryHandler
+	Ocean     *handler.OceanHandler
+	Share     *handler.ShareHandler
+	// Challenge is set only when Cast uses the self-hosted proof-of-work verifier.
+	Challenge *handler.ChallengeHandler
+	// Idempotency stores Idempotency-Key replays for mutations; nil disables it.
+	Idempotency middleware.IdempotencyStore
 	// User JWT create/login is not product v1 — do not wire here (PRD US28).
 }
 
 // RegisterRoutes wires all HTTP and WebSocket routes onto app.
 func RegisterRoutes(app *fiber.App, h Handlers, hub *ws.Hub, log *zap.Logger) {
 	app.Get("/api/health", h.Health.Check)
+	app.Get("/api/openapi.json", handler.ServeOpenAPI)
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
 
 	app.Get("/", func(c fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -38,18 +48,42 @@ func RegisterRoutes(app *fiber.App, h Handlers, hub *ws.Hub, log *zap.Logger) {
 	})
 	app.Get("/ws", ws.NewDriftHandler(hub, log))
 
-	v1 := app.Group("/api/v1")
+	// Share links — Open Graph page for chat-app unfurls; the card image lives under /api/v1.
+	app.Get("/b/:id", middleware.RateLimit(), h.Share.Page)
+
+	// Clients pinned to the pre-rename vocabulary send Ocealis-Wire-Version: 1.
+	v1 := app.Group("/api/v1", middleware.WireVersion())
+
+	if h.Challenge != nil {
+		v1.Get("/challenge", middleware.RateLimit(), h.Challenge.Issue)
+	}
 
 	// Anonymous Visitor bottle flows — no JWT (CONTEXT.md / PRD).
+	// Idempotency runs before the strict limiter so retried Casts replay without spending budget.
+	// Stamp takes the same chain once its route lands.
+	idem := middleware.Idempotency(h.Idempotency)
+
 	bottles := v1.Group("/bottles")
-	bottles.Post("/", middleware.StrictRateLimit(), h.Bottle.CreateBottle)
+	bottles.Post("/", idem, middleware.StrictRateLimit(), h.Bottle.CreateBottle)
 	bottles.Get("/:id", middleware.RateLimit(), h.Bottle.GetBottle)
 	bottles.Get("/:id/journey", middleware.RateLimit(), h.Bottle.GetJourney)
+	bottles.Get("/:id/journey.:format", middleware.RateLimit(), h.Bottle.ExportJourney)
+	bottles.Get("/:id/card.png", middleware.RateLimit(), h.Share.Card)
+	bottles.Get("/:id/journey/highlights", middleware.RateLimit(), h.Bottle.GetJourneyHighlights)
 	bottles.Get("/:id/events", middleware.RateLimit(), h.Event.GetBottleEvents)
 	bottles.Post("/:id/discover", middleware.StrictRateLimit(), h.Bottle.DiscoverBottle)
-	bottles.Post("/:id/release", middleware.StrictRateLimit(), h.Bottle.ReleaseBottle)
+	bottles.Post("/:id/release", idem, middleware.StrictRateLimit(), h.Bottle.ReleaseBottle)
+
+	v1.Get("/styles", middleware.RateLimit(), handler.ListStyles)
 
 	discovery := v1.Group("/discovery")
 	discovery.Get("/", middleware.RateLimit(), h.Discovery.FindNearby)
 	discovery.Get("/map", middleware.RateLimit(), h.Discovery.BrowseMap)
+	discovery.Get("/search", middleware.RateLimit(), h.Discovery.Search)
+	discovery.Get("/random", middleware.RateLimit(), h.Discovery.Random)
+
+	ocean := v1.Group("/ocean")
+	ocean.Get("/stats", middleware.RateLimit(), h.Ocean.Stats)
+	ocean.Get("/replay", middleware.RateLimit(), h.Ocean.Replay)
+	ocean.Get("/notable", middleware.RateLimit(), h.Ocean.Notable)
 }
@@ -31,10 +31,19 @@ func (f *fakeBottleSvc) GetBottle(context.Context, int32) (*domain.Bottle, error
 func (f *fakeBottleSvc) GetJourney(context.Context, int32) (*domain.Journey, error) {
 	return &domain.Journey{Bottle: f.bottle, Events: nil}, nil
 }
+func (f *fakeBottleSvc) GetJourneyHighlights(context.Context, int32, int) (*domain.Journey, error) {
+	return &domain.Journey{Bottle: f.bottle, Events: nil}, nil
+}
+func (f *fakeBottleSvc) TranslateBottle(context.Context, int32, string) (*domain.TranslatedBottle, error) {
+	return nil, service.ErrTranslationDisabled
+}
+func (f *fakeBottleSvc) BottleVersion(context.Context, int32) (int32, error) {
+	return 0, nil
+}
 func (f *fakeBottleSvc) DiscoverBottle(context.Context, service.DiscoverBottleInput) (*domain.Journey, error) {
 	return nil, nil
 }
-func (f *fakeBottleSvc) ReleaseBottle(context.Context, int32, int32, float64, float64) (*domain.Bottle, error) {
+func (f *fakeBottleSvc) ReleaseBottle(context.Context, service.ReleaseBottleInput) (*domain.Bottle, error) {
 	return nil, nil
 }
 
@@ -51,7 +60,7 @@ func TestAnonymousVisitorCanGetBottleWithoutAuth(t *testing.T) {
 	app := fiber.New()
 	api.RegisterRoutes(app, api.Handlers{
 		Health:    &handler.HealthHandler{},
-		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{bottle: bottle}, nil),
+		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{bottle: bottle}, nil, nil),
 		Event:     handler.NewEventHandler(nil),
 		Discovery: handler.NewDiscoveryHandler(nil),
 	}, hub, log)
@@ -80,7 +89,7 @@ func TestUserJWTCreateLoginIsNotProductRoute(t *testing.T) {
 	app := fiber.New()
 	api.RegisterRoutes(app, api.Handlers{
 		Health:    &handler.HealthHandler{},
-		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{}, nil),
+		Bottle:    handler.NewBottleHandler(&fakeBottleSvc{}, nil, nil),
 		Event:     handler.NewEventHandler(nil),
 		Discovery: handler.NewDiscoveryHandler(nil),
 	}, hub, log)
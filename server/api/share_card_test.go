@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/card"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type shareSvc struct {
+	journeySvc
+	missing bool
+}
+
+func (f *shareSvc) GetBottle(ctx context.Context, id int32) (*domain.Bottle, error) {
+	if f.missing {
+		return nil, service.ErrBottleNotFound
+	}
+	return f.journeySvc.GetBottle(ctx, id)
+}
+
+func (f *shareSvc) GetJourney(ctx context.Context, id int32) (*domain.Journey, error) {
+	if f.missing {
+		return nil, service.ErrBottleNotFound
+	}
+	return f.journeySvc.GetJourney(ctx, id)
+}
+
+func TestShareCardAndOpenGraphPage(t *testing.T) {
+	svc := &shareSvc{journeySvc: journeySvc{
+		fakeBottleSvc: fakeBottleSvc{bottle: &domain.Bottle{ID: 7, Nickname: `<Petrel & "co">`, MessageText: "hello   ocean\nfrom the pier", Status: domain.BottleStatusDrifting}},
+		events: []domain.BottleEvent{
+			{ID: 1, BottleID: 7, EventType: domain.EventTypeCast, Lat: 10, Lng: 179},
+			{ID: 2, BottleID: 7, EventType: domain.EventTypeDrift, Lat: 12, Lng: -179},
+		},
+	}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+		Share:     handler.NewShareHandler(svc, nil, "https://ocealis.example/"),
+	}, ws.NewHub(), zap.NewNop())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/bottles/7/card.png", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "image/png" {
+		t.Fatalf("card: status %d, content type %q", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+	}
+	cfg, err := png.DecodeConfig(resp.Body)
+	resp.Body.Close()
+	if err != nil || cfg.Width != card.Width || cfg.Height != card.Height {
+		t.Fatalf("card: %dx%d, %v", cfg.Width, cfg.Height, err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/b/7", nil)
+	req.Host = "api.ocealis.example"
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	page := string(body)
+	if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/html") {
+		t.Fatalf("page: status %d, content type %q", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+	}
+	for _, want := range []string{
+		`<meta property="og:image" content="http://api.ocealis.example/api/v1/bottles/7/card.png">`,
+		`<meta property="og:url" content="https://ocealis.example/bottle/7">`,
+		`<meta property="og:description" content="hello ocean from the pier">`,
+		"A message in a bottle from \u2068&lt;Petrel &amp; &#34;co&#34;&gt;\u2069",
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("page missing %s:\n%s", want, page)
+		}
+	}
+
+	svc.missing = true
+	for _, target := range []string{"/api/v1/bottles/7/card.png", "/b/7"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Fatalf("%s for a missing Bottle: want 404, got %d", target, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+func TestStylesServesCatalog(t *testing.T) {
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(nil, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/styles", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var got []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(styles.All()) {
+		t.Fatalf("%d styles served, catalog has %d", len(got), len(styles.All()))
+	}
+	seed := got[styles.Seed]
+	for _, key := range []string{"id", "name", "asset_key", "leeway", "reserved"} {
+		if _, ok := seed[key]; !ok {
+			t.Fatalf("style missing %s: %v", key, seed)
+		}
+	}
+	if seed["reserved"] != true {
+		t.Fatalf("Seed style not reserved: %v", seed)
+	}
+}
@@ -0,0 +1,85 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+// tracedBottleSvc opens a child span the way bottleService does.
+type tracedBottleSvc struct {
+	fakeBottleSvc
+}
+
+func (f *tracedBottleSvc) GetBottle(ctx context.Context, id int32) (_ *domain.Bottle, err error) {
+	_, span := telemetry.Start(ctx, "BottleService.GetBottle")
+	defer func() { telemetry.End(span, err) }()
+	return f.fakeBottleSvc.GetBottle(ctx, id)
+}
+
+func inMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	prev := otel.GetTracerProvider()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	telemetry.Install(tp)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prev)
+	})
+	return exp
+}
+
+func TestTracingNamesServerSpanByRouteAndNestsServiceSpans(t *testing.T) {
+	exp := inMemoryTracing(t)
+
+	app := fiber.New()
+	app.Use(middleware.Tracing())
+	bottle := &domain.Bottle{ID: 1, MessageText: "hello ocean", Status: domain.BottleStatusDrifting, CreatedAt: time.Now()}
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(&tracedBottleSvc{fakeBottleSvc{bottle: bottle}}, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/bottles/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("want 200, got %d", resp.StatusCode)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("want service + server span, got %d", len(spans))
+	}
+	child, server := spans[0], spans[1]
+	if server.Name != "GET /api/v1/bottles/:id" {
+		t.Fatalf("server span name = %q", server.Name)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("want incoming traceparent continued, got trace %s", got)
+	}
+	if child.Name != "BottleService.GetBottle" || child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatalf("want BottleService.GetBottle under server span, got %q parent %s", child.Name, child.Parent.SpanID())
+	}
+}
@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/translate"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type translateSvc struct {
+	fakeBottleSvc
+	translator translate.Translator
+}
+
+func (f *translateSvc) TranslateBottle(ctx context.Context, _ int32, target string) (*domain.TranslatedBottle, error) {
+	if f.translator == nil {
+		return f.fakeBottleSvc.TranslateBottle(ctx, f.bottle.ID, target)
+	}
+	text, err := f.translator.Translate(ctx, f.bottle.MessageText, "en", target)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TranslatedBottle{Bottle: *f.bottle, Translation: domain.Translation{Lang: target, SourceLang: "en", MessageText: text}}, nil
+}
+
+func TestGetBottleWithLangAddsTranslation(t *testing.T) {
+	bottle := &domain.Bottle{ID: 9, MessageText: "hello ocean", Status: domain.BottleStatusDrifting}
+	svc := &translateSvc{fakeBottleSvc: fakeBottleSvc{bottle: bottle}, translator: translate.Dictionary{"fr": {"hello ocean": "bonjour océan"}}}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get("/api/v1/bottles/9?lang=fr")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var body struct {
+		MessageText string             `json:"message_text"`
+		Translation domain.Translation `json:"translation"`
+		WireVersion int                `json:"wire_version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.MessageText != "hello ocean" || body.Translation.MessageText != "bonjour océan" || body.Translation.Lang != "fr" || body.WireVersion == 0 {
+		t.Fatalf("body %+v", body)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/bottles/9":                  http.StatusOK,
+		"/api/v1/bottles/9?lang=xx":          http.StatusUnprocessableEntity, // dictionary has no xx
+		"/api/v1/bottles/9?lang=not_a_lang!": http.StatusUnprocessableEntity,
+	} {
+		if got := get(path).StatusCode; got != want {
+			t.Errorf("%s: status %d, want %d", path, got, want)
+		}
+	}
+
+	svc.translator = nil
+	if got := get("/api/v1/bottles/9?lang=fr").StatusCode; got != http.StatusNotImplemented {
+		t.Fatalf("without a translator: status %d, want 501", got)
+	}
+}
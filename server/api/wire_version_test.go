@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/api"
+	"github.com/Polqt/ocealis/api/handler"
+	"github.com/Polqt/ocealis/api/middleware"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type journeySvc struct {
+	fakeBottleSvc
+	events []domain.BottleEvent
+}
+
+func (f *journeySvc) GetJourney(context.Context, int32) (*domain.Journey, error) {
+	return &domain.Journey{Bottle: f.bottle, Events: f.events}, nil
+}
+
+func TestWireVersionOneServesPreRenameVocabulary(t *testing.T) {
+	visible := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := &journeySvc{
+		fakeBottleSvc: fakeBottleSvc{bottle: &domain.Bottle{ID: 7, Status: domain.BottleStatusMysteryDelay, VisibleAt: visible}},
+		events:        []domain.BottleEvent{{ID: 1, BottleID: 7, EventType: domain.EventTypeCast}},
+	}
+	app := fiber.New()
+	api.RegisterRoutes(app, api.Handlers{
+		Health:    &handler.HealthHandler{},
+		Bottle:    handler.NewBottleHandler(svc, nil, nil),
+		Event:     handler.NewEventHandler(nil),
+		Discovery: handler.NewDiscoveryHandler(nil),
+	}, ws.NewHub(), zap.NewNop())
+
+	type journey struct {
+		Bottle map[string]any   `json:"bottle"`
+		Events []map[string]any `json:"events"`
+	}
+	get := func(pin string) (journey, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/bottles/7/journey", nil)
+		if pin != "" {
+			req.Header.Set(middleware.WireVersionHeader, pin)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var j journey
+		if err := json.NewDecoder(resp.Body).Decode(&j); err != nil {
+			t.Fatal(err)
+		}
+		return j, resp.Header.Get(middleware.WireVersionHeader)
+	}
+
+	current, version := get("")
+	if version != "2" || current.Bottle["status"] != "mystery_delay" || current.Events[0]["event_type"] != "cast" {
+		t.Fatalf("default should be wire_version 2, got %s %+v", version, current)
+	}
+	if _, ok := current.Bottle["scheduled_release"]; ok {
+		t.Fatal("scheduled_release must not appear in wire_version 2")
+	}
+
+	legacy, version := get("1")
+	if version != "1" || legacy.Bottle["wire_version"] != float64(1) {
+		t.Fatalf("want wire_version 1, got header %s body %+v", version, legacy.Bottle)
+	}
+	if legacy.Bottle["status"] != "scheduled" || legacy.Events[0]["event_type"] != "released" {
+		t.Fatalf("want pre-rename spellings, got %+v", legacy)
+	}
+	if legacy.Bottle["scheduled_release"] != "2026-01-02T03:04:05Z" || legacy.Bottle["id"] != float64(7) {
+		t.Fatalf("want scheduled_release mirrored from visible_at, got %+v", legacy.Bottle)
+	}
+}
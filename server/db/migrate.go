@@ -0,0 +1,410 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Migrations are the goose-style files applied by Migrator, in version order.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// Schema is the sqlc view of the database; TestSchemaMatchesMigrations keeps it honest.
+//
+//go:embed schema.sql
+var Schema string
+
+// migrateLockID is the pg_advisory_lock key — one migrator per database at a time.
+const migrateLockID int64 = 0x6f63656c6973 // "ocelis"
+
+var ErrNoMigrationToRevert = errors.New("no applied migration to revert")
+
+// Migration is one db/migrations/NNNNN_name.sql file split at its goose markers.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// NoTransaction is set by "-- +goose NO TRANSACTION" (e.g. CREATE INDEX CONCURRENTLY).
+	NoTransaction bool
+	// Manual is set by "-- +ocealis manual" on contract steps that must wait until no old
+	// instance is serving; start-up migration stops in front of them.
+	Manual bool
+}
+
+// Checksum fingerprints the Up SQL so status can flag files edited after they ran.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:8])
+}
+
+// MigrationStatus is one row of `migrate status`.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	// Modified means the applied checksum no longer matches the embedded file.
+	Modified bool
+}
+
+// LoadMigrations reads every *.sql under migrations/ in fsys, sorted by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(names))
+	seen := make(map[int64]string, len(names))
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".sql")
+		prefix, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: want NNNNN_name.sql", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: bad version: %w", name, err)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migration %s: version %d already used by %s", name, version, prev)
+		}
+		seen[version] = name
+
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m := parseMigration(string(raw))
+		m.Version, m.Name = version, label
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %s: empty up section", name)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseMigration splits on "-- +goose up/down"; StatementBegin/End are kept as
+// plain comments since each section runs as a single simple-protocol Exec.
+func parseMigration(raw string) Migration {
+	var m Migration
+	var up, down strings.Builder
+	var section *strings.Builder
+	for _, line := range strings.SplitAfter(raw, "\n") {
+		directive := strings.ToLower(strings.Join(strings.Fields(line), " "))
+		switch directive {
+		case "-- +goose up":
+			section = &up
+			continue
+		case "-- +goose down":
+			section = &down
+			continue
+		case "-- +goose no transaction":
+			m.NoTransaction = true
+			continue
+		case "-- +ocealis manual":
+			m.Manual = true
+			continue
+		}
+		if section != nil {
+			section.WriteString(line)
+		}
+	}
+	m.Up, m.Down = strings.TrimSpace(up.String()), strings.TrimSpace(down.String())
+	return m
+}
+
+// Migrator applies embedded Migrations and records them in schema_migrations.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	log        *zap.Logger
+}
+
+func NewMigrator(pool *pgxpool.Pool, log *zap.Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations(Migrations)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations:%w", err)
+	}
+	return &Migrator{pool: pool, migrations: migrations, log: log}, nil
+}
+
+// Up applies every pending migration in order and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.UpTo(ctx, math.MaxInt64)
+}
+
+// UpTo applies pending migrations up to and including version — the pause between the
+// expand and rewrite steps of a rename while old instances drain.
+func (m *Migrator) UpTo(ctx context.Context, version int64) (int, error) {
+	return m.up(ctx, version, false)
+}
+
+// UpAutomatic applies pending migrations up to the first Manual one, which only an
+// explicit Up or UpTo runs. It is what instances do on start.
+func (m *Migrator) UpAutomatic(ctx context.Context) (int, error) {
+	return m.up(ctx, math.MaxInt64, true)
+}
+
+func (m *Migrator) up(ctx context.Context, version int64, stopAtManual bool) (int, error) {
+	ran := 0
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, done := applied[mig.Version]; done {
+				continue
+			}
+			if stopAtManual && mig.Manual {
+				m.log.Info("migration left for an explicit migrate up", zap.Int64("version", mig.Version), zap.String("name", mig.Name))
+				break
+			}
+			if err := m.apply(ctx, conn, mig, mig.Up, func(tx execer) error {
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum())
+				return err
+			}); err != nil {
+				return fmt.Errorf("migrate up %05d_%s:%w", mig.Version, mig.Name, err)
+			}
+			m.log.Info("migration applied", zap.Int64("version", mig.Version), zap.String("name", mig.Name))
+			ran++
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down reverts the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, done := applied[mig.Version]; !done {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %05d_%s has no down section", mig.Version, mig.Name)
+			}
+			if err := m.apply(ctx, conn, mig, mig.Down, func(tx execer) error {
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migrate down %05d_%s:%w", mig.Version, mig.Name, err)
+			}
+			m.log.Info("migration reverted", zap.Int64("version", mig.Version), zap.String("name", mig.Name))
+			reverted = &mig
+			return nil
+		}
+		return ErrNoMigrationToRevert
+	})
+	return reverted, err
+}
+
+// Status lists every embedded migration with when (and whether unchanged) it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var out []MigrationStatus
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := MigrationStatus{Migration: mig}
+			if row, ok := applied[mig.Version]; ok {
+				at := row.appliedAt
+				st.AppliedAt = &at
+				st.Modified = row.checksum != mig.Checksum()
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Drift compares the live tables against the applied migrations and schema.sql
+// against the full migration chain. Empty means all three agree.
+func (m *Migrator) Drift(ctx context.Context) ([]string, error) {
+	want, err := ParseSchema(Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema.sql:%w", err)
+	}
+	chain, err := SchemaFromMigrations(m.migrations)
+	if err != nil {
+		return nil, err
+	}
+	var drift []string
+	for _, d := range DiffSchemas(want, chain) {
+		drift = append(drift, "schema.sql vs migrations: "+d)
+	}
+
+	err = m.locked(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		var ran []Migration
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				ran = append(ran, mig)
+			}
+		}
+		expected, err := SchemaFromMigrations(ran)
+		if err != nil {
+			return err
+		}
+		live, err := liveSchema(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, d := range DiffSchemas(expected, live) {
+			drift = append(drift, "migrations vs database: "+d)
+		}
+		return nil
+	})
+	return drift, err
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// locked runs fn on one connection holding the migrate advisory lock,
+// so two instances starting together do not race the same migration.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire conn:%w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrateLockID); err != nil {
+		return fmt.Errorf("advisory lock:%w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrateLockID)
+	}()
+
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    checksum   TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`); err != nil {
+		return fmt.Errorf("create schema_migrations:%w", err)
+	}
+	return fn(conn.Conn())
+}
+
+// apply runs sql and the bookkeeping statement together, in one transaction
+// unless the migration opted out.
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, mig Migration, sql string, record func(execer) error) error {
+	if mig.NoTransaction {
+		// One Exec per statement: a multi-statement simple query is an implicit
+		// transaction, which CREATE INDEX CONCURRENTLY refuses.
+		for _, stmt := range splitStatements(sql) {
+			if _, err := conn.Exec(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return record(conn)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction:%w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type appliedRow struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func appliedVersions(ctx context.Context, conn *pgx.Conn) (map[int64]appliedRow, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations:%w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedRow)
+	for rows.Next() {
+		var v int64
+		var row appliedRow
+		if err := rows.Scan(&v, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[v] = row
+	}
+	return applied, rows.Err()
+}
+
+// liveSchema reads tables/columns in the connection's current schema.
+func liveSchema(ctx context.Context, conn *pgx.Conn) (SchemaModel, error) {
+	rows, err := conn.Query(ctx, `
+SELECT c.table_name, c.column_name, c.data_type, c.is_nullable = 'NO'
+FROM information_schema.columns c
+JOIN information_schema.tables t USING (table_schema, table_name)
+WHERE c.table_schema = current_schema()
+  AND t.table_type = 'BASE TABLE'
+  AND c.table_name <> 'schema_migrations'`)
+	if err != nil {
+		return nil, fmt.Errorf("read information_schema:%w", err)
+	}
+	defer rows.Close()
+
+	live := make(SchemaModel)
+	for rows.Next() {
+		var table string
+		var col Column
+		if err := rows.Scan(&table, &col.Name, &col.Type, &col.NotNull); err != nil {
+			return nil, err
+		}
+		if live[table] == nil {
+			live[table] = make(map[string]Column)
+		}
+		live[table][col.Name] = col
+	}
+	return live, rows.Err()
+}
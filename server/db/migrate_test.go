@@ -0,0 +1,120 @@
+package db_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Polqt/ocealis/db"
+	"github.com/Polqt/ocealis/internal/lang"
+)
+
+func TestSchemaMatchesMigrations(t *testing.T) {
+	migrations, err := db.LoadMigrations(db.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := db.SchemaFromMigrations(migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := db.ParseSchema(db.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift := db.DiffSchemas(want, chain); len(drift) > 0 {
+		t.Fatalf("schema.sql and db/migrations disagree:\n%s", strings.Join(drift, "\n"))
+	}
+	for _, m := range migrations {
+		if m.Down == "" {
+			t.Errorf("migration %05d_%s has no down section", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsOrdersAndSplitsSections(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/00010_later.sql": {Data: []byte("-- +ocealis manual\n-- +goose Up\n-- +goose NO TRANSACTION\nCREATE INDEX CONCURRENTLY b_idx ON b (x);\n-- +goose Down\nDROP INDEX b_idx;\n")},
+		"migrations/00002_first.sql": {Data: []byte("-- +goose up\n\n-- +goose statementbegin\nCREATE TABLE a (id INT);\n-- +goose StatementEnd\n")},
+	}
+	migrations, err := db.LoadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Name != "later" {
+		t.Fatalf("want 00002 then 00010, got %+v", migrations)
+	}
+	if !strings.Contains(migrations[0].Up, "CREATE TABLE a") || migrations[0].Down != "" {
+		t.Fatalf("bad split: %+v", migrations[0])
+	}
+	if !migrations[1].NoTransaction || !migrations[1].Manual || migrations[1].Down != "DROP INDEX b_idx;" {
+		t.Fatalf("want NO TRANSACTION, manual and down section, got %+v", migrations[1])
+	}
+	if migrations[0].Manual {
+		t.Fatalf("unmarked migration loaded as manual: %+v", migrations[0])
+	}
+
+	fsys["migrations/00002_dupe.sql"] = &fstest.MapFile{Data: []byte("-- +goose up\nSELECT 1;\n")}
+	if _, err := db.LoadMigrations(fsys); err == nil {
+		t.Fatal("want duplicate version rejected")
+	}
+}
+
+// Rewriting rows old instances still read must wait for an explicit migrate up.
+func TestCastVocabularyRewriteIsManual(t *testing.T) {
+	migrations, err := db.LoadMigrations(db.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations {
+		if m.Name == "cast_vocabulary_rewrite" && !m.Manual {
+			t.Fatalf("migration %05d_%s would run on start", m.Version, m.Name)
+		}
+	}
+}
+
+func TestDiffSchemasReportsDrift(t *testing.T) {
+	want, err := db.ParseSchema(`
+CREATE TABLE bottles (
+    id      SERIAL PRIMARY KEY,
+    status  TEXT NOT NULL,
+    payload JSONB
+);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.ParseSchema(`
+CREATE TABLE bottles (id INTEGER NOT NULL, state TEXT, payload TEXT);
+ALTER TABLE bottles RENAME COLUMN state TO status;
+CREATE TABLE stray (id INT);
+-- a ; inside a function body must not split the statement
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN NEW.x := 1; RETURN NEW; END $$ LANGUAGE plpgsql;
+ALTER TABLE bottles ADD COLUMN IF NOT EXISTS hops INT DEFAULT 0;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drift := db.DiffSchemas(want, got)
+	wantDrift := []string{
+		"bottles.hops: unexpected column",
+		"bottles.payload: type text, want jsonb",
+		"bottles.status: not null false, want true",
+		"table stray: unexpected",
+	}
+	if strings.Join(drift, "\n") != strings.Join(wantDrift, "\n") {
+		t.Fatalf("drift:\n%s\nwant:\n%s", strings.Join(drift, "\n"), strings.Join(wantDrift, "\n"))
+	}
+}
+
+// message_search_query must parse a query under every config a Cast can store.
+func TestSearchQueryCoversSupportedLanguages(t *testing.T) {
+	src, err := db.Migrations.ReadFile("migrations/00013_bottle_search.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range append([]lang.Language{lang.Undetermined}, lang.Supported...) {
+		if !strings.Contains(string(src), "websearch_to_tsquery('"+l.Config+"', q)") {
+			t.Errorf("message_search_query misses config %s", l.Config)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose up
+
+-- +goose statementbegin
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    nickname TEXT NOT NULL,
+    avatar_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TABLE users;
+-- +goose StatementEnd
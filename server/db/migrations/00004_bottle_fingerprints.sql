@@ -0,0 +1,17 @@
+-- +goose up
+
+-- +goose statementbegin
+CREATE TABLE IF NOT EXISTS bottle_fingerprints (
+    bottle_id   INTEGER PRIMARY KEY REFERENCES bottles(id) ON DELETE CASCADE,
+    fingerprint BIGINT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS bottle_fingerprints_created_at_idx ON bottle_fingerprints (created_at);
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TABLE bottle_fingerprints;
+-- +goose StatementEnd
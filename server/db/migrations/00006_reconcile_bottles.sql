@@ -0,0 +1,39 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Bring the 00002/00003 tables up to what the app has been running against (schema.sql).
+ALTER TABLE bottles
+    ADD COLUMN IF NOT EXISTS nickname TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS current_lat DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS current_lng DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'drifting',
+    ALTER COLUMN bottle_style SET DEFAULT 0,
+    ALTER COLUMN scheduled_release DROP DEFAULT;
+
+-- Only Bottles still inside their delay: a database that already had status holds live
+-- drifting rows the scheduler flipped itself, and those stay put.
+UPDATE bottles SET status = 'scheduled'
+WHERE is_release IS NOT TRUE AND status = 'drifting' AND scheduled_release > NOW();
+UPDATE bottles SET current_lat = start_lat, current_lng = start_lng WHERE current_lat IS NULL;
+UPDATE bottles SET created_at = NOW() WHERE created_at IS NULL;
+UPDATE bottle_events SET created_at = NOW() WHERE created_at IS NULL;
+
+ALTER TABLE bottles
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE bottle_events ALTER COLUMN created_at SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+ALTER TABLE bottle_events ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE bottles
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN scheduled_release SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN bottle_style DROP DEFAULT,
+    DROP COLUMN status,
+    DROP COLUMN current_lng,
+    DROP COLUMN current_lat,
+    DROP COLUMN nickname;
+-- +goose StatementEnd
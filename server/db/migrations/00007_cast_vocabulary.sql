@@ -0,0 +1,40 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Expand step of the Cast / Mystery Delay rename: released → cast, scheduled → mystery_delay,
+-- scheduled_release → visible_at. Existing rows keep their old spellings here, because
+-- instances from before the rename still read them during a rolling deploy; current
+-- readers accept both, and 00016 rewrites the rows once those instances are gone. The
+-- trigger keeps visible_at and scheduled_release in step whichever one a writer sets.
+-- A later contract migration drops scheduled_release and the triggers.
+ALTER TABLE bottles ADD COLUMN IF NOT EXISTS visible_at TIMESTAMPTZ;
+
+UPDATE bottles SET visible_at = scheduled_release WHERE visible_at IS NULL;
+
+CREATE OR REPLACE FUNCTION bottles_cast_vocabulary() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        NEW.visible_at := COALESCE(NEW.visible_at, NEW.scheduled_release);
+    ELSIF NEW.visible_at IS NOT DISTINCT FROM OLD.visible_at THEN
+        NEW.visible_at := NEW.scheduled_release; -- old writer moved scheduled_release
+    END IF;
+    NEW.scheduled_release := NEW.visible_at;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bottles_cast_vocabulary
+    BEFORE INSERT OR UPDATE ON bottles
+    FOR EACH ROW EXECUTE FUNCTION bottles_cast_vocabulary();
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TRIGGER bottles_cast_vocabulary ON bottles;
+DROP FUNCTION bottles_cast_vocabulary();
+
+UPDATE bottles SET scheduled_release = visible_at;
+
+ALTER TABLE bottles DROP COLUMN visible_at;
+-- +goose StatementEnd
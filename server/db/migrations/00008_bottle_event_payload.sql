@@ -0,0 +1,13 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Typed per-EventType details (Stamp seal/note, Re-release Nickname, drift vector, Sink reason).
+-- Constant default: no table rewrite, older rows read back as an empty payload.
+ALTER TABLE bottle_events ADD COLUMN IF NOT EXISTS payload JSONB NOT NULL DEFAULT '{}'::jsonb;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+ALTER TABLE bottle_events DROP COLUMN payload;
+-- +goose StatementEnd
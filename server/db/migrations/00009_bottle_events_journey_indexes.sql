@@ -0,0 +1,23 @@
+-- +goose NO TRANSACTION
+-- +goose up
+
+-- +goose statementbegin
+-- Journey highlights read one Bottle's events by type; compaction scans old drift rows.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS bottle_events_bottle_type_created_idx
+    ON bottle_events (bottle_id, event_type, created_at);
+-- +goose StatementEnd
+
+-- +goose statementbegin
+CREATE INDEX CONCURRENTLY IF NOT EXISTS bottle_events_drift_created_idx
+    ON bottle_events (created_at) WHERE event_type = 'drift';
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP INDEX CONCURRENTLY IF EXISTS bottle_events_drift_created_idx;
+-- +goose StatementEnd
+
+-- +goose statementbegin
+DROP INDEX CONCURRENTLY IF EXISTS bottle_events_bottle_type_created_idx;
+-- +goose StatementEnd
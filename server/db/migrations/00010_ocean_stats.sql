@@ -0,0 +1,174 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Ocean-wide totals for GET /ocean/stats, kept by statement-level triggers so reading
+-- them never scans bottles or bottle_events. Names are 'status:<status>',
+-- 'basin:<basin>' (drifting Bottles only) and 'km_drifted'. Each total is spread over
+-- shards, one picked at random per statement and summed on read, so concurrent writers
+-- rarely wait on each other's counter rows until commit.
+CREATE TABLE ocean_counters (
+    name  TEXT NOT NULL,
+    shard SMALLINT NOT NULL DEFAULT 0,
+    value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (name, shard)
+);
+
+-- Casts and Stamps per hour, sharded like ocean_counters; /ocean/stats sums the last
+-- 24 buckets.
+CREATE TABLE ocean_activity_hourly (
+    hour  TIMESTAMPTZ NOT NULL,
+    kind  TEXT NOT NULL,
+    shard SMALLINT NOT NULL DEFAULT 0,
+    n     BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (hour, kind, shard)
+);
+
+CREATE OR REPLACE FUNCTION ocean_counter_shard() RETURNS SMALLINT AS $$
+    SELECT floor(random() * 16)::smallint
+$$ LANGUAGE sql VOLATILE;
+
+-- Mirror of geo.Basin — keep the two in step.
+CREATE OR REPLACE FUNCTION ocean_basin(lat DOUBLE PRECISION, lng DOUBLE PRECISION) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN lat >= 0 AND lng >= -80 AND lng <= 0 THEN 'north_atlantic'
+        WHEN lat < 0 AND lng >= -60 AND lng <= 20 THEN 'south_atlantic'
+        WHEN lat >= 0 AND (lng >= 120 OR lng <= -120) THEN 'north_pacific'
+        WHEN lat < 0 AND (lng >= 150 OR lng <= -70) THEN 'south_pacific'
+        WHEN lat >= -60 AND lat <= 25 AND lng >= 40 AND lng <= 120 THEN 'indian_ocean'
+        ELSE 'other'
+    END
+$$ LANGUAGE sql IMMUTABLE;
+
+-- The counter names one Bottle row contributes to.
+CREATE OR REPLACE FUNCTION ocean_bottle_keys(bottle_status TEXT, lat DOUBLE PRECISION, lng DOUBLE PRECISION) RETURNS SETOF TEXT AS $$
+    SELECT 'status:' || bottle_status
+    UNION ALL
+    SELECT 'basin:' || ocean_basin(lat, lng) WHERE bottle_status = 'drifting'
+$$ LANGUAGE sql IMMUTABLE;
+
+-- One function behind three triggers: transition tables cannot span several events,
+-- and each branch only touches the tables its event defines. Rows are written in key
+-- order so two writers that picked the same shard lock them in the same order.
+CREATE OR REPLACE FUNCTION ocean_stats_bottles() RETURNS trigger AS $$
+DECLARE
+    s SMALLINT := ocean_counter_shard();
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT k, s, sum(d) FROM (
+            SELECT k, -1 AS d FROM old_rows, ocean_bottle_keys(old_rows.status, old_rows.current_lat, old_rows.current_lng) k
+            UNION ALL
+            SELECT k, 1 FROM new_rows, ocean_bottle_keys(new_rows.status, new_rows.current_lat, new_rows.current_lng) k
+        ) delta GROUP BY k HAVING sum(d) <> 0 ORDER BY k
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+    ELSIF TG_OP = 'INSERT' THEN
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT k, s, count(*) FROM new_rows, ocean_bottle_keys(new_rows.status, new_rows.current_lat, new_rows.current_lng) k GROUP BY k ORDER BY k
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+
+        -- A Bottle row is one Cast; the cast event repeats when Mystery Delay ends.
+        INSERT INTO ocean_activity_hourly AS a (hour, kind, shard, n)
+        SELECT date_trunc('hour', created_at), 'cast', s, count(*) FROM new_rows GROUP BY 1 ORDER BY 1
+        ON CONFLICT (hour, kind, shard) DO UPDATE SET n = a.n + EXCLUDED.n;
+    ELSE
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT k, s, -count(*) FROM old_rows, ocean_bottle_keys(old_rows.status, old_rows.current_lat, old_rows.current_lng) k GROUP BY k ORDER BY k
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ocean_stats_bottles_insert
+    AFTER INSERT ON bottles REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_bottles();
+
+CREATE TRIGGER ocean_stats_bottles_update
+    AFTER UPDATE ON bottles REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_bottles();
+
+CREATE TRIGGER ocean_stats_bottles_delete
+    AFTER DELETE ON bottles REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_bottles();
+
+-- Drift compaction folds a day's tick distances into the day's last event and deletes
+-- the rest: the UPDATE adds what the DELETE takes away, so km_drifted nets out unchanged.
+CREATE OR REPLACE FUNCTION ocean_stats_events() RETURNS trigger AS $$
+DECLARE
+    s SMALLINT := ocean_counter_shard();
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT 'km_drifted', s, sum(COALESCE((payload->>'distance_km')::double precision, 0))
+        FROM new_rows WHERE event_type = 'drift' HAVING count(*) > 0
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+
+        INSERT INTO ocean_activity_hourly AS a (hour, kind, shard, n)
+        SELECT date_trunc('hour', created_at), 'stamp', s, count(*) FROM new_rows WHERE event_type = 'stamp' GROUP BY 1 ORDER BY 1
+        ON CONFLICT (hour, kind, shard) DO UPDATE SET n = a.n + EXCLUDED.n;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT 'km_drifted', s, n.km - o.km
+        FROM (SELECT COALESCE(sum((payload->>'distance_km')::double precision), 0) AS km
+              FROM new_rows WHERE event_type = 'drift') n,
+             (SELECT COALESCE(sum((payload->>'distance_km')::double precision), 0) AS km
+              FROM old_rows WHERE event_type = 'drift') o
+        WHERE n.km <> o.km
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+    ELSE
+        INSERT INTO ocean_counters AS c (name, shard, value)
+        SELECT 'km_drifted', s, -sum(COALESCE((payload->>'distance_km')::double precision, 0))
+        FROM old_rows WHERE event_type = 'drift' HAVING count(*) > 0
+        ON CONFLICT (name, shard) DO UPDATE SET value = c.value + EXCLUDED.value;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ocean_stats_events_insert
+    AFTER INSERT ON bottle_events REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_events();
+
+CREATE TRIGGER ocean_stats_events_update
+    AFTER UPDATE ON bottle_events REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_events();
+
+CREATE TRIGGER ocean_stats_events_delete
+    AFTER DELETE ON bottle_events REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION ocean_stats_events();
+
+-- Backfill after the triggers exist: they hold bottles/bottle_events locks until commit,
+-- so no write lands between the count and the first trigger firing.
+INSERT INTO ocean_counters (name, value)
+SELECT k, count(*) FROM bottles, ocean_bottle_keys(bottles.status, bottles.current_lat, bottles.current_lng) k GROUP BY k;
+
+INSERT INTO ocean_counters (name, value)
+SELECT 'km_drifted', COALESCE(sum((payload->>'distance_km')::double precision), 0)
+FROM bottle_events WHERE event_type = 'drift';
+
+INSERT INTO ocean_activity_hourly (hour, kind, n)
+SELECT date_trunc('hour', created_at), 'cast', count(*) FROM bottles
+WHERE created_at >= NOW() - INTERVAL '48 hours' GROUP BY 1;
+
+INSERT INTO ocean_activity_hourly (hour, kind, n)
+SELECT date_trunc('hour', created_at), 'stamp', count(*) FROM bottle_events
+WHERE event_type = 'stamp' AND created_at >= NOW() - INTERVAL '48 hours' GROUP BY 1;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TRIGGER ocean_stats_events_delete ON bottle_events;
+DROP TRIGGER ocean_stats_events_update ON bottle_events;
+DROP TRIGGER ocean_stats_events_insert ON bottle_events;
+DROP FUNCTION ocean_stats_events();
+DROP TRIGGER ocean_stats_bottles_delete ON bottles;
+DROP TRIGGER ocean_stats_bottles_update ON bottles;
+DROP TRIGGER ocean_stats_bottles_insert ON bottles;
+DROP FUNCTION ocean_stats_bottles();
+DROP FUNCTION ocean_bottle_keys(TEXT, DOUBLE PRECISION, DOUBLE PRECISION);
+DROP FUNCTION ocean_basin(DOUBLE PRECISION, DOUBLE PRECISION);
+DROP FUNCTION ocean_counter_shard();
+DROP TABLE ocean_activity_hourly;
+DROP TABLE ocean_counters;
+-- +goose StatementEnd
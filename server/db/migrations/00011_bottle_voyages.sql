@@ -0,0 +1,118 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Per-Bottle Journey totals behind GET /ocean/notable, kept by a statement-level trigger
+-- on bottle_events so rankings are index reads, never a replay of every Journey.
+CREATE TABLE bottle_voyages (
+    bottle_id   INT PRIMARY KEY REFERENCES bottles(id) ON DELETE CASCADE,
+    distance_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    re_releases INT NOT NULL DEFAULT 0,
+    stamps      INT NOT NULL DEFAULT 0,
+    basins      TEXT[] NOT NULL DEFAULT '{}',
+    basin_count INT GENERATED ALWAYS AS (cardinality(basins)) STORED,
+    last_lat    DOUBLE PRECISION,
+    last_lng    DOUBLE PRECISION
+);
+
+CREATE INDEX bottle_voyages_distance_idx ON bottle_voyages (distance_km DESC);
+CREATE INDEX bottle_voyages_re_releases_idx ON bottle_voyages (re_releases DESC) WHERE re_releases > 0;
+CREATE INDEX bottle_voyages_stamps_idx ON bottle_voyages (stamps DESC) WHERE stamps > 0;
+CREATE INDEX bottle_voyages_basin_count_idx ON bottle_voyages (basin_count DESC);
+CREATE INDEX bottles_drifting_created_idx ON bottles (created_at) WHERE status = 'drifting';
+
+CREATE OR REPLACE FUNCTION great_circle_km(lat1 DOUBLE PRECISION, lng1 DOUBLE PRECISION, lat2 DOUBLE PRECISION, lng2 DOUBLE PRECISION)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT 2 * 6371.0 * asin(least(1, sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2))))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Each statement's events are folded per Bottle: legs chain from the stored last position
+-- through the new positions in id order. Compacted drift waypoints (payload.ticks) restate
+-- history the voyage already counted, so they are skipped.
+CREATE OR REPLACE FUNCTION bottle_voyages_events() RETURNS trigger AS $$
+BEGIN
+    WITH moves AS (
+        SELECT bottle_id, id, event_type, lat, lng
+        FROM new_rows
+        WHERE bottle_id IS NOT NULL
+          AND event_type IN ('cast', 'released', 'drift', 're_released', 'stamp')
+          AND NOT (event_type = 'drift' AND payload ? 'ticks')
+    ), legs AS (
+        SELECT m.bottle_id, m.id, m.lat, m.lng,
+               great_circle_km(COALESCE(lag(m.lat) OVER w, prior.last_lat), COALESCE(lag(m.lng) OVER w, prior.last_lng),
+                               m.lat, m.lng) AS km
+        FROM moves m
+        LEFT JOIN bottle_voyages prior ON prior.bottle_id = m.bottle_id
+        WHERE m.event_type <> 'stamp' AND m.lat IS NOT NULL AND m.lng IS NOT NULL
+        WINDOW w AS (PARTITION BY m.bottle_id ORDER BY m.id)
+    ), leg_totals AS (
+        SELECT bottle_id,
+               COALESCE(sum(km), 0) AS km,
+               array_agg(DISTINCT ocean_basin(lat, lng)) AS basins,
+               (array_agg(lat ORDER BY id DESC))[1] AS last_lat,
+               (array_agg(lng ORDER BY id DESC))[1] AS last_lng
+        FROM legs GROUP BY bottle_id
+    ), counts AS (
+        SELECT bottle_id,
+               count(*) FILTER (WHERE event_type = 're_released') AS re_releases,
+               count(*) FILTER (WHERE event_type = 'stamp') AS stamps
+        FROM moves GROUP BY bottle_id
+    )
+    INSERT INTO bottle_voyages AS v (bottle_id, distance_km, re_releases, stamps, basins, last_lat, last_lng)
+    SELECT c.bottle_id, COALESCE(t.km, 0), c.re_releases, c.stamps, COALESCE(t.basins, '{}'), t.last_lat, t.last_lng
+    FROM counts c LEFT JOIN leg_totals t USING (bottle_id)
+    ON CONFLICT (bottle_id) DO UPDATE SET
+        distance_km = v.distance_km + EXCLUDED.distance_km,
+        re_releases = v.re_releases + EXCLUDED.re_releases,
+        stamps      = v.stamps + EXCLUDED.stamps,
+        basins      = ARRAY(SELECT DISTINCT b FROM unnest(v.basins || EXCLUDED.basins) b ORDER BY b),
+        last_lat    = COALESCE(EXCLUDED.last_lat, v.last_lat),
+        last_lng    = COALESCE(EXCLUDED.last_lng, v.last_lng);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bottle_voyages_events
+    AFTER INSERT ON bottle_events REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bottle_voyages_events();
+
+-- Backfill from every Journey so far. Old drift may already be compacted into daily
+-- waypoints, so here they are the only record and count as legs.
+WITH moves AS (
+    SELECT bottle_id, id, event_type, lat, lng
+    FROM bottle_events
+    WHERE bottle_id IS NOT NULL AND event_type IN ('cast', 'released', 'drift', 're_released', 'stamp')
+), legs AS (
+    SELECT bottle_id, id, lat, lng,
+           great_circle_km(lag(lat) OVER w, lag(lng) OVER w, lat, lng) AS km
+    FROM moves
+    WHERE event_type <> 'stamp' AND lat IS NOT NULL AND lng IS NOT NULL
+    WINDOW w AS (PARTITION BY bottle_id ORDER BY id)
+), leg_totals AS (
+    SELECT bottle_id,
+           COALESCE(sum(km), 0) AS km,
+           array_agg(DISTINCT ocean_basin(lat, lng)) AS basins,
+           (array_agg(lat ORDER BY id DESC))[1] AS last_lat,
+           (array_agg(lng ORDER BY id DESC))[1] AS last_lng
+    FROM legs GROUP BY bottle_id
+), counts AS (
+    SELECT bottle_id,
+           count(*) FILTER (WHERE event_type = 're_released') AS re_releases,
+           count(*) FILTER (WHERE event_type = 'stamp') AS stamps
+    FROM moves GROUP BY bottle_id
+)
+INSERT INTO bottle_voyages (bottle_id, distance_km, re_releases, stamps, basins, last_lat, last_lng)
+SELECT c.bottle_id, COALESCE(t.km, 0), c.re_releases, c.stamps, COALESCE(t.basins, '{}'), t.last_lat, t.last_lng
+FROM counts c LEFT JOIN leg_totals t USING (bottle_id);
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TRIGGER bottle_voyages_events ON bottle_events;
+DROP FUNCTION bottle_voyages_events();
+DROP FUNCTION great_circle_km(DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION);
+DROP INDEX bottles_drifting_created_idx;
+DROP TABLE bottle_voyages;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Sampling index for GET /discovery/random: one row per visible drifting Bottle, keyed by a
+-- random number drawn when it joins the Ocean. Seeking from a fresh random key walks the
+-- index a few rows instead of scanning bottles. Kept by a statement-level trigger so the
+-- drift tick's bulk move costs one pass.
+CREATE TABLE bottle_samples (
+    bottle_id  INT PRIMARY KEY REFERENCES bottles(id) ON DELETE CASCADE,
+    sample_key DOUBLE PRECISION NOT NULL DEFAULT random(),
+    basin      TEXT NOT NULL
+);
+
+CREATE INDEX bottle_samples_key_idx ON bottle_samples (sample_key);
+CREATE INDEX bottle_samples_basin_key_idx ON bottle_samples (basin, sample_key);
+
+-- A Bottle is sampled while it is a visible Cork: drifting and past Mystery Delay. Its key
+-- survives moves so a Cork is not re-rolled every tick; only the basin follows it.
+CREATE OR REPLACE FUNCTION bottle_samples_sync() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM bottle_samples s
+    USING new_rows n
+    WHERE s.bottle_id = n.id
+      AND NOT (n.status = 'drifting' AND n.is_release IS TRUE AND n.current_lat IS NOT NULL AND n.current_lng IS NOT NULL);
+
+    INSERT INTO bottle_samples AS s (bottle_id, basin)
+    SELECT id, ocean_basin(current_lat, current_lng)
+    FROM new_rows
+    WHERE status = 'drifting' AND is_release IS TRUE AND current_lat IS NOT NULL AND current_lng IS NOT NULL
+    ON CONFLICT (bottle_id) DO UPDATE SET basin = EXCLUDED.basin
+    WHERE s.basin <> EXCLUDED.basin;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bottle_samples_insert
+    AFTER INSERT ON bottles REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bottle_samples_sync();
+
+CREATE TRIGGER bottle_samples_update
+    AFTER UPDATE ON bottles REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION bottle_samples_sync();
+
+INSERT INTO bottle_samples (bottle_id, basin)
+SELECT id, ocean_basin(current_lat, current_lng)
+FROM bottles
+WHERE status = 'drifting' AND is_release IS TRUE AND current_lat IS NOT NULL AND current_lng IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TRIGGER bottle_samples_update ON bottles;
+DROP TRIGGER bottle_samples_insert ON bottles;
+DROP FUNCTION bottle_samples_sync();
+DROP TABLE bottle_samples;
+-- +goose StatementEnd
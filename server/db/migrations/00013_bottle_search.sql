@@ -0,0 +1,43 @@
+-- +goose up
+
+-- +goose statementbegin
+-- Full-text index of Messages for GET /discovery/search. The Cast writes one row in the
+-- same transaction as the Bottle, stemmed with the config lang.Detect chose.
+CREATE TABLE bottle_search (
+    bottle_id INT PRIMARY KEY REFERENCES bottles(id) ON DELETE CASCADE,
+    language  TEXT NOT NULL,
+    config    REGCONFIG NOT NULL,
+    document  TSVECTOR NOT NULL
+);
+
+CREATE INDEX bottle_search_document_idx ON bottle_search USING GIN (document);
+
+-- A search query parsed under every config lang.Supported can store, ORed, so one GIN
+-- scan finds Messages whatever language they were stemmed in. Mirror of lang.Supported
+-- — keep the two in step.
+CREATE OR REPLACE FUNCTION message_search_query(q TEXT) RETURNS TSQUERY AS $$
+    SELECT websearch_to_tsquery('simple', q)
+        || websearch_to_tsquery('english', q)
+        || websearch_to_tsquery('spanish', q)
+        || websearch_to_tsquery('french', q)
+        || websearch_to_tsquery('german', q)
+        || websearch_to_tsquery('portuguese', q)
+        || websearch_to_tsquery('italian', q)
+        || websearch_to_tsquery('dutch', q)
+        || websearch_to_tsquery('swedish', q)
+        || websearch_to_tsquery('russian', q)
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Bottles cast before detection existed are indexed unstemmed; 'simple' still matches
+-- their exact words.
+INSERT INTO bottle_search (bottle_id, language, config, document)
+SELECT id, 'und', 'simple', to_tsvector('simple', message_text)
+FROM bottles;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP FUNCTION message_search_query(TEXT);
+DROP TABLE bottle_search;
+-- +goose StatementEnd
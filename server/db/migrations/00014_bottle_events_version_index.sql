@@ -0,0 +1,14 @@
+-- +goose NO TRANSACTION
+-- +goose up
+
+-- +goose statementbegin
+-- Conditional GETs version a Bottle by its newest event: one index probe per request.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS bottle_events_bottle_id_idx
+    ON bottle_events (bottle_id, id);
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP INDEX CONCURRENTLY IF EXISTS bottle_events_bottle_id_idx;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose up
+
+-- +goose statementbegin
+-- The Ocean generation behind map caching and conditional GETs. Services advance it
+-- with nextval once a write to bottles or bottle_events has committed, and readers take
+-- last_value before reading the Ocean, so a result is never filed under a newer
+-- generation than the state it saw. Event IDs cannot stand in for it: sequences hand
+-- them out before commit, so a drift batch holding low IDs can commit after a Stamp
+-- with a higher one and leave MAX(id) where it was. A sequence bumped after commit
+-- holds no lock a writer could wait on.
+CREATE SEQUENCE ocean_generation AS INTEGER CYCLE;
+
+-- Start past every event ID, which older instances served as ETags and cache keys.
+SELECT setval('ocean_generation', COALESCE(MAX(id), 0) + 1) FROM bottle_events;
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP SEQUENCE ocean_generation;
+-- +goose StatementEnd
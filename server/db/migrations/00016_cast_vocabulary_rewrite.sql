@@ -0,0 +1,62 @@
+-- +ocealis manual
+-- +goose up
+
+-- +goose statementbegin
+-- Rewrite step of the Cast / Mystery Delay rename begun in 00007. Instances from before
+-- the rename only read the old spellings, so apply this once none is left running:
+-- roll the fleet (MIGRATE_ON_START, or `ocealis migrate up 15`, stops short of this
+-- file), then run `ocealis migrate up`. The triggers translate whatever a straggler
+-- still writes.
+UPDATE bottles SET status = 'mystery_delay' WHERE status = 'scheduled';
+UPDATE bottle_events SET event_type = 'cast' WHERE event_type = 'released';
+
+CREATE OR REPLACE FUNCTION bottles_cast_vocabulary() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'scheduled' THEN
+        NEW.status := 'mystery_delay';
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        NEW.visible_at := COALESCE(NEW.visible_at, NEW.scheduled_release);
+    ELSIF NEW.visible_at IS NOT DISTINCT FROM OLD.visible_at THEN
+        NEW.visible_at := NEW.scheduled_release; -- old writer moved scheduled_release
+    END IF;
+    NEW.scheduled_release := NEW.visible_at;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION bottle_events_cast_vocabulary() RETURNS trigger AS $$
+BEGIN
+    IF NEW.event_type = 'released' THEN
+        NEW.event_type := 'cast';
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bottle_events_cast_vocabulary
+    BEFORE INSERT OR UPDATE ON bottle_events
+    FOR EACH ROW EXECUTE FUNCTION bottle_events_cast_vocabulary();
+-- +goose StatementEnd
+
+-- +goose down
+
+-- +goose statementbegin
+DROP TRIGGER bottle_events_cast_vocabulary ON bottle_events;
+DROP FUNCTION bottle_events_cast_vocabulary();
+
+CREATE OR REPLACE FUNCTION bottles_cast_vocabulary() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        NEW.visible_at := COALESCE(NEW.visible_at, NEW.scheduled_release);
+    ELSIF NEW.visible_at IS NOT DISTINCT FROM OLD.visible_at THEN
+        NEW.visible_at := NEW.scheduled_release; -- old writer moved scheduled_release
+    END IF;
+    NEW.scheduled_release := NEW.visible_at;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+UPDATE bottle_events SET event_type = 'released' WHERE event_type = 'cast';
+UPDATE bottles SET status = 'scheduled' WHERE status = 'mystery_delay';
+-- +goose StatementEnd
@@ -23,6 +23,7 @@ type Bottle struct {
 	ScheduledRelease pgtype.Timestamptz
 	IsRelease        pgtype.Bool
 	CreatedAt        pgtype.Timestamptz
+	VisibleAt        pgtype.Timestamptz
 }
 
 type BottleEvent struct {
@@ -32,6 +33,59 @@ type BottleEvent struct {
 	Lat       pgtype.Float8
 	Lng       pgtype.Float8
 	CreatedAt pgtype.Timestamptz
+	Payload   []byte
+}
+
+type BottleFingerprint struct {
+	BottleID    int32
+	Fingerprint int64
+	CreatedAt   pgtype.Timestamptz
+}
+
+type BottleSample struct {
+	BottleID  int32
+	SampleKey float64
+	Basin     string
+}
+
+type BottleSearch struct {
+	BottleID int32
+	Language string
+	Config   interface{}
+	Document interface{}
+}
+
+type BottleVoyage struct {
+	BottleID   int32
+	DistanceKm float64
+	ReReleases int32
+	Stamps     int32
+	Basins     []string
+	BasinCount pgtype.Int4
+	LastLat    pgtype.Float8
+	LastLng    pgtype.Float8
+}
+
+type IdempotencyKey struct {
+	Key          string
+	RequestHash  string
+	StatusCode   pgtype.Int4
+	ResponseBody []byte
+	CreatedAt    pgtype.Timestamptz
+	ExpiresAt    pgtype.Timestamptz
+}
+
+type OceanActivityHourly struct {
+	Hour  pgtype.Timestamptz
+	Kind  string
+	Shard int16
+	N     int64
+}
+
+type OceanCounter struct {
+	Name  string
+	Shard int16
+	Value float64
 }
 
 type User struct {
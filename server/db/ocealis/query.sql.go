@@ -11,22 +11,117 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const bumpOceanGeneration = `-- name: BumpOceanGeneration :exec
+SELECT nextval('ocean_generation')
+`
+
+// Runs after the write it announces has committed; nextval is not rolled back.
+func (q *Queries) BumpOceanGeneration(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, bumpOceanGeneration)
+	return err
+}
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (key, request_hash, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+SET request_hash  = EXCLUDED.request_hash,
+    status_code   = NULL,
+    response_body = NULL,
+    created_at    = NOW(),
+    expires_at    = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING key, request_hash, status_code, response_body, created_at, expires_at
+`
+
+type ClaimIdempotencyKeyParams struct {
+	Key         string
+	RequestHash string
+	ExpiresAt   pgtype.Timestamptz
+}
+
+// Inserts a fresh claim, or takes over a row whose 24h window already lapsed.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, claimIdempotencyKey, arg.Key, arg.RequestHash, arg.ExpiresAt)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const compactDriftEvents = `-- name: CompactDriftEvents :execrows
+WITH days AS (
+    SELECT bottle_id,
+           (created_at AT TIME ZONE 'UTC')::date AS day,
+           max(id) AS keep_id,
+           sum(COALESCE((payload->>'ticks')::int, 1)) AS ticks,
+           sum(COALESCE((payload->>'distance_km')::float8, 0)) AS distance_km
+    FROM bottle_events
+    WHERE event_type = 'drift' AND created_at < $1::timestamptz
+    GROUP BY bottle_id, day
+    HAVING count(*) > 1
+), waypoints AS (
+    UPDATE bottle_events e
+    SET payload = e.payload || jsonb_build_object('ticks', d.ticks, 'distance_km', d.distance_km)
+    FROM days d
+    WHERE e.id = d.keep_id
+)
+DELETE FROM bottle_events e
+USING days d
+WHERE e.bottle_id = d.bottle_id
+  AND e.event_type = 'drift'
+  AND e.created_at < $1::timestamptz
+  AND (e.created_at AT TIME ZONE 'UTC')::date = d.day
+  AND e.id <> d.keep_id
+`
+
+// Collapse drift events older than `before` into one waypoint per Bottle per UTC day:
+// the day's last position, carrying the day's summed distance and tick count.
+func (q *Queries) CompactDriftEvents(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, compactDriftEvents, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET status_code = $2, response_body = $3 WHERE key = $1
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Key          string
+	StatusCode   pgtype.Int4
+	ResponseBody []byte
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey, arg.Key, arg.StatusCode, arg.ResponseBody)
+	return err
+}
+
 const createBottle = `-- name: CreateBottle :one
-INSERT INTO bottles (sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, status, is_release, scheduled_release)
+INSERT INTO bottles (sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, status, is_release, visible_at)
 VALUES ($1, $2, $3, $4, $5, $6, $5, $6, $7, $8, $9)
-RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 `
 
 type CreateBottleParams struct {
-	SenderID         pgtype.Int4
-	Nickname         string
-	MessageText      string
-	BottleStyle      pgtype.Int4
-	StartLat         pgtype.Float8
-	StartLng         pgtype.Float8
-	Status           string
-	IsRelease        pgtype.Bool
-	ScheduledRelease pgtype.Timestamptz
+	SenderID    pgtype.Int4
+	Nickname    string
+	MessageText string
+	BottleStyle pgtype.Int4
+	StartLat    pgtype.Float8
+	StartLng    pgtype.Float8
+	Status      string
+	IsRelease   pgtype.Bool
+	VisibleAt   pgtype.Timestamptz
 }
 
 func (q *Queries) CreateBottle(ctx context.Context, arg CreateBottleParams) (Bottle, error) {
@@ -39,7 +134,7 @@ func (q *Queries) CreateBottle(ctx context.Context, arg CreateBottleParams) (Bot
 		arg.StartLng,
 		arg.Status,
 		arg.IsRelease,
-		arg.ScheduledRelease,
+		arg.VisibleAt,
 	)
 	var i Bottle
 	err := row.Scan(
@@ -57,14 +152,15 @@ func (q *Queries) CreateBottle(ctx context.Context, arg CreateBottleParams) (Bot
 		&i.ScheduledRelease,
 		&i.IsRelease,
 		&i.CreatedAt,
+		&i.VisibleAt,
 	)
 	return i, err
 }
 
 const createBottleEvent = `-- name: CreateBottleEvent :one
-INSERT INTO bottle_events (bottle_id, event_type, lat, lng)
-VALUES ($1, $2, $3, $4)
-RETURNING id, bottle_id, event_type, lat, lng, created_at
+INSERT INTO bottle_events (bottle_id, event_type, lat, lng, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, bottle_id, event_type, lat, lng, created_at, payload
 `
 
 type CreateBottleEventParams struct {
@@ -72,6 +168,7 @@ type CreateBottleEventParams struct {
 	EventType string
 	Lat       pgtype.Float8
 	Lng       pgtype.Float8
+	Payload   []byte
 }
 
 func (q *Queries) CreateBottleEvent(ctx context.Context, arg CreateBottleEventParams) (BottleEvent, error) {
@@ -80,6 +177,7 @@ func (q *Queries) CreateBottleEvent(ctx context.Context, arg CreateBottleEventPa
 		arg.EventType,
 		arg.Lat,
 		arg.Lng,
+		arg.Payload,
 	)
 	var i BottleEvent
 	err := row.Scan(
@@ -89,10 +187,58 @@ func (q *Queries) CreateBottleEvent(ctx context.Context, arg CreateBottleEventPa
 		&i.Lat,
 		&i.Lng,
 		&i.CreatedAt,
+		&i.Payload,
 	)
 	return i, err
 }
 
+type CreateDriftEventsParams struct {
+	BottleID  pgtype.Int4
+	EventType string
+	Lat       pgtype.Float8
+	Lng       pgtype.Float8
+	Payload   []byte
+}
+
+const createBottleFingerprint = `-- name: CreateBottleFingerprint :exec
+INSERT INTO bottle_fingerprints (bottle_id, fingerprint)
+VALUES ($1, $2)
+`
+
+type CreateBottleFingerprintParams struct {
+	BottleID    int32
+	Fingerprint int64
+}
+
+func (q *Queries) CreateBottleFingerprint(ctx context.Context, arg CreateBottleFingerprintParams) error {
+	_, err := q.db.Exec(ctx, createBottleFingerprint, arg.BottleID, arg.Fingerprint)
+	return err
+}
+
+const createBottleSearch = `-- name: CreateBottleSearch :exec
+INSERT INTO bottle_search (bottle_id, language, config, document)
+VALUES ($1, $2, $3::regconfig,
+        to_tsvector($3::regconfig, $4::text))
+`
+
+type CreateBottleSearchParams struct {
+	BottleID    int32
+	Language    string
+	Config      string
+	MessageText string
+}
+
+// Indexes a Cast's Message under the text search config its language stems with.
+func (q *Queries) CreateBottleSearch(ctx context.Context, arg CreateBottleSearchParams) error {
+	_, err := q.db.Exec(ctx, createBottleSearch,
+		arg.BottleID,
+		arg.Language,
+		arg.Config,
+		arg.MessageText,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (nickname, avatar_url) VALUES ($1, $2)
 RETURNING id, nickname, avatar_url, created_at
@@ -115,8 +261,41 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE key = $1
+`
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, key)
+	return err
+}
+
+const deleteOceanActivityBefore = `-- name: DeleteOceanActivityBefore :execrows
+DELETE FROM ocean_activity_hourly WHERE hour < $1::timestamptz
+`
+
+func (q *Queries) DeleteOceanActivityBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOceanActivityBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getBottle = `-- name: GetBottle :one
-SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 FROM bottles WHERE id = $1
 `
 
@@ -138,12 +317,13 @@ func (q *Queries) GetBottle(ctx context.Context, id int32) (Bottle, error) {
 		&i.ScheduledRelease,
 		&i.IsRelease,
 		&i.CreatedAt,
+		&i.VisibleAt,
 	)
 	return i, err
 }
 
 const getBottleEvents = `-- name: GetBottleEvents :many
-SELECT id, bottle_id, event_type, lat, lng, created_at
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
 FROM bottle_events WHERE bottle_id = $1 ORDER BY created_at ASC, id ASC
 `
 
@@ -163,6 +343,7 @@ func (q *Queries) GetBottleEvents(ctx context.Context, bottleID pgtype.Int4) ([]
 			&i.Lat,
 			&i.Lng,
 			&i.CreatedAt,
+			&i.Payload,
 		); err != nil {
 			return nil, err
 		}
@@ -175,7 +356,7 @@ func (q *Queries) GetBottleEvents(ctx context.Context, bottleID pgtype.Int4) ([]
 }
 
 const getBottleEventsPaginated = `-- name: GetBottleEventsPaginated :many
-SELECT id, bottle_id, event_type, lat, lng, created_at
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
 FROM bottle_events
 WHERE bottle_id = $1
   AND ($2::int IS NULL OR id < $2::int)
@@ -204,6 +385,73 @@ func (q *Queries) GetBottleEventsPaginated(ctx context.Context, arg GetBottleEve
 			&i.Lat,
 			&i.Lng,
 			&i.CreatedAt,
+			&i.Payload,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, request_hash, status_code, response_body, created_at, expires_at
+FROM idempotency_keys WHERE key = $1
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getJourneyHighlights = `-- name: GetJourneyHighlights :many
+WITH drift AS (
+    SELECT id, ntile($1::int) OVER (ORDER BY created_at, id) AS slice
+    FROM bottle_events
+    WHERE bottle_id = $2 AND event_type = 'drift'
+)
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
+FROM bottle_events
+WHERE bottle_id = $2
+  AND (event_type <> 'drift' OR id IN (SELECT max(id) FROM drift GROUP BY slice))
+ORDER BY created_at ASC, id ASC
+`
+
+type GetJourneyHighlightsParams struct {
+	Samples  int32
+	BottleID pgtype.Int4
+}
+
+// Every non-drift event plus the last drift event of each of `samples` equal slices of the drift track.
+func (q *Queries) GetJourneyHighlights(ctx context.Context, arg GetJourneyHighlightsParams) ([]BottleEvent, error) {
+	rows, err := q.db.Query(ctx, getJourneyHighlights, arg.Samples, arg.BottleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BottleEvent
+	for rows.Next() {
+		var i BottleEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.BottleID,
+			&i.EventType,
+			&i.Lat,
+			&i.Lng,
+			&i.CreatedAt,
+			&i.Payload,
 		); err != nil {
 			return nil, err
 		}
@@ -218,7 +466,7 @@ func (q *Queries) GetBottleEventsPaginated(ctx context.Context, arg GetBottleEve
 const getNearbyBottles = `-- name: GetNearbyBottles :many
 SELECT id, sender_id, nickname, message_text, bottle_style,
        start_lat, start_lng, current_lat, current_lng,
-       hops, status, scheduled_release, is_release, created_at
+       hops, status, scheduled_release, is_release, created_at, visible_at
 FROM bottles
 WHERE status = 'drifting'
   AND is_release = TRUE
@@ -267,6 +515,7 @@ func (q *Queries) GetNearbyBottles(ctx context.Context, arg GetNearbyBottlesPara
 			&i.ScheduledRelease,
 			&i.IsRelease,
 			&i.CreatedAt,
+			&i.VisibleAt,
 		); err != nil {
 			return nil, err
 		}
@@ -278,6 +527,17 @@ func (q *Queries) GetNearbyBottles(ctx context.Context, arg GetNearbyBottlesPara
 	return items, nil
 }
 
+const getOceanGeneration = `-- name: GetOceanGeneration :one
+SELECT last_value::int AS generation FROM ocean_generation
+`
+
+func (q *Queries) GetOceanGeneration(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, getOceanGeneration)
+	var generation int32
+	err := row.Scan(&generation)
+	return generation, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, nickname, avatar_url, created_at FROM users WHERE id = $1
 `
@@ -295,7 +555,7 @@ func (q *Queries) GetUser(ctx context.Context, id int32) (User, error) {
 }
 
 const listActiveDriftingBottles = `-- name: ListActiveDriftingBottles :many
-SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 FROM bottles
 WHERE status = 'drifting' AND is_release = TRUE
 `
@@ -324,6 +584,318 @@ func (q *Queries) ListActiveDriftingBottles(ctx context.Context) ([]Bottle, erro
 			&i.ScheduledRelease,
 			&i.IsRelease,
 			&i.CreatedAt,
+			&i.VisibleAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotableBottles = `-- name: ListNotableBottles :many
+(SELECT 'longest_voyages'::text AS ranking, b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.distance_km > 0
+ ORDER BY v.distance_km DESC, b.id LIMIT $1::int)
+UNION ALL
+(SELECT 'most_re_released', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.re_releases > 0
+ ORDER BY v.re_releases DESC, b.id LIMIT $1::int)
+UNION ALL
+(SELECT 'most_stamped', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.stamps > 0
+ ORDER BY v.stamps DESC, b.id LIMIT $1::int)
+UNION ALL
+(SELECT 'most_basins', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.basin_count > 0
+ ORDER BY v.basin_count DESC, v.distance_km DESC, b.id LIMIT $1::int)
+UNION ALL
+(SELECT 'oldest', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        COALESCE(v.distance_km, 0), COALESCE(v.re_releases, 0), COALESCE(v.stamps, 0), COALESCE(v.basins, '{}')
+ FROM bottles b LEFT JOIN bottle_voyages v ON v.bottle_id = b.id
+ WHERE b.status = 'drifting'
+ ORDER BY b.created_at, b.id LIMIT $1::int)
+`
+
+type ListNotableBottlesRow struct {
+	Ranking     string
+	ID          int32
+	BottleStyle pgtype.Int4
+	CurrentLat  pgtype.Float8
+	CurrentLng  pgtype.Float8
+	CreatedAt   pgtype.Timestamptz
+	DistanceKm  float64
+	ReReleases  int32
+	Stamps      int32
+	Basins      []string
+}
+
+// The top `limit` drifting Bottles in each ranking, read from the trigger-kept
+// bottle_voyages totals (00011). Sunk and Mystery Delay Bottles are left out.
+func (q *Queries) ListNotableBottles(ctx context.Context, lim int32) ([]ListNotableBottlesRow, error) {
+	rows, err := q.db.Query(ctx, listNotableBottles, lim)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNotableBottlesRow
+	for rows.Next() {
+		var i ListNotableBottlesRow
+		if err := rows.Scan(
+			&i.Ranking,
+			&i.ID,
+			&i.BottleStyle,
+			&i.CurrentLat,
+			&i.CurrentLng,
+			&i.CreatedAt,
+			&i.DistanceKm,
+			&i.ReReleases,
+			&i.Stamps,
+			&i.Basins,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOceanCounters = `-- name: ListOceanCounters :many
+SELECT name, sum(value)::float8 AS value FROM ocean_counters GROUP BY name
+`
+
+type ListOceanCountersRow struct {
+	Name  string
+	Value float64
+}
+
+// Each counter summed across its shards.
+func (q *Queries) ListOceanCounters(ctx context.Context) ([]ListOceanCountersRow, error) {
+	rows, err := q.db.Query(ctx, listOceanCounters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOceanCountersRow
+	for rows.Next() {
+		var i ListOceanCountersRow
+		if err := rows.Scan(&i.Name, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentFingerprints = `-- name: ListRecentFingerprints :many
+SELECT fingerprint
+FROM bottle_fingerprints
+WHERE created_at >= $1::timestamptz
+`
+
+func (q *Queries) ListRecentFingerprints(ctx context.Context, since pgtype.Timestamptz) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listRecentFingerprints, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var fingerprint int64
+		if err := rows.Scan(&fingerprint); err != nil {
+			return nil, err
+		}
+		items = append(items, fingerprint)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReplayEvents = `-- name: ListReplayEvents :many
+WITH seen AS (
+    SELECT DISTINCT e.bottle_id
+    FROM bottle_events e
+    WHERE e.created_at BETWEEN $1::timestamptz AND $2::timestamptz
+      AND e.event_type IN ('cast', 'released', 'drift', 're_released')
+      AND e.lat BETWEEN $3::float8 AND $4::float8
+      AND e.lng BETWEEN $5::float8 AND $6::float8
+    LIMIT $7::int
+), track AS (
+    SELECT e.id, e.bottle_id, e.event_type, e.lat, e.lng, e.created_at
+    FROM bottle_events e
+    JOIN seen s ON s.bottle_id = e.bottle_id
+    WHERE e.created_at BETWEEN $1::timestamptz AND $2::timestamptz
+      AND e.event_type IN ('cast', 'released', 'drift', 're_released', 'sink', 'discovered')
+    UNION ALL
+    SELECT id, bottle_id, event_type, lat, lng, created_at FROM (
+        SELECT DISTINCT ON (e.bottle_id) e.id, e.bottle_id, e.event_type, e.lat, e.lng, e.created_at
+        FROM bottle_events e
+        JOIN seen s ON s.bottle_id = e.bottle_id
+        WHERE e.created_at < $1::timestamptz
+          AND e.event_type IN ('cast', 'released', 'drift', 're_released')
+        ORDER BY e.bottle_id, e.created_at DESC, e.id DESC
+    ) before
+)
+SELECT t.id, t.bottle_id, t.event_type, t.lat, t.lng, t.created_at, b.bottle_style, b.visible_at
+FROM track t
+JOIN bottles b ON b.id = t.bottle_id
+ORDER BY t.bottle_id, t.created_at, t.id
+`
+
+type ListReplayEventsParams struct {
+	FromAt     pgtype.Timestamptz
+	ToAt       pgtype.Timestamptz
+	MinLat     float64
+	MaxLat     float64
+	MinLng     float64
+	MaxLng     float64
+	MaxBottles int32
+}
+
+type ListReplayEventsRow struct {
+	ID          int32
+	BottleID    pgtype.Int4
+	EventType   string
+	Lat         pgtype.Float8
+	Lng         pgtype.Float8
+	CreatedAt   pgtype.Timestamptz
+	BottleStyle pgtype.Int4
+	VisibleAt   pgtype.Timestamptz
+}
+
+// Position events of every Bottle seen inside the bbox during [from_at, to_at], plus each
+// one's last position before from_at so the first frames have somewhere to start from.
+func (q *Queries) ListReplayEvents(ctx context.Context, arg ListReplayEventsParams) ([]ListReplayEventsRow, error) {
+	rows, err := q.db.Query(ctx, listReplayEvents,
+		arg.FromAt,
+		arg.ToAt,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.MaxBottles,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReplayEventsRow
+	for rows.Next() {
+		var i ListReplayEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BottleID,
+			&i.EventType,
+			&i.Lat,
+			&i.Lng,
+			&i.CreatedAt,
+			&i.BottleStyle,
+			&i.VisibleAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSampleCandidates = `-- name: ListSampleCandidates :many
+WITH ahead AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE sample_key >= $1::float8
+    ORDER BY sample_key LIMIT $2::int
+), wrapped AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE sample_key < $1::float8
+    ORDER BY sample_key LIMIT $2::int - (SELECT count(*) FROM ahead)
+), basin_ahead AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE basin = $3::text AND sample_key >= $1::float8
+    ORDER BY sample_key LIMIT $2::int
+), basin_wrapped AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE basin = $3::text AND sample_key < $1::float8
+    ORDER BY sample_key LIMIT $2::int - (SELECT count(*) FROM basin_ahead)
+), picked AS (
+    SELECT bottle_id, basin FROM ahead UNION SELECT bottle_id, basin FROM wrapped
+    UNION SELECT bottle_id, basin FROM basin_ahead UNION SELECT bottle_id, basin FROM basin_wrapped
+)
+SELECT b.id, b.sender_id, b.nickname, b.message_text, b.bottle_style, b.start_lat, b.start_lng, b.current_lat, b.current_lng, b.hops, b.status, b.scheduled_release, b.is_release, b.created_at, b.visible_at, p.basin,
+       COALESCE(v.re_releases, 0)::int AS re_releases,
+       COALESCE(v.stamps, 0)::int AS stamps
+FROM picked p
+JOIN bottles b ON b.id = p.bottle_id
+LEFT JOIN bottle_voyages v ON v.bottle_id = b.id
+WHERE b.status = 'drifting' AND b.is_release = TRUE
+ORDER BY b.id
+`
+
+type ListSampleCandidatesParams struct {
+	FromKey float64
+	Lim     int32
+	Basin   string
+}
+
+type ListSampleCandidatesRow struct {
+	Bottle     Bottle
+	Basin      string
+	ReReleases int32
+	Stamps     int32
+}
+
+// Up to `lim` visible drifting Bottles from the sampling index at or after `from_key`,
+// wrapping to the start when the tail runs short, plus as many again from `basin`
+// (empty for none). Each carries its Journey totals so the caller can weight the pick.
+func (q *Queries) ListSampleCandidates(ctx context.Context, arg ListSampleCandidatesParams) ([]ListSampleCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, listSampleCandidates, arg.FromKey, arg.Lim, arg.Basin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSampleCandidatesRow
+	for rows.Next() {
+		var i ListSampleCandidatesRow
+		if err := rows.Scan(
+			&i.Bottle.ID,
+			&i.Bottle.SenderID,
+			&i.Bottle.Nickname,
+			&i.Bottle.MessageText,
+			&i.Bottle.BottleStyle,
+			&i.Bottle.StartLat,
+			&i.Bottle.StartLng,
+			&i.Bottle.CurrentLat,
+			&i.Bottle.CurrentLng,
+			&i.Bottle.Hops,
+			&i.Bottle.Status,
+			&i.Bottle.ScheduledRelease,
+			&i.Bottle.IsRelease,
+			&i.Bottle.CreatedAt,
+			&i.Bottle.VisibleAt,
+			&i.Basin,
+			&i.ReReleases,
+			&i.Stamps,
 		); err != nil {
 			return nil, err
 		}
@@ -337,11 +909,11 @@ func (q *Queries) ListActiveDriftingBottles(ctx context.Context) ([]Bottle, erro
 
 const listScheduledBottles = `-- name: ListScheduledBottles :many
 SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng,
-       current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+       current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 FROM bottles
 WHERE is_release = FALSE
-  AND status = 'scheduled'
-  AND scheduled_release <= NOW()
+  AND status IN ('mystery_delay', 'scheduled')
+  AND visible_at <= NOW()
 `
 
 func (q *Queries) ListScheduledBottles(ctx context.Context) ([]Bottle, error) {
@@ -368,6 +940,7 @@ func (q *Queries) ListScheduledBottles(ctx context.Context) ([]Bottle, error) {
 			&i.ScheduledRelease,
 			&i.IsRelease,
 			&i.CreatedAt,
+			&i.VisibleAt,
 		); err != nil {
 			return nil, err
 		}
@@ -379,6 +952,156 @@ func (q *Queries) ListScheduledBottles(ctx context.Context) ([]Bottle, error) {
 	return items, nil
 }
 
+const moveDriftingBottles = `-- name: MoveDriftingBottles :many
+UPDATE bottles AS b
+SET current_lat = d.lat,
+    current_lng = d.lng
+FROM unnest($1::int[], $2::float8[], $3::float8[]) AS d(id, lat, lng)
+WHERE b.id = d.id AND b.status = 'drifting'
+RETURNING b.id
+`
+
+type MoveDriftingBottlesParams struct {
+	Ids  []int32
+	Lats []float64
+	Lngs []float64
+}
+
+// Drift is not a hop: only Re-release bumps hops. Returns the Bottles still drifting
+// once their row locks were taken, the only ones that moved.
+func (q *Queries) MoveDriftingBottles(ctx context.Context, arg MoveDriftingBottlesParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, moveDriftingBottles, arg.Ids, arg.Lats, arg.Lngs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchBottles = `-- name: SearchBottles :many
+SELECT b.id, b.sender_id, b.nickname, b.message_text, b.bottle_style, b.start_lat, b.start_lng, b.current_lat, b.current_lng, b.hops, b.status, b.scheduled_release, b.is_release, b.created_at, b.visible_at, ts_rank(s.document, message_search_query($1::text))::float4 AS rank
+FROM bottle_search s
+JOIN bottles b ON b.id = s.bottle_id
+WHERE s.document @@ message_search_query($1::text)
+  AND b.status = 'drifting'
+  AND b.is_release = TRUE
+  AND b.current_lat BETWEEN $2::float8 AND $3::float8
+  AND b.current_lng BETWEEN $4::float8 AND $5::float8
+  AND ($6::int IS NULL
+       OR (ts_rank(s.document, message_search_query($1::text))::float4, b.id)
+          < ($7::float4, $6::int))
+ORDER BY rank DESC, b.id DESC
+LIMIT $8::int
+`
+
+type SearchBottlesParams struct {
+	Q          string
+	MinLat     float64
+	MaxLat     float64
+	MinLng     float64
+	MaxLng     float64
+	CursorID   pgtype.Int4
+	CursorRank pgtype.Float4
+	Lim        int32
+}
+
+type SearchBottlesRow struct {
+	Bottle Bottle
+	Rank   float32
+}
+
+// Visible drifting Bottles whose Message matches q inside the bbox, best match first,
+// keyset-paged on (rank, id) after the cursor.
+func (q *Queries) SearchBottles(ctx context.Context, arg SearchBottlesParams) ([]SearchBottlesRow, error) {
+	rows, err := q.db.Query(ctx, searchBottles,
+		arg.Q,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLng,
+		arg.MaxLng,
+		arg.CursorID,
+		arg.CursorRank,
+		arg.Lim,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchBottlesRow
+	for rows.Next() {
+		var i SearchBottlesRow
+		if err := rows.Scan(
+			&i.Bottle.ID,
+			&i.Bottle.SenderID,
+			&i.Bottle.Nickname,
+			&i.Bottle.MessageText,
+			&i.Bottle.BottleStyle,
+			&i.Bottle.StartLat,
+			&i.Bottle.StartLng,
+			&i.Bottle.CurrentLat,
+			&i.Bottle.CurrentLng,
+			&i.Bottle.Hops,
+			&i.Bottle.Status,
+			&i.Bottle.ScheduledRelease,
+			&i.Bottle.IsRelease,
+			&i.Bottle.CreatedAt,
+			&i.Bottle.VisibleAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumOceanActivity = `-- name: SumOceanActivity :many
+SELECT kind, sum(n)::bigint AS n
+FROM ocean_activity_hourly
+WHERE hour >= $1::timestamptz
+GROUP BY kind
+`
+
+type SumOceanActivityRow struct {
+	Kind string
+	N    int64
+}
+
+// Casts and Stamps per kind across the hourly buckets starting at or after `since`.
+func (q *Queries) SumOceanActivity(ctx context.Context, since pgtype.Timestamptz) ([]SumOceanActivityRow, error) {
+	rows, err := q.db.Query(ctx, sumOceanActivity, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumOceanActivityRow
+	for rows.Next() {
+		var i SumOceanActivityRow
+		if err := rows.Scan(&i.Kind, &i.N); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateBottlePosition = `-- name: UpdateBottlePosition :one
 UPDATE bottles
 SET current_lat = $2,
@@ -387,7 +1110,7 @@ SET current_lat = $2,
     status = $4,
     is_release = CASE WHEN $4 = 'drifting' THEN TRUE ELSE is_release END
 WHERE id = $1
-RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 `
 
 type UpdateBottlePositionParams struct {
@@ -420,13 +1143,14 @@ func (q *Queries) UpdateBottlePosition(ctx context.Context, arg UpdateBottlePosi
 		&i.ScheduledRelease,
 		&i.IsRelease,
 		&i.CreatedAt,
+		&i.VisibleAt,
 	)
 	return i, err
 }
 
 const updateBottleStatus = `-- name: UpdateBottleStatus :one
 UPDATE bottles SET status = $2 WHERE id = $1
-RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
 `
 
 type UpdateBottleStatusParams struct {
@@ -452,6 +1176,7 @@ func (q *Queries) UpdateBottleStatus(ctx context.Context, arg UpdateBottleStatus
 		&i.ScheduledRelease,
 		&i.IsRelease,
 		&i.CreatedAt,
+		&i.VisibleAt,
 	)
 	return i, err
 }
@@ -0,0 +1,152 @@
+package db_test
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/db"
+	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// migratedTx opens a transaction on a throwaway database brought up to the latest
+// migration, rolled back when t ends. It needs
+// OCEALIS_TEST_DATABASE_URL=postgres://... and skips without it.
+func migratedTx(t *testing.T) pgx.Tx {
+	t.Helper()
+	url := os.Getenv("OCEALIS_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("OCEALIS_TEST_DATABASE_URL not set")
+	}
+	ctx := t.Context()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := db.NewMigrator(pool, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback(ctx) })
+	return tx
+}
+
+func TestDriftCompactionKeepsKmDrifted(t *testing.T) {
+	tx := migratedTx(t)
+	ctx := t.Context()
+
+	var id int32
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO bottles (nickname, message_text, start_lat, start_lng, current_lat, current_lng, status, is_release)
+		VALUES ('compact', 'km drifted', 30, -40, 30, -40, 'drifting', TRUE) RETURNING id`).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Now().UTC().AddDate(0, 0, -10).Truncate(24 * time.Hour).Add(time.Hour)
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO bottle_events (bottle_id, event_type, lat, lng, created_at, payload)
+		SELECT $1, 'drift', 30, -40, $2::timestamptz + g * INTERVAL '15 minutes',
+		       jsonb_build_object('bearing_deg', 45, 'speed_km_h', 2, 'distance_km', 0.5)
+		FROM generate_series(0, 7) g`, id, day); err != nil {
+		t.Fatal(err)
+	}
+
+	km := func() float64 {
+		t.Helper()
+		var v float64
+		if err := tx.QueryRow(ctx, `SELECT sum(value) FROM ocean_counters WHERE name = 'km_drifted'`).Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	before := km()
+
+	removed, err := ocealis.New(tx).CompactDriftEvents(ctx, pgtype.Timestamptz{Time: day.AddDate(0, 0, 1), Valid: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed < 7 {
+		t.Fatalf("compaction removed %d events, want the day's 7 non-waypoints at least", removed)
+	}
+	if after := km(); math.Abs(after-before) > 1e-6 {
+		t.Fatalf("km_drifted %v before compaction, %v after", before, after)
+	}
+
+	var waypointKm float64
+	if err := tx.QueryRow(ctx, `
+		SELECT (payload->>'distance_km')::float8 FROM bottle_events
+		WHERE bottle_id = $1 AND event_type = 'drift'`, id).Scan(&waypointKm); err != nil {
+		t.Fatal(err)
+	}
+	if waypointKm != 4 {
+		t.Fatalf("waypoint carries %v km, want the day's 4", waypointKm)
+	}
+}
+
+func TestOceanCountersSumAcrossShards(t *testing.T) {
+	tx := migratedTx(t)
+	ctx := t.Context()
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ocean_counters (name, shard, value) VALUES ('test:sharded', 1, 2), ('test:sharded', 9, 3)`); err != nil {
+		t.Fatal(err)
+	}
+	counters, err := ocealis.New(tx).ListOceanCounters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range counters {
+		if c.Name == "test:sharded" {
+			if c.Value != 5 {
+				t.Fatalf("test:sharded reads %v, want both shards' 5", c.Value)
+			}
+			return
+		}
+	}
+	t.Fatal("sharded counter not listed")
+}
+
+func TestOceanGenerationMovesOnlyWhenBumped(t *testing.T) {
+	tx := migratedTx(t)
+	ctx := t.Context()
+	q := ocealis.New(tx)
+
+	generation := func() int32 {
+		t.Helper()
+		gen, err := q.GetOceanGeneration(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gen
+	}
+	gen := generation()
+
+	// Writes take no generation lock; the service bumps once they have committed.
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO bottles (nickname, message_text, start_lat, start_lng, current_lat, current_lng, status, is_release)
+		VALUES ('gen', 'generation', 10, 10, 10, 10, 'drifting', TRUE)`); err != nil {
+		t.Fatal(err)
+	}
+	if got := generation(); got != gen {
+		t.Fatalf("generation %d moved from %d before any bump", got, gen)
+	}
+	if err := q.BumpOceanGeneration(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := generation(); got != gen+1 {
+		t.Fatalf("generation %d after one bump from %d", got, gen)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -50,7 +51,10 @@ func Connect(log *zap.Logger) error {
 // WithTransaction executes the provided function within a database transaction.
 // If the function returns an error, the transaction is rolled back;
 // otherwise, it is committed.
-func WithTransaction(ctx context.Context, pool *pgxpool.Pool, fn func(q *ocealis.Queries) error) error {
+func WithTransaction(ctx context.Context, pool *pgxpool.Pool, fn func(q *ocealis.Queries) error) (err error) {
+	ctx, span := telemetry.Start(ctx, "db.WithTransaction")
+	defer func() { telemetry.End(span, err) }()
+
 	tx, err := pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction:%w", err)
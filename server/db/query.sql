@@ -0,0 +1,305 @@
+-- name: CreateBottle :one
+INSERT INTO bottles (sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, status, is_release, visible_at)
+VALUES ($1, $2, $3, $4, $5, $6, $5, $6, $7, $8, $9)
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at;
+
+-- name: GetBottle :one
+SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
+FROM bottles WHERE id = $1;
+
+-- name: UpdateBottleStatus :one
+UPDATE bottles SET status = $2 WHERE id = $1
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at;
+
+-- name: UpdateBottlePosition :one
+UPDATE bottles
+SET current_lat = $2,
+    current_lng = $3,
+    hops = hops + 1,
+    status = $4,
+    is_release = CASE WHEN $4 = 'drifting' THEN TRUE ELSE is_release END
+WHERE id = $1
+RETURNING id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at;
+
+-- name: MoveDriftingBottles :many
+-- Drift is not a hop: only Re-release bumps hops. Returns the Bottles still drifting
+-- once their row locks were taken, the only ones that moved.
+UPDATE bottles AS b
+SET current_lat = d.lat,
+    current_lng = d.lng
+FROM unnest(sqlc.arg(ids)::int[], sqlc.arg(lats)::float8[], sqlc.arg(lngs)::float8[]) AS d(id, lat, lng)
+WHERE b.id = d.id AND b.status = 'drifting'
+RETURNING b.id;
+
+-- name: ListActiveDriftingBottles :many
+SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng, current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
+FROM bottles
+WHERE status = 'drifting' AND is_release = TRUE;
+
+-- name: ListScheduledBottles :many
+SELECT id, sender_id, nickname, message_text, bottle_style, start_lat, start_lng,
+       current_lat, current_lng, hops, status, scheduled_release, is_release, created_at, visible_at
+FROM bottles
+WHERE is_release = FALSE
+  AND status IN ('mystery_delay', 'scheduled')
+  AND visible_at <= NOW();
+
+-- name: GetNearbyBottles :many
+SELECT id, sender_id, nickname, message_text, bottle_style,
+       start_lat, start_lng, current_lat, current_lng,
+       hops, status, scheduled_release, is_release, created_at, visible_at
+FROM bottles
+WHERE status = 'drifting'
+  AND is_release = TRUE
+  AND current_lat BETWEEN sqlc.arg(lat)::float8 - sqlc.arg(radius_deg)::float8
+                      AND sqlc.arg(lat)::float8 + sqlc.arg(radius_deg)::float8
+  AND current_lng BETWEEN sqlc.arg(lng)::float8 - sqlc.arg(radius_deg)::float8
+                      AND sqlc.arg(lng)::float8 + sqlc.arg(radius_deg)::float8
+  AND (sqlc.narg(cursor_id)::int IS NULL OR id < sqlc.narg(cursor_id)::int)
+ORDER BY id DESC
+LIMIT 5;
+
+-- name: CreateBottleFingerprint :exec
+INSERT INTO bottle_fingerprints (bottle_id, fingerprint)
+VALUES ($1, $2);
+
+-- name: CreateBottleSearch :exec
+-- Indexes a Cast's Message under the text search config its language stems with.
+INSERT INTO bottle_search (bottle_id, language, config, document)
+VALUES (sqlc.arg(bottle_id), sqlc.arg(language), sqlc.arg(config)::regconfig,
+        to_tsvector(sqlc.arg(config)::regconfig, sqlc.arg(message_text)::text));
+
+-- name: SearchBottles :many
+-- Visible drifting Bottles whose Message matches q inside the bbox, best match first,
+-- keyset-paged on (rank, id) after the cursor.
+SELECT sqlc.embed(b), ts_rank(s.document, message_search_query(sqlc.arg(q)::text))::float4 AS rank
+FROM bottle_search s
+JOIN bottles b ON b.id = s.bottle_id
+WHERE s.document @@ message_search_query(sqlc.arg(q)::text)
+  AND b.status = 'drifting'
+  AND b.is_release = TRUE
+  AND b.current_lat BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+  AND b.current_lng BETWEEN sqlc.arg(min_lng)::float8 AND sqlc.arg(max_lng)::float8
+  AND (sqlc.narg(cursor_id)::int IS NULL
+       OR (ts_rank(s.document, message_search_query(sqlc.arg(q)::text))::float4, b.id)
+          < (sqlc.narg(cursor_rank)::float4, sqlc.narg(cursor_id)::int))
+ORDER BY rank DESC, b.id DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: ListRecentFingerprints :many
+SELECT fingerprint
+FROM bottle_fingerprints
+WHERE created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: CreateBottleEvent :one
+INSERT INTO bottle_events (bottle_id, event_type, lat, lng, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, bottle_id, event_type, lat, lng, created_at, payload;
+
+-- name: CreateDriftEvents :copyfrom
+INSERT INTO bottle_events (bottle_id, event_type, lat, lng, payload)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetBottleEvents :many
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
+FROM bottle_events WHERE bottle_id = $1 ORDER BY created_at ASC, id ASC;
+
+-- name: GetBottleEventsPaginated :many
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
+FROM bottle_events
+WHERE bottle_id = $1
+  AND (sqlc.narg(cursor_id)::int IS NULL OR id < sqlc.narg(cursor_id)::int)
+ORDER BY id DESC
+LIMIT 3;
+
+-- name: GetOceanGeneration :one
+SELECT last_value::int AS generation FROM ocean_generation;
+
+-- name: BumpOceanGeneration :exec
+-- Runs after the write it announces has committed; nextval is not rolled back.
+SELECT nextval('ocean_generation');
+
+-- name: CreateUser :one
+INSERT INTO users (nickname, avatar_url) VALUES ($1, $2)
+RETURNING id, nickname, avatar_url, created_at;
+
+-- name: GetUser :one
+SELECT id, nickname, avatar_url, created_at FROM users WHERE id = $1;
+
+-- name: ClaimIdempotencyKey :one
+-- Inserts a fresh claim, or takes over a row whose 24h window already lapsed.
+INSERT INTO idempotency_keys (key, request_hash, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+SET request_hash  = EXCLUDED.request_hash,
+    status_code   = NULL,
+    response_body = NULL,
+    created_at    = NOW(),
+    expires_at    = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING key, request_hash, status_code, response_body, created_at, expires_at;
+
+-- name: GetIdempotencyKey :one
+SELECT key, request_hash, status_code, response_body, created_at, expires_at
+FROM idempotency_keys WHERE key = $1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys SET status_code = $2, response_body = $3 WHERE key = $1;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE key = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= NOW();
+
+-- name: GetJourneyHighlights :many
+-- Every non-drift event plus the last drift event of each of `samples` equal slices of the drift track.
+WITH drift AS (
+    SELECT id, ntile(sqlc.arg(samples)::int) OVER (ORDER BY created_at, id) AS slice
+    FROM bottle_events
+    WHERE bottle_id = sqlc.arg(bottle_id) AND event_type = 'drift'
+)
+SELECT id, bottle_id, event_type, lat, lng, created_at, payload
+FROM bottle_events
+WHERE bottle_id = sqlc.arg(bottle_id)
+  AND (event_type <> 'drift' OR id IN (SELECT max(id) FROM drift GROUP BY slice))
+ORDER BY created_at ASC, id ASC;
+
+-- name: CompactDriftEvents :execrows
+-- Collapse drift events older than `before` into one waypoint per Bottle per UTC day:
+-- the day's last position, carrying the day's summed distance and tick count.
+WITH days AS (
+    SELECT bottle_id,
+           (created_at AT TIME ZONE 'UTC')::date AS day,
+           max(id) AS keep_id,
+           sum(COALESCE((payload->>'ticks')::int, 1)) AS ticks,
+           sum(COALESCE((payload->>'distance_km')::float8, 0)) AS distance_km
+    FROM bottle_events
+    WHERE event_type = 'drift' AND created_at < sqlc.arg(before)::timestamptz
+    GROUP BY bottle_id, day
+    HAVING count(*) > 1
+), waypoints AS (
+    UPDATE bottle_events e
+    SET payload = e.payload || jsonb_build_object('ticks', d.ticks, 'distance_km', d.distance_km)
+    FROM days d
+    WHERE e.id = d.keep_id
+)
+DELETE FROM bottle_events e
+USING days d
+WHERE e.bottle_id = d.bottle_id
+  AND e.event_type = 'drift'
+  AND e.created_at < sqlc.arg(before)::timestamptz
+  AND (e.created_at AT TIME ZONE 'UTC')::date = d.day
+  AND e.id <> d.keep_id;
+
+-- name: ListReplayEvents :many
+-- Position events of every Bottle seen inside the bbox during [from_at, to_at], plus each
+-- one's last position before from_at so the first frames have somewhere to start from.
+WITH seen AS (
+    SELECT DISTINCT e.bottle_id
+    FROM bottle_events e
+    WHERE e.created_at BETWEEN sqlc.arg(from_at)::timestamptz AND sqlc.arg(to_at)::timestamptz
+      AND e.event_type IN ('cast', 'released', 'drift', 're_released')
+      AND e.lat BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+      AND e.lng BETWEEN sqlc.arg(min_lng)::float8 AND sqlc.arg(max_lng)::float8
+    LIMIT sqlc.arg(max_bottles)::int
+), track AS (
+    SELECT e.id, e.bottle_id, e.event_type, e.lat, e.lng, e.created_at
+    FROM bottle_events e
+    JOIN seen s ON s.bottle_id = e.bottle_id
+    WHERE e.created_at BETWEEN sqlc.arg(from_at)::timestamptz AND sqlc.arg(to_at)::timestamptz
+      AND e.event_type IN ('cast', 'released', 'drift', 're_released', 'sink', 'discovered')
+    UNION ALL
+    SELECT id, bottle_id, event_type, lat, lng, created_at FROM (
+        SELECT DISTINCT ON (e.bottle_id) e.id, e.bottle_id, e.event_type, e.lat, e.lng, e.created_at
+        FROM bottle_events e
+        JOIN seen s ON s.bottle_id = e.bottle_id
+        WHERE e.created_at < sqlc.arg(from_at)::timestamptz
+          AND e.event_type IN ('cast', 'released', 'drift', 're_released')
+        ORDER BY e.bottle_id, e.created_at DESC, e.id DESC
+    ) before
+)
+SELECT t.id, t.bottle_id, t.event_type, t.lat, t.lng, t.created_at, b.bottle_style, b.visible_at
+FROM track t
+JOIN bottles b ON b.id = t.bottle_id
+ORDER BY t.bottle_id, t.created_at, t.id;
+
+-- name: ListOceanCounters :many
+-- Each counter summed across its shards.
+SELECT name, sum(value)::float8 AS value FROM ocean_counters GROUP BY name;
+
+-- name: SumOceanActivity :many
+-- Casts and Stamps per kind across the hourly buckets starting at or after `since`.
+SELECT kind, sum(n)::bigint AS n
+FROM ocean_activity_hourly
+WHERE hour >= sqlc.arg(since)::timestamptz
+GROUP BY kind;
+
+-- name: DeleteOceanActivityBefore :execrows
+DELETE FROM ocean_activity_hourly WHERE hour < sqlc.arg(before)::timestamptz;
+
+-- name: ListNotableBottles :many
+-- The top `limit` drifting Bottles in each ranking, read from the trigger-kept
+-- bottle_voyages totals (00011). Sunk and Mystery Delay Bottles are left out.
+(SELECT 'longest_voyages'::text AS ranking, b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.distance_km > 0
+ ORDER BY v.distance_km DESC, b.id LIMIT sqlc.arg(lim)::int)
+UNION ALL
+(SELECT 'most_re_released', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.re_releases > 0
+ ORDER BY v.re_releases DESC, b.id LIMIT sqlc.arg(lim)::int)
+UNION ALL
+(SELECT 'most_stamped', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.stamps > 0
+ ORDER BY v.stamps DESC, b.id LIMIT sqlc.arg(lim)::int)
+UNION ALL
+(SELECT 'most_basins', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        v.distance_km, v.re_releases, v.stamps, v.basins
+ FROM bottle_voyages v JOIN bottles b ON b.id = v.bottle_id
+ WHERE b.status = 'drifting' AND v.basin_count > 0
+ ORDER BY v.basin_count DESC, v.distance_km DESC, b.id LIMIT sqlc.arg(lim)::int)
+UNION ALL
+(SELECT 'oldest', b.id, b.bottle_style, b.current_lat, b.current_lng, b.created_at,
+        COALESCE(v.distance_km, 0), COALESCE(v.re_releases, 0), COALESCE(v.stamps, 0), COALESCE(v.basins, '{}')
+ FROM bottles b LEFT JOIN bottle_voyages v ON v.bottle_id = b.id
+ WHERE b.status = 'drifting'
+ ORDER BY b.created_at, b.id LIMIT sqlc.arg(lim)::int);
+
+-- name: ListSampleCandidates :many
+-- Up to `lim` visible drifting Bottles from the sampling index at or after `from_key`,
+-- wrapping to the start when the tail runs short, plus as many again from `basin`
+-- (empty for none). Each carries its Journey totals so the caller can weight the pick.
+WITH ahead AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE sample_key >= sqlc.arg(from_key)::float8
+    ORDER BY sample_key LIMIT sqlc.arg(lim)::int
+), wrapped AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE sample_key < sqlc.arg(from_key)::float8
+    ORDER BY sample_key LIMIT sqlc.arg(lim)::int - (SELECT count(*) FROM ahead)
+), basin_ahead AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE basin = sqlc.arg(basin)::text AND sample_key >= sqlc.arg(from_key)::float8
+    ORDER BY sample_key LIMIT sqlc.arg(lim)::int
+), basin_wrapped AS (
+    SELECT bottle_id, basin FROM bottle_samples
+    WHERE basin = sqlc.arg(basin)::text AND sample_key < sqlc.arg(from_key)::float8
+    ORDER BY sample_key LIMIT sqlc.arg(lim)::int - (SELECT count(*) FROM basin_ahead)
+), picked AS (
+    SELECT bottle_id, basin FROM ahead UNION SELECT bottle_id, basin FROM wrapped
+    UNION SELECT bottle_id, basin FROM basin_ahead UNION SELECT bottle_id, basin FROM basin_wrapped
+)
+SELECT sqlc.embed(b), p.basin,
+       COALESCE(v.re_releases, 0)::int AS re_releases,
+       COALESCE(v.stamps, 0)::int AS stamps
+FROM picked p
+JOIN bottles b ON b.id = p.bottle_id
+LEFT JOIN bottle_voyages v ON v.bottle_id = b.id
+WHERE b.status = 'drifting' AND b.is_release = TRUE
+ORDER BY b.id;
@@ -0,0 +1,393 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaModel is table → column → Column, enough to spot drift between
+// schema.sql, the migration chain and information_schema.
+type SchemaModel map[string]map[string]Column
+
+// Column uses information_schema spelling for Type ("integer", "timestamp with time zone").
+type Column struct {
+	Name    string
+	Type    string
+	NotNull bool
+}
+
+// ParseSchema reads the CREATE/ALTER/DROP TABLE statements in sql; everything else is ignored.
+func ParseSchema(sql string) (SchemaModel, error) {
+	model := make(SchemaModel)
+	for _, stmt := range splitStatements(sql) {
+		if err := model.apply(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return model, nil
+}
+
+// SchemaFromMigrations replays the Up sections of migrations, in order.
+func SchemaFromMigrations(migrations []Migration) (SchemaModel, error) {
+	model := make(SchemaModel)
+	for _, mig := range migrations {
+		for _, stmt := range splitStatements(mig.Up) {
+			if err := model.apply(stmt); err != nil {
+				return nil, fmt.Errorf("migration %05d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+	}
+	return model, nil
+}
+
+// DiffSchemas lists every way got differs from want, sorted for stable output.
+func DiffSchemas(want, got SchemaModel) []string {
+	var diffs []string
+	for table, cols := range want {
+		gotCols, ok := got[table]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("table %s: missing", table))
+			continue
+		}
+		for name, w := range cols {
+			g, ok := gotCols[name]
+			switch {
+			case !ok:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: missing", table, name))
+			case g.Type != w.Type:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: type %s, want %s", table, name, g.Type, w.Type))
+			case g.NotNull != w.NotNull:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: not null %t, want %t", table, name, g.NotNull, w.NotNull))
+			}
+		}
+		for name := range gotCols {
+			if _, ok := cols[name]; !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: unexpected column", table, name))
+			}
+		}
+	}
+	for table := range got {
+		if _, ok := want[table]; !ok {
+			diffs = append(diffs, fmt.Sprintf("table %s: unexpected", table))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)\s*\((.*)\)\s*$`)
+	alterTableRe  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."]+)\s+(.*)$`)
+	dropTableRe   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.*?)(?:\s+CASCADE|\s+RESTRICT)?$`)
+)
+
+func (s SchemaModel) apply(stmt string) error {
+	if m := createTableRe.FindStringSubmatch(stmt); m != nil {
+		table := ident(m[1])
+		if _, exists := s[table]; exists {
+			return nil // IF NOT EXISTS on an adopted database
+		}
+		cols := make(map[string]Column)
+		for _, def := range splitTopLevel(m[2], ',') {
+			if pk, ok := tablePrimaryKey(def); ok {
+				for _, name := range pk {
+					c := cols[name]
+					c.NotNull = true
+					cols[name] = c
+				}
+				continue
+			}
+			if isTableConstraint(def) {
+				continue
+			}
+			col := parseColumn(def)
+			cols[col.Name] = col
+		}
+		s[table] = cols
+		return nil
+	}
+	if m := alterTableRe.FindStringSubmatch(stmt); m != nil {
+		return s.alter(ident(m[1]), m[2])
+	}
+	if m := dropTableRe.FindStringSubmatch(stmt); m != nil {
+		for _, name := range strings.Split(m[1], ",") {
+			delete(s, ident(name))
+		}
+	}
+	return nil
+}
+
+func (s SchemaModel) alter(table, actions string) error {
+	if rest, ok := cutWords(actions, "RENAME", "TO"); ok {
+		s[ident(rest)] = s[table]
+		delete(s, table)
+		return nil
+	}
+	cols, ok := s[table]
+	if !ok {
+		return fmt.Errorf("alter unknown table %s", table)
+	}
+	for _, action := range splitTopLevel(actions, ',') {
+		switch {
+		case hasWords(action, "ADD", "CONSTRAINT"), hasWords(action, "DROP", "CONSTRAINT"):
+		case hasWords(action, "RENAME"):
+			rest, _ := cutWords(action, "RENAME")
+			rest, _ = cutOptional(rest, "COLUMN")
+			words := strings.Fields(rest)
+			if len(words) != 3 || !strings.EqualFold(words[1], "TO") {
+				return fmt.Errorf("cannot read %q on %s", action, table)
+			}
+			from, to := ident(words[0]), ident(words[2])
+			c, ok := cols[from]
+			if !ok {
+				return fmt.Errorf("rename unknown column %s.%s", table, from)
+			}
+			delete(cols, from)
+			c.Name = to
+			cols[to] = c
+		case hasWords(action, "ADD"):
+			rest, _ := cutWords(action, "ADD")
+			rest, _ = cutOptional(rest, "COLUMN")
+			rest, _ = cutOptional(rest, "IF", "NOT", "EXISTS")
+			col := parseColumn(rest)
+			if _, exists := cols[col.Name]; !exists {
+				cols[col.Name] = col
+			}
+		case hasWords(action, "DROP"):
+			rest, _ := cutWords(action, "DROP")
+			rest, _ = cutOptional(rest, "COLUMN")
+			rest, _ = cutOptional(rest, "IF", "EXISTS")
+			delete(cols, ident(firstWord(rest)))
+		case hasWords(action, "ALTER"):
+			rest, _ := cutWords(action, "ALTER")
+			rest, _ = cutOptional(rest, "COLUMN")
+			name := ident(firstWord(rest))
+			c, ok := cols[name]
+			if !ok {
+				return fmt.Errorf("alter unknown column %s.%s", table, name)
+			}
+			change := strings.TrimSpace(rest[len(firstWord(rest)):])
+			switch {
+			case hasWords(change, "SET", "NOT", "NULL"):
+				c.NotNull = true
+			case hasWords(change, "DROP", "NOT", "NULL"):
+				c.NotNull = false
+			case hasWords(change, "TYPE"), hasWords(change, "SET", "DATA", "TYPE"):
+				typ, _ := cutWords(change, "SET", "DATA", "TYPE")
+				if typ == "" {
+					typ, _ = cutWords(change, "TYPE")
+				}
+				if using := strings.Index(strings.ToUpper(typ), " USING "); using >= 0 {
+					typ = typ[:using]
+				}
+				c.Type = normalizeType(typ)
+			}
+			cols[name] = c
+		}
+	}
+	return nil
+}
+
+// columnStop ends the type part of a column definition.
+var columnStop = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "REFERENCES": true,
+	"UNIQUE": true, "CHECK": true, "GENERATED": true, "CONSTRAINT": true, "COLLATE": true,
+}
+
+func parseColumn(def string) Column {
+	words := strings.Fields(def)
+	col := Column{Name: ident(words[0])}
+	var typ []string
+	i := 1
+	for ; i < len(words) && !columnStop[strings.ToUpper(words[i])]; i++ {
+		typ = append(typ, words[i])
+	}
+	col.Type = normalizeType(strings.Join(typ, " "))
+	rest := strings.ToUpper(strings.Join(words[i:], " "))
+	col.NotNull = strings.Contains(rest, "NOT NULL") || strings.Contains(rest, "PRIMARY KEY") ||
+		strings.HasSuffix(strings.ToLower(strings.Join(typ, "")), "serial") // serial implies NOT NULL
+	return col
+}
+
+var typeParams = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// normalizeType maps Postgres type spellings onto information_schema.columns.data_type.
+func normalizeType(typ string) string {
+	t := strings.ToLower(strings.Join(strings.Fields(typ), " "))
+	if strings.HasSuffix(t, "[]") {
+		return "ARRAY"
+	}
+	t = typeParams.ReplaceAllString(t, "")
+	switch t {
+	case "serial", "serial4", "int", "int4", "integer":
+		return "integer"
+	case "bigserial", "serial8", "int8", "bigint":
+		return "bigint"
+	case "smallserial", "serial2", "int2", "smallint":
+		return "smallint"
+	case "float8", "double precision":
+		return "double precision"
+	case "float4", "real":
+		return "real"
+	case "bool", "boolean":
+		return "boolean"
+	case "varchar", "character varying":
+		return "character varying"
+	case "decimal", "numeric":
+		return "numeric"
+	case "timestamptz", "timestamp with time zone":
+		return "timestamp with time zone"
+	case "timestamp", "timestamp without time zone":
+		return "timestamp without time zone"
+	}
+	return t
+}
+
+func tablePrimaryKey(def string) ([]string, bool) {
+	rest := def
+	if r, ok := cutWords(rest, "CONSTRAINT"); ok {
+		rest = strings.TrimSpace(r[len(firstWord(r)):])
+	}
+	rest, ok := cutWords(rest, "PRIMARY", "KEY")
+	if !ok {
+		return nil, false
+	}
+	rest = strings.Trim(strings.TrimSpace(rest), "()")
+	var names []string
+	for _, n := range strings.Split(rest, ",") {
+		names = append(names, ident(n))
+	}
+	return names, true
+}
+
+func isTableConstraint(def string) bool {
+	switch strings.ToUpper(firstWord(def)) {
+	case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "EXCLUDE", "LIKE":
+		return true
+	}
+	return false
+}
+
+// splitStatements drops -- comments and splits on top-level semicolons,
+// leaving quoted strings and $$ bodies (trigger functions) intact.
+func splitStatements(sql string) []string {
+	var out []string
+	var cur strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(cur.String()); stmt != "" {
+			out = append(out, stmt)
+		}
+		cur.Reset()
+	}
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+				cur.WriteByte('\n')
+			}
+		case sql[i] == '\'':
+			end := strings.IndexByte(sql[i+1:], '\'')
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+			cur.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case sql[i] == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				cur.WriteByte(sql[i])
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - len(tag)
+			}
+			stop := min(i+len(tag)+end+len(tag), len(sql))
+			cur.WriteString(sql[i:stop])
+			i = stop - 1
+		case sql[i] == ';':
+			flush()
+		default:
+			cur.WriteByte(sql[i])
+		}
+	}
+	flush()
+	return out
+}
+
+var dollarTagRe = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+func dollarTag(s string) string {
+	return dollarTagRe.FindString(s)
+}
+
+// splitTopLevel splits on sep outside parentheses.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// cutWords reports whether s starts with words (case-insensitive) and returns the rest.
+func cutWords(s string, words ...string) (string, bool) {
+	fields := strings.Fields(s)
+	if len(fields) < len(words) {
+		return "", false
+	}
+	for i, w := range words {
+		if !strings.EqualFold(fields[i], w) {
+			return "", false
+		}
+	}
+	return strings.Join(fields[len(words):], " "), true
+}
+
+func cutOptional(s string, words ...string) (string, bool) {
+	if rest, ok := cutWords(s, words...); ok {
+		return rest, true
+	}
+	return strings.TrimSpace(s), false
+}
+
+func hasWords(s string, words ...string) bool {
+	_, ok := cutWords(s, words...)
+	return ok
+}
+
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// ident strips quotes and a schema prefix.
+func ident(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	if i := strings.LastIndexByte(s, '.'); i >= 0 {
+		s = strings.Trim(s[i+1:], `"`)
+	}
+	return strings.ToLower(s)
+}
@@ -0,0 +1,62 @@
+package card
+
+import (
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/lru"
+	"github.com/Polqt/ocealis/internal/metrics"
+)
+
+// DefaultCacheSize is the card count NewCache falls back to for a non-positive size.
+const DefaultCacheSize = 512
+
+// Version identifies what a card was drawn from. Journey events are append-only, so the
+// newest event ID and the event count change whenever the card would — a Cache entry
+// with a stale Version is simply re-rendered.
+type Version struct {
+	LastEventID int32
+	Events      int
+}
+
+// VersionOf is j's current Version.
+func VersionOf(j *domain.Journey) Version {
+	v := Version{Events: len(j.Events)}
+	for _, e := range j.Events {
+		v.LastEventID = max(v.LastEventID, e.ID)
+	}
+	return v
+}
+
+// Cache keeps the most recently served cards in memory, one per Bottle.
+type Cache struct {
+	cards *lru.Cache[int32, cachedCard]
+}
+
+type cachedCard struct {
+	version Version
+	png     []byte
+}
+
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{cards: lru.New[int32, cachedCard](size)}
+}
+
+// Card returns j's card PNG, rendering only when the cached one is missing or was
+// drawn from an older Journey.
+func (c *Cache) Card(j *domain.Journey) ([]byte, error) {
+	id, v := j.Bottle.ID, VersionOf(j)
+	if cached, ok := c.cards.Get(id); ok && cached.version == v {
+		metrics.CardRenders.WithLabelValues("hit").Inc()
+		return cached.png, nil
+	}
+
+	png, err := Render(j)
+	if err != nil {
+		return nil, err
+	}
+	metrics.CardRenders.WithLabelValues("render").Inc()
+	c.cards.Add(id, cachedCard{version: v, png: png})
+	return png, nil
+}
@@ -0,0 +1,237 @@
+// Package card renders a Bottle's share card: the postcard image chat apps unfurl
+// from a /b/:id link. Pure Go — stdlib image, an embedded 5x7 font and the embedded
+// coastline — so the API binary needs no fonts, CGO or headless browser.
+package card
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"unicode"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/export"
+	"github.com/Polqt/ocealis/internal/geo"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Width and Height are the Open Graph large-image size (1.91:1).
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin       = 60
+	mapLeft      = 640
+	messageScale = 4
+	messageLines = 8
+	// messageCols is how many glyphs fit left of the map.
+	messageCols = (mapLeft - margin - 40) / (advance * messageScale)
+)
+
+var (
+	paper   = color.RGBA{0xf4, 0xeb, 0xd9, 0xff}
+	ink     = color.RGBA{0x1b, 0x49, 0x65, 0xff}
+	faded   = color.RGBA{0x5f, 0x7a, 0x8a, 0xff}
+	ocean   = color.RGBA{0xa9, 0xd6, 0xe5, 0xff}
+	land    = color.RGBA{0xe0, 0xd2, 0xb4, 0xff}
+	trail   = color.RGBA{0xc0, 0x39, 0x2b, 0xff}
+	castPin = color.RGBA{0x2a, 0x9d, 0x8f, 0xff}
+)
+
+// mapRect is the mini world map, equirectangular at 2:1.
+var mapRect = image.Rect(mapLeft, 190, mapLeft+500, 190+250)
+
+// glassColors tint the bottle glyph by bottle_style, indexed like the styles catalog.
+var glassColors = [...]color.RGBA{
+	{0x3a, 0x7d, 0x44, 0xff}, {0x8a, 0x5a, 0x2b, 0xff}, {0x2e, 0x6f, 0x9e, 0xff},
+	{0x7b, 0x3f, 0x8c, 0xff}, {0xb0, 0x8d, 0x2c, 0xff}, {0x9e, 0x3b, 0x3b, 0xff},
+	{0x4f, 0x9a, 0x94, 0xff}, {0x55, 0x55, 0x55, 0xff}, {0xc2, 0x6a, 0x2f, 0xff},
+	{0x6d, 0x8f, 0x2f, 0xff}, {0xc8, 0xe7, 0xf0, 0xff},
+}
+
+// Render draws j as a PNG share card: the Message, Nickname, bottle style, hop count
+// and the Journey path on a mini world map.
+func Render(j *domain.Journey) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fillRect(img, img.Bounds(), paper)
+
+	b := j.Bottle
+	drawText(img, margin, margin, 3, "A MESSAGE IN A BOTTLE", faded)
+
+	y := margin + 70
+	for _, line := range wrap(Legible(b.MessageText, "a message"), messageCols, messageLines) {
+		drawText(img, margin, y, messageScale, line, ink)
+		y += (glyphH + 3) * messageScale
+	}
+
+	drawText(img, margin, Height-margin-80, messageScale, "- "+Legible(b.Nickname, "a name"), ink)
+	hops := fmt.Sprintf("%d hops", b.Hops)
+	if b.Hops == 1 {
+		hops = "1 hop"
+	}
+	drawText(img, margin, Height-margin-21, 3,
+		fmt.Sprintf("style %d | %s | %s", b.BottleStyle, hops, strings.ReplaceAll(string(b.Status), "_", " ")), faded)
+
+	drawBottle(img, image.Pt(mapRect.Max.X-40, margin), glassColors[int(b.BottleStyle)%len(glassColors)])
+	drawJourneyMap(img, j)
+
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawJourneyMap(img *image.RGBA, j *domain.Journey) {
+	fillRect(img, mapRect.Inset(-3), ink)
+	fillRect(img, mapRect, ocean)
+	for _, l := range geo.Coastlines() {
+		pts := make([]image.Point, len(l.Ring))
+		for i, p := range l.Ring {
+			pts[i] = project(p.Lng, p.Lat)
+		}
+		fillPolygon(img, pts, mapRect, land)
+	}
+
+	path := export.Path(j.Events)
+	for _, seg := range path {
+		for i := 1; i < len(seg); i++ {
+			drawLine(img, project(seg[i-1].Lng, seg[i-1].Lat), project(seg[i].Lng, seg[i].Lat), 3, trail)
+		}
+	}
+	end := project(j.Bottle.CurrentLng, j.Bottle.CurrentLat)
+	if len(path) > 0 {
+		first, last := path[0][0], path[len(path)-1]
+		fillDisc(img, project(first.Lng, first.Lat), 6, castPin)
+		end = project(last[len(last)-1].Lng, last[len(last)-1].Lat)
+	}
+	fillDisc(img, end, 7, trail)
+}
+
+// project maps lng/lat onto mapRect, clamped inside it.
+func project(lng, lat float64) image.Point {
+	x := mapRect.Min.X + int((lng+180)/360*float64(mapRect.Dx()))
+	y := mapRect.Min.Y + int((90-lat)/180*float64(mapRect.Dy()))
+	return image.Pt(min(max(x, mapRect.Min.X), mapRect.Max.X-1), min(max(y, mapRect.Min.Y), mapRect.Max.Y-1))
+}
+
+// drawBottle is a small cork-stoppered bottle, top-centred at p.
+func drawBottle(img *image.RGBA, p image.Point, glass color.RGBA) {
+	fillRect(img, image.Rect(p.X-5, p.Y, p.X+5, p.Y+8), color.RGBA{0xa0, 0x7a, 0x4f, 0xff})
+	fillRect(img, image.Rect(p.X-6, p.Y+8, p.X+6, p.Y+30), glass)
+	fillRect(img, image.Rect(p.X-18, p.Y+30, p.X+18, p.Y+100), glass)
+	fillRect(img, image.Rect(p.X-10, p.Y+50, p.X+10, p.Y+80), paper)
+}
+
+// typography maps the punctuation people actually type onto ASCII look-alikes.
+var typography = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201c", `"`, "\u201d", `"`,
+	"\u2013", "-", "\u2014", "-", "\u2026", "...",
+)
+
+// Legible is s as the card draws it: folded onto the font, or, when most of s is in a
+// script the font has no glyphs for, "(<what> in <language>)" instead of a row of '?'.
+func Legible(s, what string) string {
+	folded, missing := ascii(s)
+	if drawn := len(strings.ReplaceAll(folded, " ", "")); missing > 0 && missing*2 >= drawn {
+		return "(" + what + " in " + languageName(s) + ")"
+	}
+	return folded
+}
+
+// ascii folds s onto the font: typographic punctuation becomes ASCII, accents are
+// dropped (é → e), whitespace runs become one space, and anything else outside
+// printable ASCII draws as '?'. missing counts those.
+func ascii(s string) (folded string, missing int) {
+	var b strings.Builder
+	space := false
+	for _, r := range norm.NFD.String(typography.Replace(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+			missing++
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), missing
+}
+
+// scriptLanguages names the language a script most likely carries, checked in order:
+// kana before Han, so Japanese mixing in kanji is not called Chinese.
+var scriptLanguages = []struct {
+	script *unicode.RangeTable
+	name   string
+}{
+	{unicode.Hiragana, "Japanese"},
+	{unicode.Katakana, "Japanese"},
+	{unicode.Hangul, "Korean"},
+	{unicode.Han, "Chinese"},
+	{unicode.Arabic, "Arabic"},
+	{unicode.Hebrew, "Hebrew"},
+	{unicode.Cyrillic, "Russian"},
+	{unicode.Greek, "Greek"},
+	{unicode.Thai, "Thai"},
+	{unicode.Devanagari, "Hindi"},
+}
+
+// languageName guesses s's language from its letters' script.
+func languageName(s string) string {
+	for _, sl := range scriptLanguages {
+		for _, r := range s {
+			if unicode.Is(sl.script, r) {
+				return sl.name
+			}
+		}
+	}
+	return "another script"
+}
+
+// wrap breaks s into at most maxLines lines of width cols, splitting words longer than
+// a line and ending with "..." when the text does not fit.
+func wrap(s string, cols, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for len(word) > cols {
+			if line != "" {
+				lines, line = append(lines, line), ""
+			}
+			lines, word = append(lines, word[:cols]), word[cols:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= cols:
+			line += " " + word
+		default:
+			lines, line = append(lines, line), word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if len(lines) > maxLines {
+		last := lines[maxLines-1]
+		if len(last) > cols-3 {
+			last = last[:cols-3]
+		}
+		lines = append(lines[:maxLines-1], last+"...")
+	}
+	return lines
+}
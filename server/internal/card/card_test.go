@@ -0,0 +1,120 @@
+package card_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/card"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var trail = color.RGBA{0xc0, 0x39, 0x2b, 0xff}
+
+func journey(events int) *domain.Journey {
+	t0 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	j := &domain.Journey{Bottle: &domain.Bottle{
+		ID: 7, Nickname: "Ñandú", MessageText: "Hello from the café — “long” quiet nights…",
+		BottleStyle: 3, Hops: 1, Status: domain.BottleStatusDrifting,
+	}}
+	for i := range events {
+		typ := domain.EventTypeDrift
+		if i == 0 {
+			typ = domain.EventTypeCast
+		}
+		j.Events = append(j.Events, domain.BottleEvent{
+			ID: int32(i + 1), BottleID: 7, EventType: typ,
+			Lat: 30 + float64(i), Lng: -150 + 2*float64(i), CreatedAt: t0.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return j
+}
+
+func trailPixels(t *testing.T, b []byte) int {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got.X != card.Width || got.Y != card.Height {
+		t.Fatalf("card is %v, want %dx%d", got, card.Width, card.Height)
+	}
+	n := 0
+	for y := range card.Height {
+		for x := range card.Width {
+			if color.RGBAModel.Convert(img.At(x, y)) == trail {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderDrawsTheJourneyPath(t *testing.T) {
+	short, err := card.Render(journey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := card.Render(journey(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both have the current-position pin; only the longer Journey has a trail behind it.
+	if s, l := trailPixels(t, short), trailPixels(t, long); s == 0 || l <= s {
+		t.Fatalf("trail pixels: 1 event %d, 20 events %d", s, l)
+	}
+}
+
+func TestCacheRerendersOnlyAfterNewJourneyEvents(t *testing.T) {
+	cache := card.NewCache(1)
+	hits := func() float64 { return testutil.ToFloat64(metrics.CardRenders.WithLabelValues("hit")) }
+	renders := func() float64 { return testutil.ToFloat64(metrics.CardRenders.WithLabelValues("render")) }
+	h0, r0 := hits(), renders()
+
+	j := journey(3)
+	first, err := cache.Card(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := cache.Card(journey(3))
+	if &again[0] != &first[0] || hits()-h0 != 1 || renders()-r0 != 1 {
+		t.Fatalf("same Journey should be a cache hit (hits %v, renders %v)", hits()-h0, renders()-r0)
+	}
+
+	if _, err := cache.Card(journey(4)); err != nil {
+		t.Fatal(err)
+	}
+	if renders()-r0 != 2 {
+		t.Fatalf("a new Journey event should re-render, renders %v", renders()-r0)
+	}
+
+	other := journey(3)
+	other.Bottle = &domain.Bottle{ID: 8}
+	if _, err := cache.Card(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Card(journey(4)); err != nil {
+		t.Fatal(err)
+	}
+	if renders()-r0 != 4 {
+		t.Fatalf("size-1 cache should have evicted Bottle 7, renders %v", renders()-r0)
+	}
+}
+
+func TestLegibleNamesScriptsTheFontCannotDraw(t *testing.T) {
+	for in, want := range map[string]string{
+		"Hello from the café — “long” nights…": `Hello from the cafe - "long" nights...`,
+		"海の向こうから、こんにちは":                        "(a message in Japanese)",
+		"رسالة في زجاجة":                       "(a message in Arabic)",
+		"Привет из моря":                       "(a message in Russian)",
+		"see you at 東京":                        "see you at ??",
+	} {
+		if got := card.Legible(in, "a message"); got != want {
+			t.Errorf("Legible(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package card
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillPolygon fills pts (even-odd rule) one scanline at a time, clipped to clip.
+func fillPolygon(img *image.RGBA, pts []image.Point, clip image.Rectangle, c color.Color) {
+	if len(pts) < 3 {
+		return
+	}
+	minY, maxY := pts[0].Y, pts[0].Y
+	for _, p := range pts {
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	minY, maxY = max(minY, clip.Min.Y), min(maxY, clip.Max.Y-1)
+
+	xs := make([]int, 0, 8)
+	for y := minY; y <= maxY; y++ {
+		fy := float64(y) + 0.5
+		xs = xs[:0]
+		for i := range pts {
+			a, b := pts[i], pts[(i+1)%len(pts)]
+			if (float64(a.Y) <= fy) == (float64(b.Y) <= fy) {
+				continue
+			}
+			t := (fy - float64(a.Y)) / float64(b.Y-a.Y)
+			xs = append(xs, int(math.Round(float64(a.X)+t*float64(b.X-a.X))))
+		}
+		sort.Ints(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0, x1 := max(xs[i], clip.Min.X), min(xs[i+1], clip.Max.X)
+			if x0 < x1 {
+				fillRect(img, image.Rect(x0, y, x1, y+1), c)
+			}
+		}
+	}
+}
+
+// drawLine strokes a to b with a square pen of width w.
+func drawLine(img *image.RGBA, a, b image.Point, w int, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := max(abs(dx), abs(dy), 1)
+	for i := 0; i <= steps; i++ {
+		x := a.X + int(math.Round(float64(dx*i)/float64(steps)))
+		y := a.Y + int(math.Round(float64(dy*i)/float64(steps)))
+		fillRect(img, image.Rect(x-w/2, y-w/2, x-w/2+w, y-w/2+w), c)
+	}
+}
+
+func fillDisc(img *image.RGBA, center image.Point, r int, c color.Color) {
+	for dy := -r; dy <= r; dy++ {
+		half := int(math.Sqrt(float64(r*r - dy*dy)))
+		fillRect(img, image.Rect(center.X-half, center.Y+dy, center.X+half+1, center.Y+dy+1), c)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
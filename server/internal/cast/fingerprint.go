@@ -0,0 +1,76 @@
+package cast
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleRunes is the character n-gram width fed into the simhash.
+// Short Messages need small shingles so one swapped word moves only a few bits.
+const shingleRunes = 4
+
+// Fingerprint is a 64-bit simhash of a Message's character shingles.
+// Case, punctuation and whitespace runs are ignored, so "Buy pills!!" and
+// "buy   pills" land on the same fingerprint; small edits flip only a few bits.
+func Fingerprint(message string) uint64 {
+	norm := normalizeForFingerprint(message)
+	if norm == "" {
+		return 0
+	}
+
+	runes := []rune(norm)
+	var weights [64]int
+	addShingle := func(s string) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(s))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	if len(runes) <= shingleRunes {
+		addShingle(norm)
+	} else {
+		for i := 0; i+shingleRunes <= len(runes); i++ {
+			addShingle(string(runes[i : i+shingleRunes]))
+		}
+	}
+
+	var fp uint64
+	for i, w := range weights {
+		if w > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+	return fp
+}
+
+// Distance is the Hamming distance between two fingerprints.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// normalizeForFingerprint lowercases letters/digits and folds everything else to single spaces.
+func normalizeForFingerprint(message string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range message {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
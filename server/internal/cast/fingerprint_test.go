@@ -0,0 +1,28 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/cast"
+)
+
+func TestFingerprintIgnoresCaseAndPunctuation(t *testing.T) {
+	a := cast.Fingerprint("Buy cheap pills at pharma-example.com now, best prices in the ocean")
+	b := cast.Fingerprint("BUY cheap pills at pharma-example.com now!! best prices in the ocean")
+	if a != b {
+		t.Fatalf("case/punctuation variants must share a fingerprint; distance=%d", cast.Distance(a, b))
+	}
+}
+
+func TestFingerprintSmallEditsStayNear(t *testing.T) {
+	base := cast.Fingerprint("Buy cheap pills at pharma-example.com now, best prices in the ocean")
+	variant := cast.Fingerprint("Buy cheap pills at pharma-example.com today, best prices in the ocean")
+	unrelated := cast.Fingerprint("The tide took my grandfather's letter and I hope it finds someone kind")
+
+	if d := cast.Distance(base, variant); d > 10 {
+		t.Fatalf("one-word edit drifted too far: %d bits", d)
+	}
+	if d := cast.Distance(base, unrelated); d <= 10 {
+		t.Fatalf("unrelated Messages look like duplicates: %d bits", d)
+	}
+}
@@ -0,0 +1,67 @@
+package hygiene
+
+import "unicode"
+
+// Graphemes counts user-perceived characters: a base plus its combining marks, an
+// emoji with its skin tone, variation selector or ZWJ-joined partners, and a pair
+// of regional indicators (a flag) each count once. It follows the UAX #29 extended
+// grapheme rules Ocealis text can hit, which is all length limits need.
+func Graphemes(s string) int {
+	n := 0
+	var prev rune = -1
+	pictographic := false // the current cluster started with an emoji
+	regional := 0         // regional indicators in the current cluster
+	for _, r := range s {
+		if prev >= 0 {
+			switch {
+			case extends(r):
+				prev = r
+				continue
+			case prev == zwj && pictographic && isPictographic(r):
+				prev = r
+				continue
+			case isRegional(r) && regional == 1:
+				regional++
+				prev = r
+				continue
+			}
+		}
+		n++
+		pictographic = isPictographic(r)
+		regional = 0
+		if isRegional(r) {
+			regional = 1
+		}
+		prev = r
+	}
+	return n
+}
+
+// extends are code points that never start a cluster: combining marks (variation
+// selectors included), ZWJ, emoji modifiers, tag characters and the conjoining
+// Hangul vowels and finals NFC leaves decomposed.
+func extends(r rune) bool {
+	switch {
+	case r == zwj, r == zwnj:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF, isTag(r):
+		return true
+	case r >= 0x1160 && r <= 0x11FF, r >= 0xD7B0 && r <= 0xD7FF:
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+func isRegional(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+
+// isPictographic approximates Extended_Pictographic with the emoji blocks plus
+// other symbols.
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF && !isRegional(r):
+		return true
+	case r >= 0x2600 && r <= 0x27BF:
+		return true
+	}
+	return unicode.Is(unicode.So, r)
+}
@@ -0,0 +1,134 @@
+// Package hygiene is the Unicode layer under every piece of user text Ocealis stores:
+// Messages, Nicknames and Stamp notes. It normalizes to NFC, strips characters that
+// render as nothing or reorder their neighbours, rejects control characters, counts
+// length in user-perceived characters and folds look-alike letters for abuse checks.
+//
+// It imports nothing from Ocealis so domain payload validation can share it with cast.
+package hygiene
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrControlCharacter is returned for text carrying C0/C1 controls, or line breaks
+// where a single line is expected.
+var ErrControlCharacter = errors.New("text contains control characters")
+
+const (
+	zwnj = '\u200c'
+	zwj  = '\u200d'
+	fsi  = '\u2068'
+	pdi  = '\u2069'
+)
+
+// maxMarks caps combining marks stacked on one base. Real scripts need a few
+// (Vietnamese two, Tibetan a handful); "zalgo" text piles up dozens so one
+// grapheme can smear over the lines around it.
+const maxMarks = 8
+
+// Mode says which whitespace controls a field may keep.
+type Mode int
+
+const (
+	// Line is a single line: Nicknames, Seals.
+	Line Mode = iota
+	// Multiline keeps line breaks and tabs: Messages, Stamp notes.
+	Multiline
+)
+
+// Clean normalizes s to NFC and strips invisible and bidi formatting characters.
+// Joiners (ZWJ/ZWNJ) survive only between two visible characters, where emoji
+// sequences and Indic/Persian scripts need them. CRLF, CR and U+2028/U+2029 become
+// \n in Multiline mode; any other control character is ErrControlCharacter.
+// Combining marks past maxMarks on one base are dropped.
+func Clean(s string, mode Mode) (string, error) {
+	s = norm.NFC.String(s)
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	var prev rune = -1 // last rune written, -1 at start
+	marks := 0         // combining marks written since the last base
+	for i, r := range runes {
+		switch {
+		case r == '\r' || r == '\n' || r == '\u2028' || r == '\u2029':
+			if mode != Multiline {
+				return "", ErrControlCharacter
+			}
+			if r == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+				continue
+			}
+			r = '\n'
+		case r == '\t':
+			if mode != Multiline {
+				return "", ErrControlCharacter
+			}
+		case unicode.IsControl(r):
+			return "", ErrControlCharacter
+		case r == zwj || r == zwnj:
+			if !visible(prev) || i+1 >= len(runes) || !visible(runes[i+1]) {
+				continue
+			}
+		case isTag(r):
+			// Tag characters only spell subdivision flags after a black flag.
+			if prev != '\U0001F3F4' && !isTag(prev) {
+				continue
+			}
+		case invisible(r):
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me):
+			if marks++; marks > maxMarks {
+				continue
+			}
+		}
+		if !unicode.In(r, unicode.Mn, unicode.Me) {
+			marks = 0
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String(), nil
+}
+
+// Blank reports whether s has nothing a reader could see.
+func Blank(s string) bool {
+	for _, r := range s {
+		if visible(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// visible is a letter, digit, punctuation mark or symbol — something with ink.
+func visible(r rune) bool {
+	if r < 0 || invisible(r) {
+		return false
+	}
+	return unicode.In(r, unicode.L, unicode.N, unicode.P, unicode.S, unicode.M)
+}
+
+// invisible are format characters (Cf: zero-widths, bidi marks, overrides and
+// isolates, soft hyphen, BOM) and the blank letters spammers use for empty names.
+func invisible(r rune) bool {
+	switch r {
+	case '\u115f', '\u1160', '\u3164', '\uffa0', '\u2800':
+		return true
+	}
+	return unicode.Is(unicode.Cf, r)
+}
+
+func isTag(r rune) bool { return r >= 0xE0020 && r <= 0xE007F }
+
+// Isolate wraps user text in first-strong isolate marks so a right-to-left Nickname
+// cannot reorder the sentence Ocealis splices it into. Clean strips them again, so
+// only output — never stored text — carries them.
+func Isolate(s string) string {
+	if s == "" {
+		return s
+	}
+	return string(fsi) + s + string(pdi)
+}
@@ -0,0 +1,108 @@
+package hygiene_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+)
+
+func TestCleanNormalizesAndStripsInvisibles(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+		mode           hygiene.Mode
+	}{
+		{"nfc", "Cafe\u0301", "Caf\u00e9", hygiene.Line},
+		{"zero width space", "gu\u200bll", "gull", hygiene.Line},
+		{"bidi override", "\u202egull\u202c", "gull", hygiene.Line},
+		{"isolates and marks", "\u2066a\u200fb\u2069", "ab", hygiene.Line},
+		{"bom and soft hyphen", "\ufeffgu\u00adll", "gull", hygiene.Line},
+		{"hangul filler", "\u3164", "", hygiene.Line},
+		{"dangling joiner", "\u200dgull\u200d", "gull", hygiene.Line},
+		{"emoji zwj sequence kept", "\U0001F468\u200d\U0001F469\u200d\U0001F467", "\U0001F468\u200d\U0001F469\u200d\U0001F467", hygiene.Line},
+		{"persian zwnj kept", "می\u200cخواهم", "می\u200cخواهم", hygiene.Line},
+		{"line breaks", "a\r\nb\rc\u2028d\te", "a\nb\nc\nd\te", hygiene.Multiline},
+		{"zalgo", "x" + strings.Repeat("\u0301", 40), "x" + strings.Repeat("\u0301", 8), hygiene.Multiline},
+	}
+	for _, tc := range cases {
+		got, err := hygiene.Clean(tc.in, tc.mode)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: Clean(%q) = %q, %v; want %q", tc.name, tc.in, got, err, tc.want)
+		}
+	}
+
+	for _, in := range []string{"a\x00b", "a\x1bb", "a\u0085b", "a\nb", "a\tb"} {
+		if _, err := hygiene.Clean(in, hygiene.Line); !errors.Is(err, hygiene.ErrControlCharacter) {
+			t.Errorf("Clean(%q, Line) err = %v, want ErrControlCharacter", in, err)
+		}
+	}
+	if _, err := hygiene.Clean("a\x07b", hygiene.Multiline); !errors.Is(err, hygiene.ErrControlCharacter) {
+		t.Errorf("bell in Multiline: err = %v", err)
+	}
+}
+
+func TestGraphemesCountsPerceivedCharacters(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"gull", 4},
+		{"e\u0301", 1},
+		{"\U0001F44B\U0001F3FD", 1}, // waving hand, skin tone
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467\u200d\U0001F466", 1}, // family
+		{"❤\ufe0f", 1},
+		{"\U0001F1EF\U0001F1F5\U0001F1EB\U0001F1F7", 2}, // two flags
+		{"\U0001F1EF\U0001F1F5\U0001F1EB", 2},
+		{"\U0001F3F4\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F", 1}, // England
+		{"각", 1},              // conjoining jamo
+		{"नमस\u094dत\u0947", 4}, // approximate: virama not joined
+	}
+	for _, tc := range cases {
+		if got := hygiene.Graphemes(tc.in); got != tc.want {
+			t.Errorf("Graphemes(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSkeletonFoldsLookAlikes(t *testing.T) {
+	spoofs := []string{
+		"Ocealis",
+		"ocealis",
+		"0cealis",
+		"Oceal1s",
+		"Осеаlis", // Cyrillic О, с, е, а
+		"Ｏｃｅａｌｉｓ", // fullwidth
+		"\U0001D40E\U0001D41C\U0001D41E\U0001D41A\U0001D425\U0001D422\U0001D42C", // mathematical bold
+		"Océalis",
+		"O.c.e.a.l.i.s",
+		"OCEAIIS",
+	}
+	for _, s := range spoofs {
+		if !hygiene.Confusable(s, "Ocealis") {
+			t.Errorf("%q should be confusable with Ocealis (skeleton %q vs %q)", s, hygiene.Skeleton(s), hygiene.Skeleton("Ocealis"))
+		}
+	}
+	for _, s := range []string{"Oceanic", "sailor", "", "..."} {
+		if hygiene.Confusable(s, "Ocealis") {
+			t.Errorf("%q must not be confusable with Ocealis", s)
+		}
+	}
+	if !hygiene.Confusable("modern", "modem") {
+		t.Error("rn should read as m")
+	}
+}
+
+func TestIsolateWrapsInFirstStrongIsolate(t *testing.T) {
+	if got := hygiene.Isolate("שלום"); got != "\u2068שלום\u2069" {
+		t.Fatalf("Isolate = %q", got)
+	}
+	if hygiene.Isolate("") != "" {
+		t.Fatal("empty text needs no isolate")
+	}
+	cleaned, _ := hygiene.Clean(hygiene.Isolate("gull"), hygiene.Line)
+	if cleaned != "gull" {
+		t.Fatalf("Clean must strip isolates, got %q", cleaned)
+	}
+}
@@ -0,0 +1,56 @@
+package hygiene
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables folds letters that render like Latin ones onto them, after
+// lowercasing. It is the slice of Unicode's confusables.txt that matters for
+// Latin-looking Nicknames: Cyrillic and Greek homoglyphs plus the digit and
+// letter swaps (0/o, 1/l/i) people type by hand.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'l', 'ї': 'l',
+	'ј': 'j', 'к': 'k', 'ӏ': 'l', 'м': 'm', 'п': 'n', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's',
+	'т': 't', 'у': 'y', 'ԝ': 'w', 'х': 'x', 'ү': 'y', 'ɡ': 'g',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'l', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'γ': 'y', 'ω': 'w',
+	// Latin look-alikes and hand-typed swaps
+	'ı': 'l', 'i': 'l', '1': 'l', '|': 'l', '!': 'l', '0': 'o', 'ø': 'o', 'ß': 's',
+	'ŀ': 'l', 'ł': 'l', 'đ': 'd', 'ħ': 'h',
+}
+
+// digraphs are two-letter runs that read as one letter at Nickname sizes.
+var digraphs = strings.NewReplacer("rn", "m", "vv", "w", "cl", "d")
+
+// Skeleton reduces s to the form Nicknames are compared in: compatibility forms
+// (fullwidth, mathematical letters, ligatures) unfolded, case and accents dropped,
+// confusable letters folded, and everything but letters and digits removed. Two
+// Nicknames with equal skeletons look alike to a reader.
+func Skeleton(s string) string {
+	s = norm.NFKD.String(strings.ToLower(s))
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if f, ok := confusables[unicode.ToLower(r)]; ok {
+			r = f
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return digraphs.Replace(b.String())
+}
+
+// Confusable reports whether a and b look alike once reduced to skeletons.
+func Confusable(a, b string) bool {
+	sa := Skeleton(a)
+	return sa != "" && sa == Skeleton(b)
+}
@@ -3,20 +3,16 @@ package cast
 import (
 	"errors"
 	"math/rand"
-	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/Polqt/ocealis/internal/domain"
 	"github.com/Polqt/ocealis/internal/geo"
-	"github.com/Polqt/ocealis/util"
+	"github.com/Polqt/ocealis/internal/lang"
 )
 
 const (
-	MaxNicknameRunes = 24
-	MaxMessageRunes  = 500
-	MysteryMin       = 15 * time.Minute
-	MysteryMax       = 30 * time.Minute
+	MysteryMin = 15 * time.Minute
+	MysteryMax = 30 * time.Minute
 )
 
 var (
@@ -35,26 +31,26 @@ type Plan struct {
 	VisibleAt   time.Time
 	Status      domain.BottleStatus
 	IsReleased  bool
+	// Fingerprint is the Message simhash for near-duplicate checks.
+	Fingerprint uint64
+	// Language is the Message's detected language, which picks its search stemming.
+	Language lang.Language
 }
 
-// Prepare validates Cast inputs, snaps inland to Shoreline, applies Mystery Delay.
+// Prepare cleans and validates Cast inputs, snaps inland to Shoreline, applies Mystery Delay.
 // lat/lng nil → BasinFallback (denied/missing geo).
 func Prepare(nickname, message string, lat, lng *float64, now time.Time, rng *rand.Rand) (Plan, error) {
-	nickname = strings.TrimSpace(nickname)
+	nickname, err := CleanNickname(nickname)
+	if err != nil {
+		return Plan{}, err
+	}
 	if nickname == "" {
 		return Plan{}, ErrNicknameRequired
 	}
-	if utf8.RuneCountInString(nickname) > MaxNicknameRunes {
-		return Plan{}, ErrNicknameTooLong
-	}
 
-	message = util.SanitizeMessage(message)
-	message = strings.TrimSpace(message)
-	if message == "" {
-		return Plan{}, ErrMessageRequired
-	}
-	if utf8.RuneCountInString(message) > MaxMessageRunes {
-		return Plan{}, ErrMessageTooLong
+	message, err = CleanMessage(message)
+	if err != nil {
+		return Plan{}, err
 	}
 
 	var dropLat, dropLng float64
@@ -77,5 +73,7 @@ func Prepare(nickname, message string, lat, lng *float64, now time.Time, rng *ra
 		VisibleAt:   visibleAt,
 		Status:      domain.BottleStatusMysteryDelay,
 		IsReleased:  false,
+		Fingerprint: Fingerprint(message),
+		Language:    lang.Detect(message),
 	}, nil
 }
@@ -1,6 +1,7 @@
 package cast_test
 
 import (
+	"errors"
 	"math/rand"
 	"strings"
 	"testing"
@@ -44,6 +45,9 @@ func TestCastAppliesMysteryDelayAndOceanDrop(t *testing.T) {
 	if plan.IsReleased {
 		t.Fatal("Mystery Delay bottle must not be released/visible yet")
 	}
+	if plan.Language.Config == "" {
+		t.Fatal("Cast must choose a search config for the Message")
+	}
 
 	minAt := now.Add(15 * time.Minute)
 	maxAt := now.Add(30 * time.Minute)
@@ -89,3 +93,43 @@ func TestCastSanitizesMessageHTML(t *testing.T) {
 		t.Fatal("sanitized message empty")
 	}
 }
+
+func TestCastAppliesUnicodeHygiene(t *testing.T) {
+	now := time.Date(2026, 7, 22, 12, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+	lat, lng := 30.0, -140.0
+
+	// 24 family emoji are 24 characters on screen, though 168 runes.
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467\u200d\U0001F466"
+	plan, err := cast.Prepare(strings.Repeat(family, 24), "gu\u200bll \u202ecalls\u202c", &lat, &lng, now, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.MessageText != "gull calls" {
+		t.Fatalf("invisible and bidi characters kept: %q", plan.MessageText)
+	}
+
+	rejects := []struct {
+		nickname, message string
+		want              error
+	}{
+		{"Осеаlis", "hi", cast.ErrNicknameReserved},
+		{"\u200b\u3164", "hi", cast.ErrNicknameRequired},
+		{"sail\nor", "hi", cast.ErrControlCharacters},
+		{"sailor", "\u200b\u2066\u2069", cast.ErrMessageRequired},
+		{"sailor", "hi\x1b[2J", cast.ErrControlCharacters},
+	}
+	for _, tc := range rejects {
+		if _, err := cast.Prepare(tc.nickname, tc.message, &lat, &lng, now, rng); !errors.Is(err, tc.want) {
+			t.Errorf("Prepare(%q, %q) err = %v, want %v", tc.nickname, tc.message, err, tc.want)
+		}
+	}
+
+	note, err := cast.CleanNote("  found at dawn\r\n\u202e  ")
+	if err != nil || note != "found at dawn" {
+		t.Fatalf("CleanNote = %q, %v", note, err)
+	}
+	if _, err := cast.CleanNote(strings.Repeat("n", cast.MaxNoteGraphemes+1)); !errors.Is(err, cast.ErrNoteTooLong) {
+		t.Fatalf("long note err = %v", err)
+	}
+}
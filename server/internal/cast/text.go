@@ -0,0 +1,85 @@
+package cast
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+	"github.com/Polqt/ocealis/util"
+)
+
+// Limits count user-perceived characters (hygiene.Graphemes), so a flag or a family
+// emoji costs one, the same as on the reader's screen.
+const (
+	MaxNicknameGraphemes = 24
+	MaxMessageGraphemes  = 500
+	MaxNoteGraphemes     = 280
+)
+
+var (
+	ErrNicknameReserved = errors.New("nickname is reserved")
+	ErrNoteTooLong      = errors.New("note must be ≤280 characters")
+	// ErrControlCharacters is hygiene.ErrControlCharacter, for callers matching cast errors.
+	ErrControlCharacters = hygiene.ErrControlCharacter
+)
+
+// ReservedNicknames sign Seed Bottles and staff posts. Nicknames that look like one
+// of them — "0cealis", "Осеаlis" in Cyrillic, "Ｏｃｅａｌｉｓ" — are refused.
+var ReservedNicknames = []string{"Ocealis", "admin", "moderator", "system"}
+
+// CleanNickname applies Unicode hygiene to a Nickname: one line, NFC, no invisible
+// or bidi characters, ≤MaxNicknameGraphemes, not confusable with ReservedNicknames.
+// An empty result is returned as is; callers decide whether a Nickname is required.
+func CleanNickname(nickname string) (string, error) {
+	nickname, err := hygiene.Clean(nickname, hygiene.Line)
+	if err != nil {
+		return "", err
+	}
+	nickname = strings.TrimSpace(nickname)
+	if hygiene.Blank(nickname) {
+		return "", nil
+	}
+	if hygiene.Graphemes(nickname) > MaxNicknameGraphemes {
+		return "", ErrNicknameTooLong
+	}
+	for _, reserved := range ReservedNicknames {
+		if hygiene.Confusable(nickname, reserved) {
+			return "", ErrNicknameReserved
+		}
+	}
+	return nickname, nil
+}
+
+// CleanMessage strips HTML from a Message and applies Unicode hygiene. Line breaks
+// survive; a Message with nothing visible left is ErrMessageRequired.
+func CleanMessage(message string) (string, error) {
+	message, err := hygiene.Clean(util.SanitizeMessage(message), hygiene.Multiline)
+	if err != nil {
+		return "", err
+	}
+	message = strings.TrimSpace(message)
+	if hygiene.Blank(message) {
+		return "", ErrMessageRequired
+	}
+	if hygiene.Graphemes(message) > MaxMessageGraphemes {
+		return "", ErrMessageTooLong
+	}
+	return message, nil
+}
+
+// CleanNote applies Unicode hygiene to a Stamp note. Notes are optional, so a blank
+// one comes back empty rather than as an error.
+func CleanNote(note string) (string, error) {
+	note, err := hygiene.Clean(util.SanitizeMessage(note), hygiene.Multiline)
+	if err != nil {
+		return "", err
+	}
+	note = strings.TrimSpace(note)
+	if hygiene.Blank(note) {
+		return "", nil
+	}
+	if hygiene.Graphemes(note) > MaxNoteGraphemes {
+		return "", ErrNoteTooLong
+	}
+	return note, nil
+}
@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"math"
+	"sync"
+
+	"github.com/Polqt/ocealis/internal/lru"
+	"github.com/Polqt/ocealis/internal/metrics"
+)
+
+// DefaultMapCacheSize sizes a MapCache asked for a non-positive size.
+const DefaultMapCacheSize = 256
+
+// MapKey identifies one cached map response: a viewport snapped to the zoom bucket's
+// grid, and the Ocean generation it was computed at.
+type MapKey struct {
+	Generation int32
+	// ZoomBucket is the whole zoom level; CorkZoomMin is whole, so a bucket is all
+	// heat or all Corks.
+	ZoomBucket int
+	// Grid cell indices of the snapped viewport edges.
+	MinLat, MaxLat, MinLng, MaxLng int
+}
+
+// SnapStep is the grid a viewport snaps to at a zoom bucket: heat cells below
+// CorkZoomMin, a quarter of a map tile's width above it.
+func SnapStep(zoomBucket int) float64 {
+	if float64(zoomBucket) < CorkZoomMin {
+		return HeatCellDeg
+	}
+	return 360 / math.Exp2(float64(zoomBucket+2))
+}
+
+// Snap widens vp outward to the grid of zoom's bucket and returns its key (Generation
+// left for the caller) with the widened viewport. Pans within one grid cell share a key,
+// and the widened viewport is what a cached response covers.
+func Snap(zoom float64, vp Viewport) (MapKey, Viewport) {
+	bucket := int(math.Floor(zoom))
+	step := SnapStep(bucket)
+	key := MapKey{
+		ZoomBucket: bucket,
+		MinLat:     int(math.Floor(vp.MinLat / step)),
+		MaxLat:     int(math.Ceil(vp.MaxLat / step)),
+		MinLng:     int(math.Floor(vp.MinLng / step)),
+		MaxLng:     int(math.Ceil(vp.MaxLng / step)),
+	}
+	return key, Viewport{
+		MinLat: math.Max(-90, float64(key.MinLat)*step),
+		MaxLat: math.Min(90, float64(key.MaxLat)*step),
+		MinLng: math.Max(-180, float64(key.MinLng)*step),
+		MaxLng: math.Min(180, float64(key.MaxLng)*step),
+	}
+}
+
+// MapCache keeps the most recently served map responses in memory. Entries are only
+// good for the generation they were computed at: a lookup at a newer generation drops
+// every older entry, so a drift tick, release, Re-release or Sink — anything that
+// writes a Journey event — invalidates the cache with no hook to call.
+type MapCache struct {
+	mu         sync.Mutex // orders generation changes against lookups
+	generation int32
+	entries    *lru.Cache[MapKey, MapResult]
+}
+
+func NewMapCache(size int) *MapCache {
+	if size <= 0 {
+		size = DefaultMapCacheSize
+	}
+	return &MapCache{entries: lru.New[MapKey, MapResult](size)}
+}
+
+// Get returns the cached response for key, counting the lookup as a hit or miss.
+func (c *MapCache) Get(key MapKey) (MapResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(key.Generation)
+	result, ok := c.entries.Get(key)
+	metrics.ObserveMapCache(ok)
+	return result, ok
+}
+
+// Put stores result under key, evicting the least recently used entry when full.
+// A result for an older generation than the cache has seen is dropped.
+func (c *MapCache) Put(key MapKey, result MapResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(key.Generation)
+	if key.Generation < c.generation {
+		return
+	}
+	c.entries.Add(key, result)
+}
+
+// Len is how many responses are cached.
+func (c *MapCache) Len() int {
+	return c.entries.Len()
+}
+
+// advance forgets every entry once the Ocean has moved past their generation.
+// Caller holds mu.
+func (c *MapCache) advance(generation int32) {
+	if generation <= c.generation {
+		return
+	}
+	c.generation = generation
+	c.entries.Clear()
+}
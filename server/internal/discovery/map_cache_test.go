@@ -0,0 +1,74 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSnapSharesKeysWithinACellAndWidensOutward(t *testing.T) {
+	vp := discovery.Viewport{MinLat: 10.3, MaxLat: 20.1, MinLng: -40.7, MaxLng: -30.2}
+	key, wide := discovery.Snap(6.2, vp)
+	if wide.MinLat > vp.MinLat || wide.MaxLat < vp.MaxLat || wide.MinLng > vp.MinLng || wide.MaxLng < vp.MaxLng {
+		t.Fatalf("snapped %+v does not cover %+v", wide, vp)
+	}
+	step := discovery.SnapStep(6)
+	if wide.MaxLat-vp.MaxLat >= step || vp.MinLng-wide.MinLng >= step {
+		t.Fatalf("snapped %+v widened by more than one %.3f° step", wide, step)
+	}
+
+	panned, _ := discovery.Snap(6.9, discovery.Viewport{MinLat: 10.35, MaxLat: 20.12, MinLng: -40.6, MaxLng: -30.25})
+	if panned != key {
+		t.Fatalf("a small pan at the same whole zoom must share the key: %+v vs %+v", panned, key)
+	}
+	if zoomed, _ := discovery.Snap(7, vp); zoomed == key {
+		t.Fatal("another zoom bucket must not share the key")
+	}
+
+	_, world := discovery.Snap(2, discovery.Viewport{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180})
+	if world != (discovery.Viewport{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}) {
+		t.Fatalf("world viewport must clamp, got %+v", world)
+	}
+}
+
+func TestMapCacheEvictsLeastRecentAndForgetsOldGenerations(t *testing.T) {
+	hits := func() float64 { return testutil.ToFloat64(metrics.MapCacheLookups.WithLabelValues("hit")) }
+	misses := func() float64 { return testutil.ToFloat64(metrics.MapCacheLookups.WithLabelValues("miss")) }
+	hits0, misses0 := hits(), misses()
+
+	cache := discovery.NewMapCache(2)
+	a := discovery.MapKey{Generation: 1, ZoomBucket: 3, MaxLat: 1}
+	b := discovery.MapKey{Generation: 1, ZoomBucket: 3, MaxLat: 2}
+	c := discovery.MapKey{Generation: 1, ZoomBucket: 3, MaxLat: 3}
+	cache.Put(a, discovery.MapResult{Mode: "heat"})
+	cache.Put(b, discovery.MapResult{Mode: "heat"})
+	if _, ok := cache.Get(a); !ok {
+		t.Fatal("want a cached")
+	}
+	cache.Put(c, discovery.MapResult{Mode: "corks"}) // evicts b, the least recent
+	if _, ok := cache.Get(b); ok {
+		t.Fatal("b should have been evicted")
+	}
+	if got, ok := cache.Get(c); !ok || got.Mode != "corks" {
+		t.Fatalf("c = %+v, %v", got, ok)
+	}
+	if hits()-hits0 != 2 || misses()-misses0 != 1 {
+		t.Fatalf("hits %+v misses %+v", hits()-hits0, misses()-misses0)
+	}
+
+	// The Ocean moved: a lookup at the new generation drops everything older.
+	next := a
+	next.Generation = 2
+	if _, ok := cache.Get(next); ok || cache.Len() != 0 {
+		t.Fatalf("new generation must start empty, have %d entries", cache.Len())
+	}
+	cache.Put(a, discovery.MapResult{Mode: "heat"}) // a late result from generation 1
+	if cache.Len() != 0 {
+		t.Fatal("results from an older generation must not be stored")
+	}
+	if r := testutil.ToFloat64(metrics.MapCacheHitRatio); r <= 0 || r >= 1 {
+		t.Fatalf("hit ratio %v", r)
+	}
+}
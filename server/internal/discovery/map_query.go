@@ -64,7 +64,7 @@ func QueryOcean(zoom float64, vp Viewport, bottles []domain.Bottle) MapResult {
 			ID:     b.ID,
 			Lat:    b.CurrentLat,
 			Lng:    b.CurrentLng,
-			IsSeed: b.BottleStyle == SeedStyle,
+			IsSeed: b.IsSeed,
 		})
 	}
 	return MapResult{Mode: "corks", Corks: corks}
@@ -4,12 +4,9 @@ import (
 	"time"
 
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/styles"
 )
 
-// SeedStyle marks a Seed Bottle via bottle_style (no schema column yet).
-// Issue 07 may promote to is_seed + Sink exemption.
-const SeedStyle int32 = 9
-
 // Seeds returns always-visible Seed Bottles so the Ocean is never empty.
 // Negative IDs avoid colliding with Postgres serial until seeds land in DB.
 func Seeds() []domain.Bottle {
@@ -28,7 +25,7 @@ func seed(id int32, nick, msg string, lat, lng float64, visibleAt time.Time) dom
 		ID:          id,
 		Nickname:    nick,
 		MessageText: msg,
-		BottleStyle: SeedStyle,
+		BottleStyle: styles.Seed,
 		StartLat:    lat,
 		StartLng:    lng,
 		CurrentLat:  lat,
@@ -37,5 +34,6 @@ func seed(id int32, nick, msg string, lat, lng float64, visibleAt time.Time) dom
 		IsReleased:  true,
 		VisibleAt:   visibleAt,
 		CreatedAt:   visibleAt,
+		IsSeed:      true,
 	}
 }
@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"math/rand"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+// BasinPreference multiplies a Candidate's weight when it drifts in the caller's basin.
+const BasinPreference = 4.0
+
+// Candidate is one Cork drawn from the sampling index for a serendipity pick.
+type Candidate struct {
+	Bottle domain.Bottle
+	// Basin is the geo.Basin the Cork drifts in.
+	Basin string
+	// ReReleases and Stamps are how often the Bottle was found and marked so far.
+	ReReleases int32
+	Stamps     int32
+}
+
+// Weight favours quiet Bottles: each Re-release or Stamp shrinks the odds, so a Cork
+// nobody has answered yet is the likeliest find. Opens leave no trace (Open is
+// read-only), so Re-releases — the one Open that writes — stand in for them.
+func Weight(c Candidate, basin string) float64 {
+	w := 1 / float64(1+c.ReReleases+c.Stamps)
+	if basin != "" && c.Basin == basin {
+		w *= BasinPreference
+	}
+	return w
+}
+
+// Pick draws one Candidate with probability proportional to its Weight.
+// It reports false when there is nothing to pick.
+func Pick(candidates []Candidate, basin string, rng *rand.Rand) (Candidate, bool) {
+	total := 0.0
+	for _, c := range candidates {
+		total += Weight(c, basin)
+	}
+	if total <= 0 {
+		return Candidate{}, false
+	}
+	x := rng.Float64() * total
+	for _, c := range candidates {
+		if x -= Weight(c, basin); x < 0 {
+			return c, true
+		}
+	}
+	return candidates[len(candidates)-1], true // float rounding left x at ~0
+}
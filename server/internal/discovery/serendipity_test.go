@@ -0,0 +1,51 @@
+package discovery_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+func TestWeightFavoursQuietBottlesAndCallerBasin(t *testing.T) {
+	quiet := discovery.Candidate{Basin: "north_pacific"}
+	busy := discovery.Candidate{Basin: "north_pacific", ReReleases: 1, Stamps: 2}
+	if discovery.Weight(quiet, "") != 1 || discovery.Weight(busy, "") != 0.25 {
+		t.Fatalf("weights quiet=%v busy=%v", discovery.Weight(quiet, ""), discovery.Weight(busy, ""))
+	}
+	if got := discovery.Weight(quiet, "north_pacific"); got != discovery.BasinPreference {
+		t.Fatalf("same-basin weight %v, want %v", got, discovery.BasinPreference)
+	}
+	if got := discovery.Weight(quiet, "indian_ocean"); got != 1 {
+		t.Fatalf("other-basin weight %v, want 1", got)
+	}
+}
+
+func TestPickIsProportionalToWeight(t *testing.T) {
+	candidates := []discovery.Candidate{
+		{Bottle: domain.Bottle{ID: 1}, Basin: "indian_ocean"},
+		{Bottle: domain.Bottle{ID: 2}, Basin: "indian_ocean", Stamps: 3},
+		{Bottle: domain.Bottle{ID: 3}, Basin: "north_atlantic", Stamps: 3},
+	}
+	// Weights with the caller in the Indian Ocean: 4, 1, 0.25.
+	rng := rand.New(rand.NewSource(1))
+	counts := map[int32]int{}
+	const draws = 52000
+	for range draws {
+		c, ok := discovery.Pick(candidates, "indian_ocean", rng)
+		if !ok {
+			t.Fatal("no pick from a non-empty sample")
+		}
+		counts[c.Bottle.ID]++
+	}
+	for id, want := range map[int32]float64{1: 4 / 5.25, 2: 1 / 5.25, 3: 0.25 / 5.25} {
+		if got := float64(counts[id]) / draws; got < want*0.9 || got > want*1.1 {
+			t.Fatalf("bottle %d drawn %.3f of the time, want about %.3f", id, got, want)
+		}
+	}
+
+	if _, ok := discovery.Pick(nil, "", rng); ok {
+		t.Fatal("picked from an empty sample")
+	}
+}
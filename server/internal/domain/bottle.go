@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // BottleStatus is the Bottle life-cycle status (CONTEXT.md / PRD).
 type BottleStatus string
@@ -9,8 +13,7 @@ const (
 	// BottleStatusDrifting — visible Cork in the Ocean.
 	BottleStatusDrifting BottleStatus = "drifting"
 	// BottleStatusMysteryDelay — invisible after Cast/Re-release until VisibleAt.
-	// Wire/DB value still "scheduled" until column migration (deferred).
-	BottleStatusMysteryDelay BottleStatus = "scheduled"
+	BottleStatusMysteryDelay BottleStatus = "mystery_delay"
 	// BottleStatusSunk — left the world after Sink (stub until issue 07).
 	BottleStatusSunk BottleStatus = "sunk"
 	// BottleStatusClaimed — legacy claim status. Open must not set this (issue 03).
@@ -18,12 +21,37 @@ const (
 	BottleStatusClaimed BottleStatus = "discovered"
 )
 
-// Deprecated aliases — prefer glossary names above.
-const (
-	BottleStatusDiscovered = BottleStatusClaimed
-	BottleStatusScheduled  = BottleStatusMysteryDelay
-	BottleStatusReleased   BottleStatus = "released" // unused life status; Cast is an event
-)
+// legacyStatuses maps wire_version 1 spellings to glossary names.
+var legacyStatuses = map[BottleStatus]BottleStatus{
+	"scheduled": BottleStatusMysteryDelay,
+}
+
+// ParseBottleStatus accepts current and wire_version 1 spellings.
+func ParseBottleStatus(s string) BottleStatus {
+	if current, ok := legacyStatuses[BottleStatus(s)]; ok {
+		return current
+	}
+	return BottleStatus(s)
+}
+
+func (s *BottleStatus) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = ParseBottleStatus(raw)
+	return nil
+}
+
+// Legacy is the wire_version 1 spelling of s.
+func (s BottleStatus) Legacy() BottleStatus {
+	for old, current := range legacyStatuses {
+		if current == s {
+			return old
+		}
+	}
+	return s
+}
 
 type Bottle struct {
 	ID          int32        `json:"id"`
@@ -37,13 +65,43 @@ type Bottle struct {
 	CurrentLng  float64      `json:"current_lng"`
 	Hops        int32        `json:"hops"`
 	// VisibleAt is Mystery Delay end — Cork appears after this instant.
-	// Maps from DB scheduled_release until rename.
-	VisibleAt time.Time `json:"visible_at"`
-	// ScheduledRelease kept for older clients; same instant as VisibleAt.
-	ScheduledRelease time.Time    `json:"scheduled_release,omitempty"`
-	IsReleased       bool         `json:"is_released"`
-	Status           BottleStatus `json:"status"`
-	CreatedAt        time.Time    `json:"created_at"`
+	VisibleAt  time.Time    `json:"visible_at"`
+	IsReleased bool         `json:"is_released"`
+	Status     BottleStatus `json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+	// IsSeed marks an Ocealis Seed Bottle; Seeds live in code, never in the database.
+	IsSeed     bool         `json:"is_seed,omitempty"`
+}
+
+// MarshalJSON stamps wire_version so clients can tell which vocabulary they got.
+func (b Bottle) MarshalJSON() ([]byte, error) {
+	type plain Bottle
+	return json.Marshal(struct {
+		plain
+		WireVersion int `json:"wire_version"`
+	}{plain(b), WireVersion})
+}
+
+// MarshalBottleWith renders b as its MarshalJSON does plus the fields of extra, a struct.
+// Types that embed Bottle and add fields need it: Bottle's MarshalJSON is promoted
+// through the embedding and would render the Bottle alone.
+func MarshalBottleWith(b Bottle, extra any) ([]byte, error) {
+	base, err := b.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := json.Marshal(extra)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 || fields[0] != '{' {
+		return nil, fmt.Errorf("extra Bottle fields must be an object, got %s", fields)
+	}
+	if len(fields) == 2 {
+		return base, nil
+	}
+	out := append(base[:len(base)-1:len(base)-1], ',')
+	return append(out, fields[1:]...), nil
 }
 
 type Journey struct {
@@ -1,14 +1,16 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // EventType is a Journey event (CONTEXT.md).
 type EventType string
 
 const (
 	// EventTypeCast — Visitor Cast a Bottle into the Ocean.
-	// Wire value still "released" until event_type migration (deferred).
-	EventTypeCast EventType = "released"
+	EventTypeCast  EventType = "cast"
 	EventTypeDrift EventType = "drift"
 	// EventTypeStamp — passport seal/note on Journey (stub until issue 04).
 	EventTypeStamp EventType = "stamp"
@@ -21,11 +23,37 @@ const (
 	EventTypeOpenedLegacy EventType = "discovered"
 )
 
-// Deprecated aliases — prefer glossary names above.
-const (
-	EventTypeReleased   = EventTypeCast
-	EventTypeDiscovered = EventTypeOpenedLegacy
-)
+// legacyEventTypes maps wire_version 1 spellings to glossary names.
+var legacyEventTypes = map[EventType]EventType{
+	"released": EventTypeCast,
+}
+
+// ParseEventType accepts current and wire_version 1 spellings.
+func ParseEventType(s string) EventType {
+	if current, ok := legacyEventTypes[EventType(s)]; ok {
+		return current
+	}
+	return EventType(s)
+}
+
+func (t *EventType) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = ParseEventType(raw)
+	return nil
+}
+
+// Legacy is the wire_version 1 spelling of t.
+func (t EventType) Legacy() EventType {
+	for old, current := range legacyEventTypes {
+		if current == t {
+			return old
+		}
+	}
+	return t
+}
 
 type BottleEvent struct {
 	ID        int32     `json:"id"`
@@ -34,4 +62,33 @@ type BottleEvent struct {
 	Lat       float64   `json:"lat"`
 	Lng       float64   `json:"lng"`
 	CreatedAt time.Time `json:"created_at"`
+	// Payload is the typed detail for EventType; nil on events from before payloads.
+	Payload EventPayload `json:"payload,omitempty"`
+}
+
+func (e BottleEvent) MarshalJSON() ([]byte, error) {
+	type plain BottleEvent
+	return json.Marshal(struct {
+		plain
+		WireVersion int `json:"wire_version"`
+	}{plain(e), WireVersion})
+}
+
+// UnmarshalJSON decodes payload into the struct its event_type calls for.
+func (e *BottleEvent) UnmarshalJSON(data []byte) error {
+	type plain BottleEvent
+	var raw struct {
+		plain
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	payload, err := UnmarshalEventPayload(raw.EventType, raw.Payload)
+	if err != nil {
+		return err
+	}
+	*e = BottleEvent(raw.plain)
+	e.Payload = payload
+	return nil
 }
@@ -1,21 +1,24 @@
 package domain_test
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Polqt/ocealis/internal/domain"
 )
 
 func TestBottleLifeStatusesUseGlossaryWireValues(t *testing.T) {
-	// Product names map to wire/DB strings (rename deferred where noted).
+	// Product names map to wire/DB strings.
 	cases := []struct {
 		status domain.BottleStatus
 		wire   string
 	}{
 		{domain.BottleStatusDrifting, "drifting"},
-		{domain.BottleStatusMysteryDelay, "scheduled"}, // Mystery Delay
-		{domain.BottleStatusSunk, "sunk"},              // Sink stub
-		{domain.BottleStatusClaimed, "discovered"},     // legacy claim
+		{domain.BottleStatusMysteryDelay, "mystery_delay"},
+		{domain.BottleStatusSunk, "sunk"},          // Sink stub
+		{domain.BottleStatusClaimed, "discovered"}, // legacy claim
 	}
 	for _, tc := range cases {
 		if string(tc.status) != tc.wire {
@@ -29,7 +32,7 @@ func TestJourneyEventTypesCoverCastStampReReleaseSink(t *testing.T) {
 		event domain.EventType
 		wire  string
 	}{
-		{domain.EventTypeCast, "released"}, // Cast; wire rename deferred
+		{domain.EventTypeCast, "cast"},
 		{domain.EventTypeDrift, "drift"},
 		{domain.EventTypeStamp, "stamp"},
 		{domain.EventTypeReReleased, "re_released"},
@@ -41,3 +44,37 @@ func TestJourneyEventTypesCoverCastStampReReleaseSink(t *testing.T) {
 		}
 	}
 }
+
+func TestWireV1SpellingsStillParse(t *testing.T) {
+	var in struct {
+		Status    domain.BottleStatus `json:"status"`
+		EventType domain.EventType    `json:"event_type"`
+	}
+	if err := json.Unmarshal([]byte(`{"status":"scheduled","event_type":"released"}`), &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Status != domain.BottleStatusMysteryDelay || in.EventType != domain.EventTypeCast {
+		t.Fatalf("legacy spellings not normalized: %+v", in)
+	}
+	if domain.BottleStatusMysteryDelay.Legacy() != "scheduled" || domain.EventTypeCast.Legacy() != "released" {
+		t.Fatal("want Legacy() to return the wire_version 1 spelling")
+	}
+	if domain.BottleStatusDrifting.Legacy() != domain.BottleStatusDrifting {
+		t.Fatal("unrenamed values must pass through Legacy()")
+	}
+}
+
+func TestBottleJSONCarriesWireVersion(t *testing.T) {
+	out, err := json.Marshal(domain.Bottle{ID: 1, Status: domain.BottleStatusMysteryDelay, VisibleAt: time.Unix(0, 0).UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"wire_version":2`, `"status":"mystery_delay"`, `"visible_at":"1970-01-01T00:00:00Z"`} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("want %s in %s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "scheduled_release") {
+		t.Fatalf("scheduled_release is wire_version 1 only: %s", out)
+	}
+}
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+)
+
+// Payload text limits count graphemes, matching the limits cast applies on input.
+const (
+	maxPayloadNicknameGraphemes = 24
+	maxSealGraphemes            = 32
+	maxStampNoteGraphemes       = 280
+)
+
+var ErrInvalidEventPayload = errors.New("invalid event payload")
+
+// EventPayload is the typed detail carried by one Journey event.
+// EventType is the discriminator: each EventType has exactly one payload struct.
+type EventPayload interface {
+	EventType() EventType
+	Validate() error
+}
+
+// CastPayload — who Cast the Bottle (or whose Mystery Delay just ended).
+type CastPayload struct {
+	Nickname    string `json:"nickname"`
+	BottleStyle int32  `json:"bottle_style"`
+}
+
+// DriftPayload — the current that moved the Bottle this tick. Compacted daily
+// waypoints set Ticks to how many ticks they stand for and sum DistanceKm over them.
+type DriftPayload struct {
+	BearingDeg float64 `json:"bearing_deg"`
+	SpeedKmH   float64 `json:"speed_kmh"`
+	DistanceKm float64 `json:"distance_km"`
+	Ticks      int     `json:"ticks,omitempty"`
+}
+
+// StampPayload — passport seal and optional note left by a finder.
+type StampPayload struct {
+	Nickname string `json:"nickname"`
+	Seal     string `json:"seal"`
+	Note     string `json:"note,omitempty"`
+}
+
+// ReReleasePayload — the finder who sent the Bottle back out from their Shoreline.
+type ReReleasePayload struct {
+	Nickname string `json:"nickname,omitempty"`
+}
+
+// SinkReason says why a Bottle left the world.
+type SinkReason string
+
+const (
+	SinkReasonBeached   SinkReason = "beached"
+	SinkReasonExpired   SinkReason = "expired"
+	SinkReasonModerated SinkReason = "moderated"
+)
+
+// SinkPayload — why the Bottle sank.
+type SinkPayload struct {
+	Reason SinkReason `json:"reason"`
+}
+
+func (CastPayload) EventType() EventType      { return EventTypeCast }
+func (DriftPayload) EventType() EventType     { return EventTypeDrift }
+func (StampPayload) EventType() EventType     { return EventTypeStamp }
+func (ReReleasePayload) EventType() EventType { return EventTypeReReleased }
+func (SinkPayload) EventType() EventType      { return EventTypeSink }
+
+func (p CastPayload) Validate() error {
+	return checkNickname(p.Nickname)
+}
+
+func (p DriftPayload) Validate() error {
+	if p.BearingDeg < 0 || p.BearingDeg >= 360 {
+		return fmt.Errorf("%w: bearing %.2f outside [0, 360)", ErrInvalidEventPayload, p.BearingDeg)
+	}
+	if p.SpeedKmH < 0 || p.DistanceKm < 0 || p.Ticks < 0 {
+		return fmt.Errorf("%w: negative drift speed, distance or ticks", ErrInvalidEventPayload)
+	}
+	return nil
+}
+
+func (p StampPayload) Validate() error {
+	if err := checkNickname(p.Nickname); err != nil {
+		return err
+	}
+	if err := checkText("seal", p.Seal, hygiene.Line); err != nil {
+		return err
+	}
+	if p.Seal == "" || hygiene.Graphemes(p.Seal) > maxSealGraphemes {
+		return fmt.Errorf("%w: seal must be 1-%d characters", ErrInvalidEventPayload, maxSealGraphemes)
+	}
+	if err := checkText("note", p.Note, hygiene.Multiline); err != nil {
+		return err
+	}
+	if hygiene.Graphemes(p.Note) > maxStampNoteGraphemes {
+		return fmt.Errorf("%w: note must be ≤%d characters", ErrInvalidEventPayload, maxStampNoteGraphemes)
+	}
+	return nil
+}
+
+func (p ReReleasePayload) Validate() error {
+	return checkNickname(p.Nickname)
+}
+
+func (p SinkPayload) Validate() error {
+	switch p.Reason {
+	case SinkReasonBeached, SinkReasonExpired, SinkReasonModerated:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown sink reason %q", ErrInvalidEventPayload, p.Reason)
+}
+
+func checkNickname(nickname string) error {
+	if err := checkText("nickname", nickname, hygiene.Line); err != nil {
+		return err
+	}
+	if hygiene.Graphemes(nickname) > maxPayloadNicknameGraphemes {
+		return fmt.Errorf("%w: nickname must be ≤%d characters", ErrInvalidEventPayload, maxPayloadNicknameGraphemes)
+	}
+	return nil
+}
+
+// checkText refuses payload text that has not been through hygiene.Clean (cast's
+// Clean* functions): control characters, invisible or bidi formatting, non-NFC forms.
+func checkText(field, s string, mode hygiene.Mode) error {
+	clean, err := hygiene.Clean(s, mode)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidEventPayload, field, err)
+	}
+	if clean != s {
+		return fmt.Errorf("%w: %s has invisible, bidi or unnormalized characters", ErrInvalidEventPayload, field)
+	}
+	return nil
+}
+
+// newPayload returns an empty payload for t, or nil for types without one
+// (the legacy "discovered" claim).
+func newPayload(t EventType) EventPayload {
+	switch t {
+	case EventTypeCast:
+		return &CastPayload{}
+	case EventTypeDrift:
+		return &DriftPayload{}
+	case EventTypeStamp:
+		return &StampPayload{}
+	case EventTypeReReleased:
+		return &ReReleasePayload{}
+	case EventTypeSink:
+		return &SinkPayload{}
+	}
+	return nil
+}
+
+// MarshalEventPayload checks p belongs to t and is valid, then encodes it for storage.
+// A nil payload stores as {}.
+func MarshalEventPayload(t EventType, p EventPayload) ([]byte, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	if p.EventType() != t {
+		return nil, fmt.Errorf("%w: %T on a %q event", ErrInvalidEventPayload, p, t)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(p)
+}
+
+// UnmarshalEventPayload decodes a stored payload strictly into t's struct.
+// Empty payloads (rows from before payloads existed) decode to nil.
+func UnmarshalEventPayload(t EventType, raw []byte) (EventPayload, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("{}")) || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+	p := newPayload(t)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %q events carry no payload", ErrInvalidEventPayload, t)
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEventPayload, err)
+	}
+	// Hand back the value type so callers can type-switch on CastPayload, DriftPayload, ...
+	switch v := p.(type) {
+	case *CastPayload:
+		return *v, v.Validate()
+	case *DriftPayload:
+		return *v, v.Validate()
+	case *StampPayload:
+		return *v, v.Validate()
+	case *ReReleasePayload:
+		return *v, v.Validate()
+	case *SinkPayload:
+		return *v, v.Validate()
+	}
+	return p, p.Validate()
+}
@@ -0,0 +1,95 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/cast"
+	"github.com/Polqt/ocealis/internal/cast/hygiene"
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+func TestEventPayloadRoundTripsByEventType(t *testing.T) {
+	stamp := domain.StampPayload{Nickname: "gull", Seal: "anchor", Note: "found it at dawn"}
+	raw, err := domain.MarshalEventPayload(domain.EventTypeStamp, stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := domain.UnmarshalEventPayload(domain.EventTypeStamp, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != stamp {
+		t.Fatalf("round trip = %#v, want %#v", got, stamp)
+	}
+
+	// Journey JSON is discriminated by event_type.
+	body, err := json.Marshal(domain.BottleEvent{ID: 3, EventType: domain.EventTypeDrift, Payload: domain.DriftPayload{BearingDeg: 90, SpeedKmH: 1.2, DistanceKm: 0.3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"payload":{"bearing_deg":90,"speed_kmh":1.2,"distance_km":0.3}`) {
+		t.Fatalf("payload missing from %s", body)
+	}
+	var decoded domain.BottleEvent
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded.Payload.(domain.DriftPayload); !ok {
+		t.Fatalf("want DriftPayload, got %T", decoded.Payload)
+	}
+}
+
+func TestEventPayloadRejectsMismatchAndInvalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		event   domain.EventType
+		payload domain.EventPayload
+	}{
+		{"wrong type", domain.EventTypeCast, domain.SinkPayload{Reason: domain.SinkReasonBeached}},
+		{"empty seal", domain.EventTypeStamp, domain.StampPayload{Nickname: "gull"}},
+		{"bearing out of range", domain.EventTypeDrift, domain.DriftPayload{BearingDeg: 400}},
+		{"unknown sink reason", domain.EventTypeSink, domain.SinkPayload{Reason: "bored"}},
+		{"long nickname", domain.EventTypeReReleased, domain.ReReleasePayload{Nickname: strings.Repeat("n", 25)}},
+	}
+	for _, tc := range cases {
+		if _, err := domain.MarshalEventPayload(tc.event, tc.payload); !errors.Is(err, domain.ErrInvalidEventPayload) {
+			t.Errorf("%s: want ErrInvalidEventPayload, got %v", tc.name, err)
+		}
+	}
+
+	for name, p := range map[string]domain.StampPayload{
+		"bidi override in nickname": {Nickname: "gull\u202egnp.exe", Seal: "🐚"},
+		"zero-width seal":           {Nickname: "gull", Seal: "🐚\u200b"},
+		"decomposed note":           {Nickname: "gull", Seal: "🐚", Note: "cafe\u0301"},
+	} {
+		if _, err := domain.MarshalEventPayload(domain.EventTypeStamp, p); !errors.Is(err, domain.ErrInvalidEventPayload) {
+			t.Errorf("%s: want ErrInvalidEventPayload, got %v", name, err)
+		}
+	}
+	bell := domain.StampPayload{Nickname: "gull", Seal: "🐚", Note: "ring\a"}
+	if _, err := domain.MarshalEventPayload(domain.EventTypeStamp, bell); !errors.Is(err, hygiene.ErrControlCharacter) {
+		t.Errorf("control character in note: want ErrControlCharacter, got %v", err)
+	}
+	if _, err := domain.UnmarshalEventPayload(domain.EventTypeStamp, []byte(`{"nickname":"gull","seal":"\u202e🐚"}`)); !errors.Is(err, domain.ErrInvalidEventPayload) {
+		t.Errorf("stored bidi seal: want ErrInvalidEventPayload, got %v", err)
+	}
+
+	// Text through cast's hygiene is what payloads accept.
+	note, err := cast.CleanNote("  cafe\u0301 \u200b\r\nsee you  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := domain.MarshalEventPayload(domain.EventTypeStamp, domain.StampPayload{Nickname: "gull", Seal: "🐚", Note: note}); err != nil {
+		t.Errorf("cleaned note %q: %v", note, err)
+	}
+
+	if _, err := domain.UnmarshalEventPayload(domain.EventTypeSink, []byte(`{"reason":"beached","depth":3}`)); err == nil {
+		t.Error("want unknown payload fields rejected")
+	}
+	if p, err := domain.UnmarshalEventPayload(domain.EventTypeCast, []byte(`{}`)); p != nil || err != nil {
+		t.Errorf("rows from before payloads should read as nil, got %v, %v", p, err)
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// IdempotentResponse is a stored mutation outcome replayed for a retried Idempotency-Key.
+// StatusCode 0 means the original request is still in flight.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	Body        []byte
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"maps"
+	"time"
+)
+
+// OceanStats is the splash globe's headline numbers (PRD US1), read from counters the
+// database keeps current rather than counted per request.
+type OceanStats struct {
+	Drifting     int64 `json:"drifting"`
+	MysteryDelay int64 `json:"mystery_delay"`
+	Sunk         int64 `json:"sunk"`
+	// Casts24h and Stamps24h count whole hours back to roughly a day ago.
+	Casts24h  int64   `json:"casts_24h"`
+	Stamps24h int64   `json:"stamps_24h"`
+	KmDrifted float64 `json:"km_drifted"`
+	// Basins counts drifting Bottles per geo.Basin; every basin is present, even at zero.
+	Basins map[string]int64 `json:"basins"`
+}
+
+// Equal reports whether s and o would render the same.
+func (s *OceanStats) Equal(o *OceanStats) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return s.Drifting == o.Drifting && s.MysteryDelay == o.MysteryDelay && s.Sunk == o.Sunk &&
+		s.Casts24h == o.Casts24h && s.Stamps24h == o.Stamps24h && s.KmDrifted == o.KmDrifted &&
+		maps.Equal(s.Basins, o.Basins)
+}
+
+// NotableBottle is one row of an anonymous ranking: no Nickname or Message, just the
+// Cork and the Journey totals it was ranked on.
+type NotableBottle struct {
+	BottleID    int32     `json:"bottle_id"`
+	BottleStyle int32     `json:"bottle_style"`
+	Lat         float64   `json:"lat"`
+	Lng         float64   `json:"lng"`
+	CastAt      time.Time `json:"cast_at"`
+	DistanceKm  float64   `json:"distance_km"`
+	ReReleases  int32     `json:"re_releases"`
+	Stamps      int32     `json:"stamps"`
+	Basins      []string  `json:"basins"`
+}
+
+// NotableRankings are the drifting Bottles with the most remarkable Journeys, best first.
+// Every ranking is present, even when empty.
+type NotableRankings struct {
+	LongestVoyages []NotableBottle `json:"longest_voyages"`
+	MostReReleased []NotableBottle `json:"most_re_released"`
+	MostStamped    []NotableBottle `json:"most_stamped"`
+	MostBasins     []NotableBottle `json:"most_basins"`
+	Oldest         []NotableBottle `json:"oldest"`
+}
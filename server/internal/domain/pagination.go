@@ -5,6 +5,8 @@ package domain
 // the internal structure, just pass it back on the next request.
 type Cursor struct {
 	LastID *int32 `json:"last_id,omitempty"`
+	// LastRank accompanies LastID on relevance-ordered lists such as search.
+	LastRank *float32 `json:"last_rank,omitempty"`
 }
 
 // CursorResult wraps any paginated list with the next cursor.
@@ -0,0 +1,14 @@
+package domain
+
+// SearchHit is a Bottle whose Message matched a search, with how well it matched.
+type SearchHit struct {
+	Bottle
+	Rank float32 `json:"rank"`
+}
+
+// MarshalJSON adds rank to the Bottle's own rendering.
+func (h SearchHit) MarshalJSON() ([]byte, error) {
+	return MarshalBottleWith(h.Bottle, struct {
+		Rank float32 `json:"rank"`
+	}{h.Rank})
+}
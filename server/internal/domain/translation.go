@@ -0,0 +1,24 @@
+package domain
+
+// Translation is a Bottle's Message rendered in a reader's language.
+type Translation struct {
+	// Lang is the language asked for; SourceLang the one the Message was detected in
+	// ("und" when detection could not tell).
+	Lang        string `json:"lang"`
+	SourceLang  string `json:"source_lang"`
+	MessageText string `json:"message_text"`
+}
+
+// TranslatedBottle is a Bottle with its Message translated; the original stays in
+// Bottle.MessageText.
+type TranslatedBottle struct {
+	Bottle
+	Translation Translation `json:"translation"`
+}
+
+// MarshalJSON adds translation to the Bottle's own rendering.
+func (t TranslatedBottle) MarshalJSON() ([]byte, error) {
+	return MarshalBottleWith(t.Bottle, struct {
+		Translation Translation `json:"translation"`
+	}{t.Translation})
+}
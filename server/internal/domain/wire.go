@@ -0,0 +1,13 @@
+package domain
+
+// Wire versions of the JSON vocabulary. Version 2 uses glossary names
+// (event "cast", status "mystery_delay", visible_at); version 1 is the
+// pre-rename spelling ("released", "scheduled", scheduled_release) that
+// pinned clients can still ask for during the transition window.
+const (
+	WireV1 = 1
+	WireV2 = 2
+
+	// WireVersion is what Bottle and BottleEvent JSON carry by default.
+	WireVersion = WireV2
+)
@@ -0,0 +1,124 @@
+package drift
+
+import (
+	"math"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/geo"
+	"github.com/Polqt/ocealis/util"
+)
+
+const (
+	// TickHours is simulated ocean time per tick: 15 real minutes = 6 hours of drift.
+	TickHours = 0.25
+	// TickInterval is how often the scheduler fires a drift tick.
+	TickInterval = 15 * time.Minute
+	// noiseDeg is the ± spread added to a current's bearing so paths look organic,
+	// not like perfect mathematical circles.
+	noiseDeg = 10
+)
+
+// currentZone maps a region of the ocean to a dominant current direction and speed.
+// This is a simplified gyre model, real ocean currents follow these
+// Broad circular patterns (gyres) driven by wind and the Coriolis effect, but with lots of local variation.
+type currentZone struct {
+	minLat, maxLat float64
+	minLng, maxLng float64
+	bearing        float64 // degrees, 0 = north, 90 = east, etc.
+	speedKmH       float64
+}
+
+var oceanZones = []currentZone{
+	{minLat: 0, maxLat: 60, minLng: -80, maxLng: 0, bearing: 45, speedKmH: 2.5},
+	// South Atlantic Gyre (counter-clockwise)
+	{minLat: -60, maxLat: 0, minLng: -60, maxLng: 20, bearing: 225, speedKmH: 2.0},
+	// North Pacific Gyre (clockwise)
+	{minLat: 0, maxLat: 65, minLng: 120, maxLng: -120, bearing: 60, speedKmH: 2.8},
+	// South Pacific Gyre (counter-clockwise)
+	{minLat: -60, maxLat: 0, minLng: 150, maxLng: -70, bearing: 210, speedKmH: 2.2},
+	// Indian Ocean Gyre
+	{minLat: -60, maxLat: 25, minLng: 40, maxLng: 120, bearing: 270, speedKmH: 1.8},
+	// Default fallback — gentle random drift
+	{minLat: -90, maxLat: 90, minLng: -180, maxLng: 180, bearing: 0, speedKmH: 0.5},
+}
+
+// Step is one tick of one Bottle.
+type Step struct {
+	Lat        float64
+	Lng        float64
+	BearingDeg float64
+	SpeedKmH   float64
+	DistanceKm float64
+}
+
+// Ashore reports whether the step would carry the Bottle onto land, where Simulate ends
+// its track. The scheduler ignores it until geo has real coastlines.
+func (s Step) Ashore() bool {
+	return geo.IsLand(s.Lat, s.Lng)
+}
+
+// Model is the drift engine. Given the same Seed it moves a Bottle along the same
+// path every time, so any Journey can be recomputed from its Cast point.
+type Model struct {
+	Seed uint64
+}
+
+// Step moves the Bottle at lat/lng through tick number tick. leeway scales the current's
+// speed by how the Bottle's style rides the wind (styles.Leeway); 1 drifts with it.
+func (m Model) Step(bottleID int32, tick int64, lat, lng, leeway float64) Step {
+	bearing, speed := DominantCurrent(lat, lng)
+	speed *= leeway
+	bearing += (Noise(m.Seed, bottleID, tick)*2 - 1) * noiseDeg
+	bearing = math.Mod(bearing+360, 360)
+
+	newLat, newLng := util.ApplyDrift(lat, lng, speed, bearing, TickHours)
+	return Step{
+		Lat:        newLat,
+		Lng:        newLng,
+		BearingDeg: bearing,
+		SpeedKmH:   speed,
+		DistanceKm: speed * TickHours,
+	}
+}
+
+// TickIndex numbers the tick a moment falls in, counting TickIntervals since the Unix epoch.
+func TickIndex(at time.Time) int64 {
+	return at.Unix() / int64(TickInterval/time.Second)
+}
+
+// Noise is a uniform value in [0, 1) derived only from (seed, bottle id, tick):
+// no shared RNG state, so Bottles can be stepped in any order or in parallel.
+func Noise(seed uint64, bottleID int32, tick int64) float64 {
+	x := seed
+	x = mix(x ^ uint64(uint32(bottleID)))
+	x = mix(x ^ uint64(tick))
+	return float64(x>>11) / (1 << 53)
+}
+
+// mix is the splitmix64 finalizer.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// DominantCurrent returns the bearing and speed of the gyre covering lat/lng.
+func DominantCurrent(lat, lng float64) (bearing, speed float64) {
+	for _, z := range oceanZones {
+		latIn := lat >= z.minLat && lat <= z.maxLat
+		lngIn := false
+		if z.minLng <= z.maxLng {
+			lngIn = lng >= z.minLng && lng <= z.maxLng
+		} else {
+			// Zone wraps across the antimeridian (+=180), e.g. North Pacific
+			lngIn = lng >= z.minLng || lng <= z.maxLng
+		}
+		if latIn && lngIn {
+			return z.bearing, z.speedKmH
+		}
+	}
+	// Should never happen since the last zone is a global fallback, but just in case:
+	last := oceanZones[len(oceanZones)-1]
+	return last.bearing, last.speedKmH
+}
@@ -0,0 +1,104 @@
+package drift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/drift"
+)
+
+func TestNoiseIsAPureFunctionOfSeedBottleAndTick(t *testing.T) {
+	a := drift.Noise(7, 42, 1000)
+	if a != drift.Noise(7, 42, 1000) {
+		t.Fatal("same inputs must give the same noise")
+	}
+	for _, other := range []float64{drift.Noise(8, 42, 1000), drift.Noise(7, 43, 1000), drift.Noise(7, 42, 1001)} {
+		if other == a {
+			t.Fatalf("changing seed, bottle or tick should change noise, all gave %v", a)
+		}
+	}
+
+	var sum float64
+	const n = 10000
+	for i := range int64(n) {
+		v := drift.Noise(1, int32(i%97), i)
+		if v < 0 || v >= 1 {
+			t.Fatalf("noise %v outside [0, 1)", v)
+		}
+		sum += v
+	}
+	if mean := sum / n; mean < 0.45 || mean > 0.55 {
+		t.Fatalf("noise mean %.3f, want ≈0.5", mean)
+	}
+}
+
+func TestModelReplaysAPath(t *testing.T) {
+	m := drift.Model{Seed: 99}
+	first := drift.TickIndex(time.Date(2026, 3, 1, 12, 7, 0, 0, time.UTC))
+	if first != drift.TickIndex(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatal("a late scheduler run should land in the same tick")
+	}
+
+	walk := func() (lat, lng float64) {
+		lat, lng = 20, -40
+		for tick := first; tick < first+96; tick++ {
+			s := m.Step(5, tick, lat, lng, 1)
+			if s.BearingDeg < 0 || s.BearingDeg >= 360 || s.DistanceKm != s.SpeedKmH*drift.TickHours {
+				t.Fatalf("bad step %+v", s)
+			}
+			lat, lng = s.Lat, s.Lng
+		}
+		return lat, lng
+	}
+	lat1, lng1 := walk()
+	lat2, lng2 := walk()
+	if lat1 != lat2 || lng1 != lng2 {
+		t.Fatalf("replay diverged: (%v, %v) vs (%v, %v)", lat1, lng1, lat2, lng2)
+	}
+	if lat1 == 20 && lng1 == -40 {
+		t.Fatal("bottle never moved")
+	}
+}
+
+func TestSimulateStopsWhenTheBottleBeaches(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := drift.Model{}
+
+	// North Pacific gyre pushes east from the basin fallback onto the US rectangle.
+	track := m.Simulate(1, 30, -140, 1, start, 60*drift.TicksPerDay)
+	if !track.Beached {
+		t.Fatalf("want beached, ended at %+v", track.End())
+	}
+	if n := len(track.Points) - 1; n >= 60*drift.TicksPerDay {
+		t.Fatalf("beached track should stop early, ran %d ticks", n)
+	}
+	if again := m.Simulate(1, 30, -140, 1, start, 60*drift.TicksPerDay); again.End() != track.End() {
+		t.Fatal("simulation must be reproducible")
+	}
+
+	open := m.Simulate(1, -30, -120, 1, start, drift.TicksPerDay)
+	if open.Beached || len(open.Points) != drift.TicksPerDay+1 {
+		t.Fatalf("open-Ocean day should run every tick, got %d points, beached=%v", len(open.Points), open.Beached)
+	}
+}
+
+func TestEnsembleBinsEveryMember(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ens := drift.Model{Seed: 3}.SimulateEnsemble(50, -30, -120, 1, start, 10*drift.TicksPerDay, 0.01)
+
+	total := 0
+	var share float64
+	for i, c := range ens.Cells {
+		total += c.Count
+		share += c.Share
+		if i > 0 && c.Count > ens.Cells[i-1].Count {
+			t.Fatal("cells must be busiest first")
+		}
+	}
+	if total != 50 || share < 0.999 || share > 1.001 {
+		t.Fatalf("want all 50 members binned, got %d (share %.3f)", total, share)
+	}
+	if len(ens.Cells) < 2 {
+		t.Fatal("distinct noise streams should spread the ensemble over more than one cell")
+	}
+}
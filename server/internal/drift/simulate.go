@@ -0,0 +1,132 @@
+package drift
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Polqt/ocealis/util"
+)
+
+// TicksPerDay is how many scheduler ticks one wall-clock day holds.
+const TicksPerDay = int(24 * time.Hour / TickInterval)
+
+// TrackPoint is a Bottle's position after one tick.
+type TrackPoint struct {
+	Tick int64
+	At   time.Time
+	Lat  float64
+	Lng  float64
+}
+
+// Track is one simulated drift, Cast point first.
+type Track struct {
+	BottleID int32
+	Points   []TrackPoint
+	// Beached is set when the next step would have landed, at which point the track stops.
+	Beached    bool
+	DistanceKm float64
+}
+
+// End is where the Bottle finished: on the Shoreline if Beached, else mid-Ocean.
+func (t Track) End() TrackPoint {
+	return t.Points[len(t.Points)-1]
+}
+
+// Simulate runs the scheduler's drift engine for ticks ticks from start, without a database.
+// A track stops early when the Bottle beaches (its next step falls on land).
+func (m Model) Simulate(bottleID int32, lat, lng, leeway float64, start time.Time, ticks int) Track {
+	first := TickIndex(start)
+	track := Track{
+		BottleID: bottleID,
+		Points:   make([]TrackPoint, 1, ticks+1),
+	}
+	track.Points[0] = TrackPoint{Tick: first, At: start, Lat: lat, Lng: lng}
+
+	for i := 1; i <= ticks; i++ {
+		tick := first + int64(i)
+		step := m.Step(bottleID, tick, lat, lng, leeway)
+		if step.Ashore() {
+			track.Beached = true
+			break
+		}
+		track.DistanceKm += util.HaversineKm(lat, lng, step.Lat, step.Lng)
+		lat, lng = step.Lat, step.Lng
+		track.Points = append(track.Points, TrackPoint{
+			Tick: tick,
+			At:   start.Add(time.Duration(i) * TickInterval),
+			Lat:  lat,
+			Lng:  lng,
+		})
+	}
+	return track
+}
+
+// HeatCell is one grid cell of an ensemble's destinations.
+type HeatCell struct {
+	MinLat, MinLng float64
+	Size           float64
+	Count          int
+	Beached        int
+	// Share is Count over the ensemble size.
+	Share float64
+}
+
+// Ensemble is a Monte Carlo run: many Bottles Cast from one point.
+type Ensemble struct {
+	Tracks []Track
+	Cells  []HeatCell // busiest first
+}
+
+// BeachedShare is the fraction of members that reached a Shoreline.
+func (e Ensemble) BeachedShare() float64 {
+	if len(e.Tracks) == 0 {
+		return 0
+	}
+	n := 0
+	for _, t := range e.Tracks {
+		if t.Beached {
+			n++
+		}
+	}
+	return float64(n) / float64(len(e.Tracks))
+}
+
+// SimulateEnsemble casts members Bottles from lat/lng and bins where each ends up into
+// cellDeg-sized cells. Members differ only in bottle id, so each is its own noise stream.
+func (m Model) SimulateEnsemble(members int, lat, lng, leeway float64, start time.Time, ticks int, cellDeg float64) Ensemble {
+	ens := Ensemble{Tracks: make([]Track, members)}
+	cells := map[[2]int]*HeatCell{}
+	for i := range members {
+		t := m.Simulate(int32(i+1), lat, lng, leeway, start, ticks)
+		ens.Tracks[i] = t
+
+		end := t.End()
+		key := [2]int{int(math.Floor(end.Lat / cellDeg)), int(math.Floor(end.Lng / cellDeg))}
+		c, ok := cells[key]
+		if !ok {
+			c = &HeatCell{MinLat: float64(key[0]) * cellDeg, MinLng: float64(key[1]) * cellDeg, Size: cellDeg}
+			cells[key] = c
+		}
+		c.Count++
+		if t.Beached {
+			c.Beached++
+		}
+	}
+
+	for _, c := range cells {
+		c.Share = float64(c.Count) / float64(members)
+		ens.Cells = append(ens.Cells, *c)
+	}
+	sort.Slice(ens.Cells, func(i, j int) bool {
+		a, b := ens.Cells[i], ens.Cells[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.MinLat != b.MinLat {
+			return a.MinLat < b.MinLat
+		}
+		return a.MinLng < b.MinLng
+	})
+	return ens
+}
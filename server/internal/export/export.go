@@ -0,0 +1,115 @@
+package export
+
+import (
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+// Format is a downloadable Journey file type, named by its extension.
+type Format string
+
+const (
+	FormatGeoJSON Format = "geojson"
+	FormatGPX     Format = "gpx"
+	FormatKML     Format = "kml"
+)
+
+// ContentType is the media type a Format is served as.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatGPX:
+		return "application/gpx+xml"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	}
+	return "application/octet-stream"
+}
+
+// Journey renders j in format f.
+func Journey(f Format, j *domain.Journey) ([]byte, error) {
+	switch f {
+	case FormatGPX:
+		return GPX(j)
+	case FormatKML:
+		return KML(j)
+	}
+	return GeoJSON(j)
+}
+
+// Vertex is one position on a drawn path.
+type Vertex struct {
+	Lng, Lat float64
+	At       time.Time
+}
+
+// Path is the Bottle's drift as drawable segments. A new segment starts at every
+// Re-release (the Bottle jumps to the finder's Shoreline) and wherever the path
+// crosses the antimeridian, so no renderer draws a line across the whole world.
+func Path(events []domain.BottleEvent) [][]Vertex {
+	var segments [][]Vertex
+	var cur []Vertex
+	for _, e := range events {
+		switch e.EventType {
+		case domain.EventTypeCast, domain.EventTypeDrift:
+		case domain.EventTypeReReleased:
+			if len(cur) > 0 {
+				segments = append(segments, cur)
+			}
+			cur = nil
+		default:
+			continue
+		}
+		cur = append(cur, Vertex{Lng: e.Lng, Lat: e.Lat, At: e.CreatedAt})
+	}
+	if len(cur) > 0 {
+		segments = append(segments, cur)
+	}
+
+	var split [][]Vertex
+	for _, s := range segments {
+		split = append(split, SplitAntimeridian(s)...)
+	}
+	return split
+}
+
+// SplitAntimeridian breaks a path wherever consecutive vertices are more than 180° of
+// longitude apart, i.e. the short way between them crosses ±180. The crossing latitude
+// is interpolated so one piece ends exactly on the antimeridian and the next starts there.
+func SplitAntimeridian(path []Vertex) [][]Vertex {
+	if len(path) == 0 {
+		return nil
+	}
+	var out [][]Vertex
+	cur := []Vertex{path[0]}
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		if d := b.Lng - a.Lng; d > 180 || d < -180 {
+			edge, bLng := 180.0, b.Lng+360
+			if a.Lng < 0 {
+				edge, bLng = -180, b.Lng-360
+			}
+			frac := (edge - a.Lng) / (bLng - a.Lng)
+			at := a.At.Add(time.Duration(frac * float64(b.At.Sub(a.At))))
+			lat := a.Lat + frac*(b.Lat-a.Lat)
+			cur = append(cur, Vertex{Lng: edge, Lat: lat, At: at})
+			out = append(out, cur)
+			cur = []Vertex{{Lng: -edge, Lat: lat, At: at}}
+		}
+		cur = append(cur, b)
+	}
+	return append(out, cur)
+}
+
+// Milestones are the Journey events drawn as points: everything but drift.
+func Milestones(events []domain.BottleEvent) []domain.BottleEvent {
+	var out []domain.BottleEvent
+	for _, e := range events {
+		if e.EventType != domain.EventTypeDrift {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package export_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/export"
+)
+
+var t0 = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func journey() *domain.Journey {
+	ev := func(id int32, typ domain.EventType, hours int, lat, lng float64) domain.BottleEvent {
+		return domain.BottleEvent{ID: id, BottleID: 9, EventType: typ, Lat: lat, Lng: lng, CreatedAt: t0.Add(time.Duration(hours) * time.Hour)}
+	}
+	return &domain.Journey{
+		Bottle: &domain.Bottle{ID: 9, Nickname: "petrel", Status: domain.BottleStatusDrifting},
+		Events: []domain.BottleEvent{
+			ev(1, domain.EventTypeCast, 0, 30, 178),
+			ev(2, domain.EventTypeDrift, 6, 31, 179.5),
+			ev(3, domain.EventTypeDrift, 12, 32, -179.5), // crosses the antimeridian
+			ev(4, domain.EventTypeStamp, 13, 32, -179.5),
+			ev(5, domain.EventTypeReReleased, 14, 10, -150), // jump to the finder's Shoreline
+			ev(6, domain.EventTypeDrift, 20, 11, -149),
+		},
+	}
+}
+
+func TestSplitAntimeridianEndsAndStartsOnTheLine(t *testing.T) {
+	parts := export.SplitAntimeridian([]export.Vertex{
+		{Lng: 179, Lat: 10, At: t0},
+		{Lng: -179, Lat: 12, At: t0.Add(2 * time.Hour)},
+		{Lng: 179, Lat: 14, At: t0.Add(4 * time.Hour)}, // and back west again
+	})
+	if len(parts) != 3 {
+		t.Fatalf("want 3 pieces, got %d: %+v", len(parts), parts)
+	}
+	if end, start := parts[0][1], parts[1][0]; end.Lng != 180 || start.Lng != -180 || end.Lat != 11 || start.Lat != 11 {
+		t.Fatalf("eastward crossing should meet at (±180, 11), got %+v / %+v", end, start)
+	}
+	if !parts[0][1].At.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("crossing time should interpolate too, got %v", parts[0][1].At)
+	}
+	if end, start := parts[1][len(parts[1])-1], parts[2][0]; end.Lng != -180 || start.Lng != 180 || end.Lat != 13 {
+		t.Fatalf("westward crossing should meet at (∓180, 13), got %+v / %+v", end, start)
+	}
+}
+
+func TestGeoJSONHasDriftLineAndMilestonePoints(t *testing.T) {
+	body, err := export.GeoJSON(journey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(body, &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 4 {
+		t.Fatalf("want drift line + cast, stamp, re-release points, got %d features", len(fc.Features))
+	}
+
+	line := fc.Features[0]
+	var lines [][][2]float64
+	if err := json.Unmarshal(line.Geometry.Coordinates, &lines); err != nil || line.Geometry.Type != "MultiLineString" {
+		t.Fatalf("want MultiLineString, got %s: %v", line.Geometry.Type, err)
+	}
+	// Antimeridian split, then the Re-release jump starts a fresh piece.
+	if len(lines) != 3 || lines[0][len(lines[0])-1][0] != 180 || lines[1][0][0] != -180 || lines[2][0] != [2]float64{-150, 10} {
+		t.Fatalf("bad split: %v", lines)
+	}
+	for _, seg := range lines {
+		for i := 1; i < len(seg); i++ {
+			if math.Abs(seg[i][0]-seg[i-1][0]) > 180 {
+				t.Fatalf("segment still jumps the antimeridian: %v", seg)
+			}
+		}
+	}
+
+	var types []string
+	for _, f := range fc.Features[1:] {
+		types = append(types, f.Properties["event_type"].(string))
+		if f.Geometry.Type != "Point" || f.Properties["at"] == "" {
+			t.Fatalf("milestone feature %+v", f)
+		}
+	}
+	if strings.Join(types, ",") != "cast,stamp,re_released" {
+		t.Fatalf("milestones %v", types)
+	}
+}
+
+func TestGPXAndKMLAreWellFormed(t *testing.T) {
+	gpx, err := export.GPX(journey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Waypoints []struct {
+			Type string `xml:"type"`
+		} `xml:"wpt"`
+		Segments []struct {
+			Points []struct {
+				Lon float64 `xml:"lon,attr"`
+			} `xml:"trkpt"`
+		} `xml:"trk>trkseg"`
+	}
+	if err := xml.Unmarshal(gpx, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Waypoints) != 3 || len(g.Segments) != 3 || g.Segments[1].Points[0].Lon != -180 {
+		t.Fatalf("gpx: %d waypoints, %d segments", len(g.Waypoints), len(g.Segments))
+	}
+
+	kml, err := export.KML(journey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k struct {
+		Placemarks []struct {
+			Name        string   `xml:"name"`
+			LineStrings []string `xml:"MultiGeometry>LineString>coordinates"`
+			When        string   `xml:"TimeStamp>when"`
+		} `xml:"Document>Placemark"`
+	}
+	if err := xml.Unmarshal(kml, &k); err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Placemarks) != 4 || len(k.Placemarks[0].LineStrings) != 3 || k.Placemarks[1].When != "2026-03-01T00:00:00Z" {
+		t.Fatalf("kml placemarks %+v", k.Placemarks)
+	}
+}
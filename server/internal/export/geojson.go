@@ -0,0 +1,85 @@
+package export
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+// Feature is a GeoJSON Feature (RFC 7946).
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry; Coordinates holds [lng, lat] positions nested per Type.
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// FeatureCollection is the top-level GeoJSON document.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// LineGeometry is a LineString for one segment and a MultiLineString for several.
+func LineGeometry(segments [][]Vertex) Geometry {
+	lines := make([][][2]float64, len(segments))
+	for i, s := range segments {
+		lines[i] = make([][2]float64, len(s))
+		for j, v := range s {
+			lines[i][j] = [2]float64{v.Lng, v.Lat}
+		}
+	}
+	if len(lines) == 1 {
+		return Geometry{Type: "LineString", Coordinates: lines[0]}
+	}
+	return Geometry{Type: "MultiLineString", Coordinates: lines}
+}
+
+// GeoJSON is the Journey as a FeatureCollection: one drift line, then a Point per milestone.
+func GeoJSON(j *domain.Journey) ([]byte, error) {
+	var features []Feature
+	if path := Path(j.Events); len(path) > 0 {
+		first, last := path[0][0], path[len(path)-1]
+		features = append(features, Feature{
+			Type:     "Feature",
+			Geometry: LineGeometry(path),
+			Properties: map[string]any{
+				"bottle_id":    j.Bottle.ID,
+				"nickname":     j.Bottle.Nickname,
+				"bottle_style": j.Bottle.BottleStyle,
+				"status":       j.Bottle.Status,
+				"started_at":   first.At.UTC().Format(time.RFC3339),
+				"ended_at":     last[len(last)-1].At.UTC().Format(time.RFC3339),
+			},
+		})
+	}
+	for _, e := range Milestones(j.Events) {
+		props := map[string]any{
+			"event_id":   e.ID,
+			"event_type": e.EventType,
+			"at":         e.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if e.Payload != nil {
+			props["payload"] = e.Payload
+		}
+		features = append(features, Feature{
+			Type:       "Feature",
+			Geometry:   Geometry{Type: "Point", Coordinates: [2]float64{e.Lng, e.Lat}},
+			Properties: props,
+		})
+	}
+	return json.Marshal(NewFeatureCollection(features))
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+type gpxDoc struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Name      string        `xml:"metadata>name"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Track     gpxTrack      `xml:"trk"`
+}
+
+type gpxWaypoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time,omitempty"`
+	Name string  `xml:"name"`
+	Type string  `xml:"type"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxTrackPoint `xml:"trkpt"`
+}
+
+type gpxTrackPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time,omitempty"`
+}
+
+// GPX is the Journey as GPX 1.1: a waypoint per milestone and the drift as a track,
+// one trkseg per Path segment.
+func GPX(j *domain.Journey) ([]byte, error) {
+	name := fmt.Sprintf("Bottle %d", j.Bottle.ID)
+	doc := gpxDoc{
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Version: "1.1",
+		Creator: "Ocealis",
+		Name:    name,
+		Track:   gpxTrack{Name: name + " drift"},
+	}
+	for _, e := range Milestones(j.Events) {
+		doc.Waypoints = append(doc.Waypoints, gpxWaypoint{
+			Lat:  e.Lat,
+			Lon:  e.Lng,
+			Time: e.CreatedAt.UTC().Format(time.RFC3339),
+			Name: string(e.EventType),
+			Type: string(e.EventType),
+		})
+	}
+	for _, s := range Path(j.Events) {
+		seg := gpxSegment{Points: make([]gpxTrackPoint, len(s))}
+		for i, v := range s {
+			seg.Points[i] = gpxTrackPoint{Lat: v.Lat, Lon: v.Lng, Time: v.At.UTC().Format(time.RFC3339)}
+		}
+		doc.Track.Segments = append(doc.Track.Segments, seg)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+type kmlDoc struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name          string            `xml:"name"`
+	TimeStamp     *kmlWhen          `xml:"TimeStamp,omitempty"`
+	TimeSpan      *kmlSpan          `xml:"TimeSpan,omitempty"`
+	ExtendedData  []kmlData         `xml:"ExtendedData>Data,omitempty"`
+	Point         *kmlCoordinates   `xml:"Point,omitempty"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry,omitempty"`
+}
+
+type kmlWhen struct {
+	When string `xml:"when"`
+}
+
+type kmlSpan struct {
+	Begin string `xml:"begin"`
+	End   string `xml:"end"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	LineStrings []kmlCoordinates `xml:"LineString"`
+}
+
+// KML is the Journey as KML 2.2: one drift Placemark (a LineString per Path segment)
+// and a timestamped Placemark per milestone.
+func KML(j *domain.Journey) ([]byte, error) {
+	doc := kmlDoc{
+		Xmlns:    "http://www.opengis.net/kml/2.2",
+		Document: kmlDocument{Name: fmt.Sprintf("Bottle %d", j.Bottle.ID)},
+	}
+
+	if path := Path(j.Events); len(path) > 0 {
+		geom := &kmlMultiGeometry{}
+		for _, s := range path {
+			coords := make([]string, len(s))
+			for i, v := range s {
+				coords[i] = kmlCoord(v.Lng, v.Lat)
+			}
+			geom.LineStrings = append(geom.LineStrings, kmlCoordinates{Coordinates: strings.Join(coords, " ")})
+		}
+		last := path[len(path)-1]
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlPlacemark{
+			Name: "drift",
+			TimeSpan: &kmlSpan{
+				Begin: path[0][0].At.UTC().Format(time.RFC3339),
+				End:   last[len(last)-1].At.UTC().Format(time.RFC3339),
+			},
+			ExtendedData: []kmlData{
+				{Name: "bottle_id", Value: strconv.Itoa(int(j.Bottle.ID))},
+				{Name: "nickname", Value: j.Bottle.Nickname},
+				{Name: "status", Value: string(j.Bottle.Status)},
+			},
+			MultiGeometry: geom,
+		})
+	}
+
+	for _, e := range Milestones(j.Events) {
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kmlPlacemark{
+			Name:      string(e.EventType),
+			TimeStamp: &kmlWhen{When: e.CreatedAt.UTC().Format(time.RFC3339)},
+			ExtendedData: []kmlData{
+				{Name: "event_id", Value: strconv.Itoa(int(e.ID))},
+				{Name: "event_type", Value: string(e.EventType)},
+			},
+			Point: &kmlCoordinates{Coordinates: kmlCoord(e.Lng, e.Lat)},
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+func kmlCoord(lng, lat float64) string {
+	return strconv.FormatFloat(lng, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+}
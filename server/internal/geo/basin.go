@@ -0,0 +1,37 @@
+package geo
+
+// Ocean basins, as named in region:<basin> WebSocket topics and /ocean/stats.
+const (
+	BasinNorthAtlantic = "north_atlantic"
+	BasinSouthAtlantic = "south_atlantic"
+	BasinNorthPacific  = "north_pacific"
+	BasinSouthPacific  = "south_pacific"
+	BasinIndianOcean   = "indian_ocean"
+	BasinOther         = "other"
+)
+
+// Basins lists every Basin result, in display order.
+var Basins = []string{
+	BasinNorthAtlantic, BasinSouthAtlantic, BasinNorthPacific,
+	BasinSouthPacific, BasinIndianOcean, BasinOther,
+}
+
+// Basin determines the ocean basin for given coordinates.
+// This is a simple heuristic; the ocean_basin SQL function (migration 00010) mirrors it
+// for the trigger-kept stats, so change both together.
+func Basin(lat, lng float64) string {
+	switch {
+	case lat >= 0 && lng >= -80 && lng <= 0:
+		return BasinNorthAtlantic
+	case lat < 0 && lng >= -60 && lng <= 20:
+		return BasinSouthAtlantic
+	case lat >= 0 && (lng >= 120 || lng <= -120):
+		return BasinNorthPacific
+	case lat < 0 && (lng >= 150 || lng <= -70):
+		return BasinSouthPacific
+	case lat >= -60 && lat <= 25 && lng >= 40 && lng <= 120:
+		return BasinIndianOcean
+	default:
+		return BasinOther
+	}
+}
@@ -0,0 +1,41 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sync"
+)
+
+//go:embed coastline.json
+var coastlineJSON []byte
+
+// Landmass is one coarse coastline ring, closed (first point == last point).
+type Landmass struct {
+	Name string
+	Ring []Point
+}
+
+var coastlines = sync.OnceValue(func() []Landmass {
+	var raw []struct {
+		Name string       `json:"name"`
+		Ring [][2]float64 `json:"ring"` // [lng, lat], GeoJSON order
+	}
+	if err := json.Unmarshal(coastlineJSON, &raw); err != nil {
+		panic("geo: embedded coastline.json: " + err.Error())
+	}
+	out := make([]Landmass, len(raw))
+	for i, r := range raw {
+		ring := make([]Point, len(r.Ring))
+		for j, p := range r.Ring {
+			ring[j] = Point{Lat: p[1], Lng: p[0]}
+		}
+		out[i] = Landmass{Name: r.Name, Ring: ring}
+	}
+	return out
+})
+
+// Coastlines are the embedded world outlines, a few hundred points in all — enough to
+// draw a recognisable mini map, far too coarse for IsLand. Rings never cross ±180.
+func Coastlines() []Landmass {
+	return coastlines()
+}
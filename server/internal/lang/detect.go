@@ -0,0 +1,131 @@
+// Package lang guesses which language a Message is written in, well enough to pick a
+// Postgres text search config for stemming. It scores function words ("the", "und",
+// "que") rather than n-gram models: Messages are short, and a wrong guess only costs
+// stemming quality because search also matches unstemmed words.
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language is an ISO 639-1 code and the Postgres text search config that stems it.
+type Language struct {
+	Code   string
+	Config string
+}
+
+// Undetermined is used when no language scores clearly; 'simple' only lowercases.
+var Undetermined = Language{Code: "und", Config: "simple"}
+
+// Supported are the languages Detect can return besides Undetermined. The search
+// query in migration 00013 ORs one tsquery per Config — keep the two in step.
+var Supported = []Language{
+	{Code: "en", Config: "english"},
+	{Code: "es", Config: "spanish"},
+	{Code: "fr", Config: "french"},
+	{Code: "de", Config: "german"},
+	{Code: "pt", Config: "portuguese"},
+	{Code: "it", Config: "italian"},
+	{Code: "nl", Config: "dutch"},
+	{Code: "sv", Config: "swedish"},
+	{Code: "ru", Config: "russian"},
+}
+
+// ByCode finds a Supported language (or Undetermined) by ISO 639-1 code.
+func ByCode(code string) (Language, bool) {
+	if code == Undetermined.Code {
+		return Undetermined, true
+	}
+	for _, l := range Supported {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// stopwords are frequent function words per Latin-script language. Words shared by
+// several lists ("de", "en", "a") split their vote between them.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "you", "that", "this", "with", "for", "have", "not", "it", "of", "to", "my", "your", "i", "we", "be", "will", "from", "who", "what", "if"},
+	"es": {"el", "la", "los", "las", "y", "que", "de", "en", "es", "un", "una", "por", "para", "con", "no", "mi", "tu", "yo", "se", "lo", "del", "al", "como", "pero", "muy"},
+	"fr": {"le", "la", "les", "et", "est", "un", "une", "des", "que", "qui", "de", "du", "en", "je", "tu", "pas", "pour", "dans", "avec", "mon", "ma", "sur", "ce", "il", "nous"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "du", "ein", "eine", "zu", "mit", "auf", "für", "den", "dem", "von", "sie", "wir", "es", "mein", "dein", "auch", "wenn", "noch"},
+	"pt": {"o", "a", "os", "as", "e", "que", "de", "do", "da", "em", "um", "uma", "não", "para", "com", "eu", "você", "meu", "minha", "se", "no", "na", "por", "mas", "muito"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "che", "di", "un", "una", "non", "per", "con", "io", "tu", "mi", "ti", "è", "sono", "del", "della", "nel", "ma", "questo", "anche"},
+	"nl": {"de", "het", "een", "en", "is", "dat", "niet", "ik", "je", "van", "op", "te", "met", "voor", "zijn", "wij", "mijn", "jouw", "maar", "ook", "als", "er", "naar", "dit", "wat"},
+	"sv": {"och", "att", "det", "är", "en", "ett", "som", "på", "jag", "du", "inte", "med", "för", "av", "till", "den", "har", "vi", "min", "din", "men", "om", "så", "var", "från"},
+}
+
+var votes = func() map[string]map[string]float64 {
+	v := map[string]map[string]float64{}
+	shared := map[string]int{}
+	for _, words := range stopwords {
+		for _, w := range words {
+			shared[w]++
+		}
+	}
+	for code, words := range stopwords {
+		for _, w := range words {
+			if v[w] == nil {
+				v[w] = map[string]float64{}
+			}
+			v[w][code] = 1 / float64(shared[w])
+		}
+	}
+	return v
+}()
+
+// minScore is the least evidence Detect accepts: two unambiguous function words.
+const minScore = 2
+
+// Detect guesses text's language. Cyrillic text is Russian; Latin text is scored by
+// function words and must lead the runner-up clearly; anything else is Undetermined.
+func Detect(text string) Language {
+	var latin, cyrillic, letters int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		}
+	}
+	switch {
+	case letters == 0:
+		return Undetermined
+	case cyrillic*2 > letters:
+		lang, _ := ByCode("ru")
+		return lang
+	case latin*2 <= letters:
+		return Undetermined
+	}
+
+	scores := map[string]float64{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for code, v := range votes[strings.Trim(word, "'")] {
+			scores[code] += v
+		}
+	}
+	best, bestScore, second := "", 0.0, 0.0
+	for code, s := range scores {
+		switch {
+		case s > bestScore || (s == bestScore && code < best):
+			best, bestScore, second = code, s, bestScore
+		case s > second:
+			second = s
+		}
+	}
+	if bestScore < minScore || bestScore < second*1.5 {
+		return Undetermined
+	}
+	lang, _ := ByCode(best)
+	return lang
+}
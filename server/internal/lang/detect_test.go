@@ -0,0 +1,46 @@
+package lang_test
+
+import (
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/lang"
+)
+
+func TestDetectPicksLanguageFromFunctionWords(t *testing.T) {
+	for want, message := range map[string]string{
+		"en": "I hope this bottle finds you well and that the sea is kind to you",
+		"es": "Espero que esta botella te encuentre bien y que el mar sea amable contigo",
+		"fr": "J'espère que cette bouteille te trouvera et que la mer sera douce avec toi",
+		"de": "Ich hoffe, dass diese Flasche dich findet und das Meer freundlich zu dir ist",
+		"pt": "Espero que esta garrafa te encontre bem e que o mar seja gentil com você",
+		"it": "Spero che questa bottiglia ti trovi bene e che il mare sia gentile con te",
+		"nl": "Ik hoop dat deze fles je vindt en dat de zee vriendelijk voor je is",
+		"sv": "Jag hoppas att den här flaskan hittar dig och att havet är snällt mot dig",
+		"ru": "Надеюсь, эта бутылка найдёт тебя",
+	} {
+		if got := lang.Detect(message); got.Code != want {
+			t.Errorf("Detect(%q) = %s, want %s", message, got.Code, want)
+		}
+	}
+}
+
+func TestDetectFallsBackToSimpleWithoutEvidence(t *testing.T) {
+	for _, message := range []string{"", "hello", "42!!", "海は広い", "Tokyo Lisboa Berlin"} {
+		if got := lang.Detect(message); got != lang.Undetermined {
+			t.Errorf("Detect(%q) = %+v, want Undetermined", message, got)
+		}
+	}
+}
+
+func TestSupportedConfigsAreDistinct(t *testing.T) {
+	seen := map[string]bool{lang.Undetermined.Config: true}
+	for _, l := range lang.Supported {
+		if seen[l.Config] {
+			t.Fatalf("config %s listed twice", l.Config)
+		}
+		seen[l.Config] = true
+		if got, ok := lang.ByCode(l.Code); !ok || got != l {
+			t.Fatalf("ByCode(%s) = %+v, %v", l.Code, got, ok)
+		}
+	}
+}
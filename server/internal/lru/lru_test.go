@@ -0,0 +1,28 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/lru"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := lru.New[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("a = %v, %v", v, ok)
+	}
+	c.Add("c", 3) // b is now the least recently used
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("want b evicted")
+	}
+	c.Add("a", 10)
+	if v, _ := c.Get("a"); v != 10 || c.Len() != 2 {
+		t.Fatalf("replace: a = %v, len %d", v, c.Len())
+	}
+	c.Clear()
+	if _, ok := c.Get("c"); ok || c.Len() != 0 {
+		t.Fatal("want an empty cache after Clear")
+	}
+}
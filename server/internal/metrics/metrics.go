@@ -0,0 +1,172 @@
+// Package metrics holds the process-wide Prometheus collectors served at /metrics.
+// Collectors are package globals on purpose — one registry per process, like the DB pool.
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ocealis"
+
+// Registry is what /metrics exposes. Tests may Gather from it directly.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration is request latency per matched route template.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// BottleActions counts Bottle life actions: cast, open, re_release.
+	BottleActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bottle_actions_total",
+		Help:      "Bottle life actions completed (cast, open, re_release).",
+	}, []string{"action"})
+
+	DriftTickDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "drift",
+		Name:      "tick_duration_seconds",
+		Help:      "Wall time of one drift tick across all active Bottles.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	})
+
+	DriftBottlesMoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "drift",
+		Name:      "bottles_moved_total",
+		Help:      "Bottles moved by drift ticks.",
+	})
+
+	// DriftBatchFailures counts drift batches rolled back; their Bottles wait for the next tick.
+	DriftBatchFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "drift",
+		Name:      "batch_failures_total",
+		Help:      "Drift tick batches whose transaction failed and rolled back.",
+	})
+
+	// ScheduledReleaseLag is how late a Cork appeared after its Mystery Delay ended.
+	ScheduledReleaseLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "drift",
+		Name:      "scheduled_release_lag_seconds",
+		Help:      "Delay between visible_at and the scheduler releasing the Bottle.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 900, 3600},
+	})
+
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests refused by an IP rate limiter.",
+	}, []string{"limiter"})
+
+	TurnstileFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "turnstile_failures_total",
+		Help:      "Casts blocked at the Turnstile / proof-of-work check.",
+	})
+
+	WSClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "connected_clients",
+		Help:      "WebSocket clients registered with the Hub.",
+	})
+
+	// WSDroppedSends counts messages skipped because a client's send buffer was full.
+	WSDroppedSends = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "dropped_sends_total",
+		Help:      "WebSocket messages dropped on a full client buffer.",
+	}, []string{"scope"})
+
+	// CardRenders counts share-card requests by result: hit (cached PNG) or render.
+	CardRenders = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "card",
+		Name:      "requests_total",
+		Help:      "Share-card requests served from cache (hit) or freshly drawn (render).",
+	}, []string{"result"})
+
+	// Translations counts Message translations by result: hit (cached), translate or error.
+	Translations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "translate",
+		Name:      "requests_total",
+		Help:      "Message translations served from cache (hit), fetched (translate) or failed (error).",
+	}, []string{"result"})
+
+	// MapCacheLookups counts map response cache lookups by result: hit or miss.
+	MapCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "map_cache",
+		Name:      "lookups_total",
+		Help:      "Map response cache lookups by result (hit or miss).",
+	}, []string{"result"})
+
+	// MapCacheHitRatio is hits over lookups since start; rate(lookups_total) gives it
+	// over a window.
+	MapCacheHitRatio = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "map_cache",
+		Name:      "hit_ratio",
+		Help:      "Share of map response cache lookups served from cache since start.",
+	}, func() float64 {
+		hits, misses := mapCacheHits.Load(), mapCacheMisses.Load()
+		if hits+misses == 0 {
+			return 0
+		}
+		return float64(hits) / float64(hits+misses)
+	})
+)
+
+var mapCacheHits, mapCacheMisses atomic.Uint64
+
+// ObserveMapCache records one map cache lookup.
+func ObserveMapCache(hit bool) {
+	if hit {
+		mapCacheHits.Add(1)
+		MapCacheLookups.WithLabelValues("hit").Inc()
+		return
+	}
+	mapCacheMisses.Add(1)
+	MapCacheLookups.WithLabelValues("miss").Inc()
+}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		BottleActions,
+		DriftTickDuration,
+		DriftBottlesMoved,
+		DriftBatchFailures,
+		ScheduledReleaseLag,
+		RateLimitRejections,
+		TurnstileFailures,
+		WSClients,
+		WSDroppedSends,
+		CardRenders,
+		Translations,
+		MapCacheLookups,
+		MapCacheHitRatio,
+	)
+}
+
+// Handler serves Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,166 @@
+// Package openapi builds the OpenAPI 3.1 document the API serves. Generator derives
+// schemas from the Go types handlers bind and render — json/query tags for names,
+// validate tags for bounds — so the document cannot describe a field the code lacks.
+// Validate checks decoded JSON against a schema; the contract tests use it to keep
+// handlers honest.
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Version is the OpenAPI version documents declare.
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds one path's operations keyed by lower-case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// Messages describes a WebSocket upgrade's frames, which OpenAPI has no words for.
+	Messages *Messages `json:"x-messages,omitempty"`
+}
+
+// Messages are the JSON frames each side of a WebSocket sends.
+type Messages struct {
+	Server *Schema `json:"server,omitempty"`
+	Client *Schema `json:"client,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path", "query" or "header"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is the slice of JSON Schema 2020-12 the API needs, plus two extensions for
+// rules JSON Schema cannot state: x-max-graphemes counts user-perceived characters
+// (maxLength counts code points), and x-required-with names sibling properties that
+// must be sent together.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // bool or *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Discriminator        *Discriminator     `json:"discriminator,omitempty"`
+	MaxGraphemes         *int               `json:"x-max-graphemes,omitempty"`
+	RequiredWith         []string           `json:"x-required-with,omitempty"`
+}
+
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+// Types is a schema's type keyword: one name marshals as a string, several as an array.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// RefPrefix is where component schema references point.
+const RefPrefix = "#/components/schemas/"
+
+// Ref is a reference to the component schema name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: RefPrefix + name}
+}
+
+// Resolve follows s's reference, if it has one, to the component schema.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, RefPrefix)]
+	}
+	return s
+}
+
+// Operation is the operation for method on a path in OpenAPI form ("/bottles/{id}").
+func (d *Document) Operation(method, path string) *Operation {
+	item := d.Paths[path]
+	if item == nil {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+// PathFromRoute converts a Fiber route ("/bottles/:id/journey.:format") to an OpenAPI
+// path template ("/bottles/{id}/journey.{format}").
+func PathFromRoute(route string) string {
+	return routeParam.ReplaceAllString(route, "{$1}")
+}
@@ -0,0 +1,112 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/openapi"
+)
+
+type marker struct {
+	ID int32 `json:"id"`
+}
+
+type page[T any] struct {
+	Data []T       `json:"data"`
+	Next *marker   `json:"next"`
+	Note string    `json:"note,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+type castRequest struct {
+	Nickname string   `json:"nickname" validate:"required,max_graphemes=24"`
+	Lat      *float64 `json:"lat" validate:"omitempty,min=-90,max=90"`
+}
+
+type searchQuery struct {
+	Q      string `query:"q" validate:"required,max=200"`
+	Cursor *int32 `query:"cursor" validate:"required_with=Rank"`
+	Rank   *int32 `query:"rank" validate:"required_with=Cursor"`
+}
+
+func TestGeneratorFollowsTagsAndValidateRules(t *testing.T) {
+	g := openapi.NewGenerator()
+	if ref := g.Response(page[marker]{}); ref.Ref != openapi.RefPrefix+"pagemarker" {
+		t.Fatalf("generic component ref %q", ref.Ref)
+	}
+	p := g.Schemas()["pagemarker"]
+	if !slices.Equal(p.Required, []string{"data", "next", "at"}) || p.AdditionalProperties != false {
+		t.Fatalf("response object %+v", p)
+	}
+	if next := p.Properties["next"]; len(next.AnyOf) != 2 || next.AnyOf[0].Ref != openapi.RefPrefix+"marker" {
+		t.Fatalf("pointer to a component must be nullable: %+v", next)
+	}
+	if at := p.Properties["at"]; at.Format != "date-time" {
+		t.Fatalf("time %+v", at)
+	}
+
+	body := g.Body(castRequest{})
+	if !slices.Equal(body.Required, []string{"nickname"}) || *body.Properties["nickname"].MaxGraphemes != 24 {
+		t.Fatalf("body %+v", body)
+	}
+	if lat := body.Properties["lat"]; *lat.Minimum != -90 || *lat.Maximum != 90 || !slices.Contains(lat.Type, "null") {
+		t.Fatalf("lat %+v", lat)
+	}
+
+	params := g.Query(searchQuery{})
+	if len(params) != 3 || !params[0].Required || *params[0].Schema.MaxLength != 200 || params[1].Required {
+		t.Fatalf("query params %+v %+v", params[0], params[1])
+	}
+	if !slices.Equal(params[1].Schema.RequiredWith, []string{"rank"}) {
+		t.Fatalf("required_with names query params: %v", params[1].Schema.RequiredWith)
+	}
+}
+
+func TestGeneratorPanicsOnRulesItCannotDocument(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "email") {
+			t.Fatalf("want a panic naming the rule, got %v", r)
+		}
+	}()
+	openapi.NewGenerator().Body(struct {
+		Email string `json:"email" validate:"email"`
+	}{})
+}
+
+func TestValidateIsStrictAboutObjects(t *testing.T) {
+	g := openapi.NewGenerator()
+	doc := &openapi.Document{}
+	schema := g.Response(page[marker]{})
+	doc.Components.Schemas = g.Schemas()
+	decode := func(raw string) any {
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	if err := doc.Validate(schema, decode(`{"data":[{"id":1}],"next":null,"at":"2026-02-01T00:00:00Z"}`)); err != nil {
+		t.Fatal(err)
+	}
+	for raw, want := range map[string]string{
+		`{"data":[],"next":null}`: `missing required "at"`,
+		`{"data":[],"next":null,"at":"2026-02-01T00:00:00Z","extra":1}`: `undocumented property "extra"`,
+		`{"data":[{"id":1.5}],"next":null,"at":"2026-02-01T00:00:00Z"}`: `$.data[0].id`,
+		`{"data":null,"next":{"id":"7"},"at":"2026-02-01T00:00:00Z"}`:   `anyOf`,
+		`{"data":null,"next":null,"at":"yesterday"}`:                    `not a date-time`,
+	} {
+		if err := doc.Validate(schema, decode(raw)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want an error mentioning %s", raw, err, want)
+		}
+	}
+}
+
+func TestPathFromRoute(t *testing.T) {
+	if got := openapi.PathFromRoute("/api/v1/bottles/:id/journey.:format"); got != "/api/v1/bottles/{id}/journey.{format}" {
+		t.Fatalf("got %q", got)
+	}
+}
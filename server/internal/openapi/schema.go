@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Generator derives schemas from Go types. Named structs a response renders become
+// component schemas, shared by reference; request bodies and query structs are inlined,
+// since each belongs to one operation.
+//
+// Property names come from json (or query) tags. In a response a property is required
+// unless it is omitempty — encoding/json always writes the rest — and pointers, slices
+// and maps may be null. In a request the validate tag decides: required, min/max,
+// max_graphemes and friends become the matching keywords. A validate rule Generator
+// cannot express panics, so a new rule cannot go undocumented.
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	owners  map[string]reflect.Type
+	extend  map[reflect.Type][]func(*Schema)
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		owners:  make(map[string]reflect.Type),
+		extend:  make(map[reflect.Type][]func(*Schema)),
+	}
+}
+
+// Schemas are the component schemas generated so far.
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Name files sample's type under name instead of its Go name — for types whose Go
+// names collide across packages.
+func (g *Generator) Name(sample any, name string) {
+	g.claim(reflect.TypeOf(sample), name)
+}
+
+// Extend runs fn over the schema generated for sample's type: the place for what
+// reflection cannot see, such as enums or fields a MarshalJSON adds.
+func (g *Generator) Extend(sample any, fn func(*Schema)) {
+	t := reflect.TypeOf(sample)
+	g.extend[t] = append(g.extend[t], fn)
+}
+
+// Define registers a hand-written component schema and returns a reference to it.
+func (g *Generator) Define(name string, s *Schema) *Schema {
+	g.schemas[name] = s
+	return Ref(name)
+}
+
+// Response is the schema for sample as a handler renders it.
+func (g *Generator) Response(sample any) *Schema {
+	return g.schema(reflect.TypeOf(sample), false)
+}
+
+// Body is the inline schema for a JSON request body bound into sample.
+func (g *Generator) Body(sample any) *Schema {
+	return g.schema(reflect.TypeOf(sample), true)
+}
+
+// Query is one parameter per query-tagged field of the struct sample binds.
+func (g *Generator) Query(sample any) []*Parameter {
+	t := reflect.TypeOf(sample)
+	var params []*Parameter
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := tagName(f.Tag.Get("query"))
+		if name == "" {
+			continue
+		}
+		s := g.schema(deref(f.Type), true)
+		required := g.constrain(t, f, s, "query")
+		params = append(params, &Parameter{Name: name, In: "query", Required: required, Schema: s})
+	}
+	return params
+}
+
+func (g *Generator) schema(t reflect.Type, request bool) *Schema {
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(g.schema(t.Elem(), request))
+	case t.Kind() == reflect.Struct && !request && t.Name() != "":
+		return g.component(t)
+	case t.Kind() == reflect.Struct:
+		s = &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}, AdditionalProperties: false}
+		g.fields(t, s, request)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = nullable(&Schema{Type: Types{"array"}, Items: g.schema(t.Elem(), request)})
+	case t.Kind() == reflect.Map:
+		s = nullable(&Schema{Type: Types{"object"}, AdditionalProperties: g.schema(t.Elem(), request)})
+	case t.Kind() == reflect.Interface:
+		s = &Schema{}
+	default:
+		s = scalar(t)
+	}
+	for _, fn := range g.extend[t] {
+		fn(s)
+	}
+	return s
+}
+
+// component generates t's schema once, under its name, and refers to it.
+func (g *Generator) component(t reflect.Type) *Schema {
+	name := g.claim(t, goName(t))
+	if _, ok := g.schemas[name]; ok {
+		return Ref(name)
+	}
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}, AdditionalProperties: false}
+	g.schemas[name] = s
+	g.fields(t, s, false)
+	for _, fn := range g.extend[t] {
+		fn(s)
+	}
+	return Ref(name)
+}
+
+func (g *Generator) claim(t reflect.Type, name string) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	if owner, ok := g.owners[name]; ok && owner != t {
+		panic(fmt.Sprintf("openapi: %s and %s are both named %s; call Name for one", owner, t, name))
+	}
+	g.names[t], g.owners[name] = name, t
+	return name
+}
+
+// fields adds t's json fields to s, flattening untagged embedded structs as
+// encoding/json does.
+func (g *Generator) fields(t reflect.Type, s *Schema, request bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name := tagName(tag)
+		if f.Anonymous && name == "" && deref(f.Type).Kind() == reflect.Struct {
+			g.fields(deref(f.Type), s, request)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.schema(f.Type, request)
+		var required bool
+		if request {
+			required = g.constrain(t, f, prop, "json")
+		} else {
+			required = !strings.Contains(tag, ",omitempty")
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// constrain applies f's validate rules to s and reports whether f is required.
+// Sibling fields in required_with are named by their nameTag ("json" or "query").
+func (g *Generator) constrain(owner reflect.Type, f reflect.StructField, s *Schema, nameTag string) bool {
+	rules := f.Tag.Get("validate")
+	if rules == "" {
+		return false
+	}
+	target := s
+	if len(s.AnyOf) > 0 {
+		target = s.AnyOf[0]
+	}
+	kind := deref(f.Type).Kind()
+	var required bool
+	for _, rule := range strings.Split(rules, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "omitempty":
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				panic(fmt.Sprintf("openapi: %s.%s: bad %s", owner, f.Name, rule))
+			}
+			bound(target, kind, key, n)
+		case "max_graphemes":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				panic(fmt.Sprintf("openapi: %s.%s: bad %s", owner, f.Name, rule))
+			}
+			target.MaxGraphemes = &n
+		case "bcp47_language_tag":
+			target.Format = "bcp47"
+		case "url":
+			target.Format = "uri"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, v)
+			}
+		case "required_with":
+			for _, sibling := range strings.Fields(param) {
+				sf, ok := owner.FieldByName(sibling)
+				if !ok {
+					panic(fmt.Sprintf("openapi: %s.%s: required_with names unknown field %s", owner, f.Name, sibling))
+				}
+				target.RequiredWith = append(target.RequiredWith, tagName(sf.Tag.Get(nameTag)))
+			}
+		default:
+			panic(fmt.Sprintf("openapi: %s.%s: no schema for validate rule %q", owner, f.Name, rule))
+		}
+	}
+	return required
+}
+
+func bound(s *Schema, kind reflect.Kind, key string, n float64) {
+	switch kind {
+	case reflect.String:
+		length := int(n)
+		if key == "min" {
+			s.MinLength = &length
+		} else {
+			s.MaxLength = &length
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if key == "min" {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	default:
+		panic(fmt.Sprintf("openapi: no %s bound for %s", key, kind))
+	}
+}
+
+func scalar(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: Types{"integer"}, Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}, Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32:
+		return &Schema{Type: Types{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: Types{"number"}, Format: "double"}
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// nullable lets s also be null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) == 0:
+		return s // already anything
+	}
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
+
+// goName is t's name without package paths; instantiated generics append their type
+// arguments' names ("CursorResult[domain.Bottle]" → "CursorResultBottle").
+func goName(t reflect.Type) string {
+	name := t.Name()
+	base, args, generic := strings.Cut(name, "[")
+	if !generic {
+		return name
+	}
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		arg = strings.TrimLeft(arg, "*[]")
+		base += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return base
+}
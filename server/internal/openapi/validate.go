@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate checks v, JSON decoded into any, against s, following references into d's
+// components. It understands the keywords Schema has; x-max-graphemes and
+// x-required-with are left to the server's own validation.
+func (d *Document) Validate(s *Schema, v any) error {
+	return d.validate("$", s, v)
+}
+
+func (d *Document) validate(at string, s *Schema, v any) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		target := d.Resolve(s)
+		if target == nil {
+			return fmt.Errorf("%s: unresolved %s", at, s.Ref)
+		}
+		return d.validate(at, target, v)
+	}
+	if len(s.AnyOf) > 0 {
+		var errs []error
+		for _, alt := range s.AnyOf {
+			err := d.validate(at, alt, v)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("%s: matches none of anyOf: %v", at, errs)
+	}
+	if len(s.OneOf) > 0 {
+		var matched int
+		var errs []error
+		for _, alt := range s.OneOf {
+			if err := d.validate(at, alt, v); err != nil {
+				errs = append(errs, err)
+			} else {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: matches %d of oneOf, want 1: %v", at, matched, errs)
+		}
+		return nil
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return isType(t, v) }) {
+		return fmt.Errorf("%s: %s is not %v", at, describe(v), []string(s.Type))
+	}
+	if s.Const != nil && !sameJSON(s.Const, v) {
+		return fmt.Errorf("%s: %s is not %v", at, describe(v), s.Const)
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return sameJSON(e, v) }) {
+		return fmt.Errorf("%s: %s is not one of %v", at, describe(v), s.Enum)
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		return d.validateObject(at, s, v)
+	case []any:
+		for i, item := range v {
+			if err := d.validate(fmt.Sprintf("%s[%d]", at, i), s.Items, item); err != nil {
+				return err
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s: %v below minimum %v", at, v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s: %v above maximum %v", at, v, *s.Maximum)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fmt.Errorf("%s: %d characters, want at least %d", at, n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fmt.Errorf("%s: %d characters, want at most %d", at, n, *s.MaxLength)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", at, v)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(at string, s *Schema, obj map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: missing required %q", at, name)
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prop, ok := s.Properties[k]; ok {
+			if err := d.validate(at+"."+k, prop, obj[k]); err != nil {
+				return err
+			}
+			continue
+		}
+		switch extra := s.AdditionalProperties.(type) {
+		case bool:
+			if !extra {
+				return fmt.Errorf("%s: undocumented property %q", at, k)
+			}
+		case *Schema:
+			if err := d.validate(at+"."+k, extra, obj[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isType(t string, v any) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+// sameJSON compares a schema value and a decoded one as the JSON they would encode to.
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
+
+func describe(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil || len(b) > 60 {
+		return fmt.Sprintf("%T", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+)
+
+const (
+	// MaxFrames bounds one replay; a week at 15-minute steps is 673 frames.
+	MaxFrames = 1000
+	// MinStep is the finest frame spacing; drift itself only moves every 15 minutes.
+	MinStep = time.Minute
+	// MaxBottles bounds how many Corks one replay follows.
+	MaxBottles = 5000
+)
+
+var (
+	ErrEmptyWindow   = errors.New("replay window must end after it starts")
+	ErrStepTooSmall  = errors.New("replay step must be at least 1m")
+	ErrTooManyFrames = errors.New("replay window holds too many frames; widen step or narrow from/to")
+)
+
+// BBox bounds the replay. Like the map viewport it does not wrap the antimeridian.
+type BBox struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+func (b BBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// Window is the time span and sampling of a replay.
+type Window struct {
+	From time.Time
+	To   time.Time
+	Step time.Duration
+}
+
+// Frames is how many frames the window holds, both ends included.
+func (w Window) Frames() int {
+	return int(w.To.Sub(w.From)/w.Step) + 1
+}
+
+func (w Window) Validate() error {
+	switch {
+	case !w.To.After(w.From):
+		return ErrEmptyWindow
+	case w.Step < MinStep:
+		return ErrStepTooSmall
+	case w.To.Sub(w.From)/w.Step >= MaxFrames:
+		return ErrTooManyFrames
+	}
+	return nil
+}
+
+// Point is one stored Journey event of a Cork, in time order per Bottle.
+type Point struct {
+	BottleID    int32
+	BottleStyle int32
+	EventType   domain.EventType
+	At          time.Time
+	Lat         float64
+	Lng         float64
+	// VisibleAt hides the Cork while it is still in Mystery Delay.
+	VisibleAt time.Time
+}
+
+// Cork is one Bottle's position in a frame.
+type Cork struct {
+	BottleID    int32   `json:"bottle_id"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	BottleStyle int32   `json:"bottle_style"`
+}
+
+// Frame is the Ocean at one instant of the replay.
+type Frame struct {
+	Index int       `json:"frame"`
+	At    time.Time `json:"at"`
+	Corks []Cork    `json:"corks"`
+}
+
+// Timeline reconstructs Cork positions at any instant from their Journey events.
+type Timeline struct {
+	window Window
+	bbox   BBox
+	tracks [][]Point
+}
+
+// NewTimeline groups points (ordered by bottle, then time) into per-Bottle tracks.
+func NewTimeline(w Window, bbox BBox, points []Point) *Timeline {
+	t := &Timeline{window: w, bbox: bbox}
+	for i := 0; i < len(points); {
+		j := i
+		for j < len(points) && points[j].BottleID == points[i].BottleID {
+			j++
+		}
+		t.tracks = append(t.tracks, points[i:j])
+		i = j
+	}
+	return t
+}
+
+func (t *Timeline) Frames() int { return t.window.Frames() }
+
+// Frame returns frame k: every Cork afloat inside the bbox at From + k*Step.
+func (t *Timeline) Frame(k int) Frame {
+	at := t.window.From.Add(time.Duration(k) * t.window.Step)
+	f := Frame{Index: k, At: at, Corks: []Cork{}}
+	for _, track := range t.tracks {
+		lat, lng, ok := position(track, at)
+		if ok && t.bbox.Contains(lat, lng) {
+			f.Corks = append(f.Corks, Cork{BottleID: track[0].BottleID, Lat: lat, Lng: lng, BottleStyle: track[0].BottleStyle})
+		}
+	}
+	return f
+}
+
+// position interpolates a track at instant at. Between two drift positions the Cork
+// moves in a straight line; a Re-release is a jump, so it is not slid towards.
+// After a Sink (or legacy claim) the Cork is gone.
+func position(track []Point, at time.Time) (lat, lng float64, ok bool) {
+	if at.Before(track[0].VisibleAt) {
+		return 0, 0, false
+	}
+	i := sort.Search(len(track), func(i int) bool { return track[i].At.After(at) }) - 1
+	if i < 0 {
+		return 0, 0, false
+	}
+	p0 := track[i]
+	if gone(p0.EventType) {
+		return 0, 0, false
+	}
+	if i+1 == len(track) {
+		return p0.Lat, p0.Lng, true
+	}
+	p1 := track[i+1]
+	if p1.EventType != domain.EventTypeDrift {
+		return p0.Lat, p0.Lng, true
+	}
+
+	frac := float64(at.Sub(p0.At)) / float64(p1.At.Sub(p0.At))
+	dLng := p1.Lng - p0.Lng
+	// Take the short way across the antimeridian.
+	if dLng > 180 {
+		dLng -= 360
+	} else if dLng < -180 {
+		dLng += 360
+	}
+	lng = p0.Lng + dLng*frac
+	lng = math.Mod(lng+540, 360) - 180
+	return p0.Lat + (p1.Lat-p0.Lat)*frac, lng, true
+}
+
+func gone(t domain.EventType) bool {
+	return t == domain.EventTypeSink || t == domain.EventTypeOpenedLegacy
+}
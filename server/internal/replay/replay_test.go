@@ -0,0 +1,105 @@
+package replay_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/replay"
+)
+
+var (
+	t0    = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	world = replay.BBox{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}
+)
+
+func at(minutes int) time.Time { return t0.Add(time.Duration(minutes) * time.Minute) }
+
+func corkOf(f replay.Frame, id int32) (replay.Cork, bool) {
+	for _, c := range f.Corks {
+		if c.BottleID == id {
+			return c, true
+		}
+	}
+	return replay.Cork{}, false
+}
+
+func TestFramesInterpolateBetweenDriftEvents(t *testing.T) {
+	points := []replay.Point{
+		{BottleID: 1, EventType: domain.EventTypeCast, At: at(0), Lat: 10, Lng: 20},
+		{BottleID: 1, EventType: domain.EventTypeDrift, At: at(60), Lat: 12, Lng: 24},
+		// Bottle 2 is still in Mystery Delay until minute 30.
+		{BottleID: 2, EventType: domain.EventTypeCast, At: at(0), Lat: -5, Lng: 5, VisibleAt: at(30)},
+	}
+	tl := replay.NewTimeline(replay.Window{From: at(0), To: at(60), Step: 15 * time.Minute}, world, points)
+	if tl.Frames() != 5 {
+		t.Fatalf("want 5 frames, got %d", tl.Frames())
+	}
+
+	c, ok := corkOf(tl.Frame(2), 1)
+	if !ok || c.Lat != 11 || c.Lng != 22 {
+		t.Fatalf("halfway between drift events want (11, 22), got %+v", c)
+	}
+	if _, ok := corkOf(tl.Frame(1), 2); ok {
+		t.Fatal("a Cork in Mystery Delay must not appear in the replay")
+	}
+	if c, ok := corkOf(tl.Frame(4), 2); !ok || c.Lat != -5 {
+		t.Fatalf("after Mystery Delay the Cork should hold its Cast point, got %+v", c)
+	}
+}
+
+func TestFramesJumpOnReReleaseAndDropSunkCorks(t *testing.T) {
+	points := []replay.Point{
+		{BottleID: 1, EventType: domain.EventTypeDrift, At: at(0), Lat: 0, Lng: 0},
+		{BottleID: 1, EventType: domain.EventTypeReReleased, At: at(60), Lat: 40, Lng: 40},
+		{BottleID: 1, EventType: domain.EventTypeSink, At: at(120), Lat: 40, Lng: 40},
+	}
+	tl := replay.NewTimeline(replay.Window{From: at(0), To: at(120), Step: 30 * time.Minute}, world, points)
+
+	if c, _ := corkOf(tl.Frame(1), 1); c.Lat != 0 {
+		t.Fatalf("a Re-release is a jump, not a slide; got %+v", c)
+	}
+	if c, _ := corkOf(tl.Frame(2), 1); c.Lat != 40 {
+		t.Fatalf("want the Re-release point, got %+v", c)
+	}
+	if _, ok := corkOf(tl.Frame(4), 1); ok {
+		t.Fatal("a sunk Cork must leave the replay")
+	}
+}
+
+func TestFramesCrossTheAntimeridianTheShortWay(t *testing.T) {
+	points := []replay.Point{
+		{BottleID: 1, EventType: domain.EventTypeDrift, At: at(0), Lat: 30, Lng: 179},
+		{BottleID: 1, EventType: domain.EventTypeDrift, At: at(60), Lat: 30, Lng: -179},
+	}
+	tl := replay.NewTimeline(replay.Window{From: at(0), To: at(60), Step: 15 * time.Minute}, world, points)
+	c, _ := corkOf(tl.Frame(2), 1)
+	if math.Abs(math.Abs(c.Lng)-180) > 1e-9 {
+		t.Fatalf("halfway across the antimeridian want ±180, got %v", c.Lng)
+	}
+
+	east := replay.BBox{MinLat: -90, MaxLat: 90, MinLng: 0, MaxLng: 180}
+	tl = replay.NewTimeline(replay.Window{From: at(0), To: at(60), Step: 15 * time.Minute}, east, points)
+	if _, ok := corkOf(tl.Frame(4), 1); ok {
+		t.Fatal("Corks that drift out of the bbox must leave the frame")
+	}
+}
+
+func TestWindowIsBounded(t *testing.T) {
+	cases := []struct {
+		w    replay.Window
+		want error
+	}{
+		{replay.Window{From: at(60), To: at(0), Step: time.Hour}, replay.ErrEmptyWindow},
+		{replay.Window{From: at(0), To: at(60), Step: time.Second}, replay.ErrStepTooSmall},
+		{replay.Window{From: at(0), To: at(0).Add(30 * 24 * time.Hour), Step: time.Minute}, replay.ErrTooManyFrames},
+		{replay.Window{From: at(0), To: at(0).Add(7 * 24 * time.Hour), Step: 15 * time.Minute}, nil},
+	}
+	for _, tc := range cases {
+		if err := tc.w.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%+v: want %v, got %v", tc.w, tc.want, err)
+		}
+	}
+}
@@ -4,24 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/discovery"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/lang"
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateBottleParams struct {
-	SenderID         int32
-	Nickname         string
-	MessageText      string
-	BottleStyle      int32
-	StartLat         float64
-	StartLng         float64
-	Status           domain.BottleStatus
-	IsReleased       bool
-	IsScheduled      bool
-	ScheduledRelease pgtype.Timestamptz
+	SenderID    int32
+	Nickname    string
+	MessageText string
+	BottleStyle int32
+	StartLat    float64
+	StartLng    float64
+	Status      domain.BottleStatus
+	IsReleased  bool
+	IsScheduled bool
+	VisibleAt   pgtype.Timestamptz
+	// MessageFingerprint is the simhash of MessageText, stored for near-duplicate checks.
+	MessageFingerprint uint64
+	// Language stems MessageText for search; the zero value indexes it unstemmed.
+	Language lang.Language
 }
 
 type FindNearbyParams struct {
@@ -32,6 +40,34 @@ type FindNearbyParams struct {
 	Limit     int32
 }
 
+// SearchParams matches Query against Messages of Corks inside the box. CursorID and
+// CursorRank come together from the previous page's NextCursor.
+type SearchParams struct {
+	Query      string
+	MinLat     float64
+	MaxLat     float64
+	MinLng     float64
+	MaxLng     float64
+	CursorID   *int32
+	CursorRank *float32
+	Limit      int32
+}
+
+// SampleParams seeks the sampling index from FromKey in [0, 1), taking up to Limit
+// Corks from anywhere and as many again from Basin when it is set.
+type SampleParams struct {
+	FromKey float64
+	Limit   int32
+	Basin   string
+}
+
+// BottleMove is one Bottle's new position from a drift tick.
+type BottleMove struct {
+	ID  int32
+	Lat float64
+	Lng float64
+}
+
 type BottleRepository interface {
 	Create(ctx context.Context, params CreateBottleParams) (*domain.Bottle, error)
 	GetByID(ctx context.Context, id int32) (*domain.Bottle, error)
@@ -39,10 +75,26 @@ type BottleRepository interface {
 	UpdateStatus(ctx context.Context, id int32, status domain.BottleStatus) (*domain.Bottle, error)
 	// UpdatePosition moves the bottle to new coordinates, increments hops, and sets status.
 	UpdatePosition(ctx context.Context, id int32, lat, lng float64, status domain.BottleStatus) (*domain.Bottle, error)
+	// MoveDrifting applies a drift tick's positions in one statement. Hops are untouched,
+	// and Bottles no longer drifting are skipped; it returns the IDs that moved.
+	MoveDrifting(ctx context.Context, moves []BottleMove) ([]int32, error)
 	// ListActive returns all bottles currently drifting that have been released.
 	ListActive(ctx context.Context) ([]domain.Bottle, error)
 	ReleaseScheduled(ctx context.Context) ([]domain.Bottle, error)
 	FindNearby(ctx context.Context, params FindNearbyParams) (*domain.CursorResult[domain.Bottle], error)
+	// RecentFingerprints returns Message fingerprints of every Bottle cast since the given time.
+	RecentFingerprints(ctx context.Context, since time.Time) ([]uint64, error)
+	// Search full-text matches visible drifting Messages (migration 00013), best first.
+	Search(ctx context.Context, params SearchParams) (*domain.CursorResult[domain.SearchHit], error)
+	// SampleDrifting draws visible drifting Corks from the sampling index (migration 00012)
+	// without scanning bottles.
+	SampleDrifting(ctx context.Context, params SampleParams) ([]discovery.Candidate, error)
+	// Generation is the Ocean generation (migration 00015). It moves after every committed
+	// write to Bottles or their events, so it never runs ahead of what a reader sees.
+	Generation(ctx context.Context) (int32, error)
+	// BumpGeneration advances the Ocean generation. Call it once a write has committed,
+	// never inside the transaction.
+	BumpGeneration(ctx context.Context) error
 
 	// WithTx returns a new repository instance that uses the provided transaction for all operations.
 	WithTx(q *ocealis.Queries) BottleRepository
@@ -60,25 +112,49 @@ func (r *postgresBottleRepo) WithTx(q *ocealis.Queries) BottleRepository {
 	return &postgresBottleRepo{q: q}
 }
 
-func (r *postgresBottleRepo) Create(ctx context.Context, params CreateBottleParams) (*domain.Bottle, error) {
+func (r *postgresBottleRepo) Create(ctx context.Context, params CreateBottleParams) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.Create")
+	defer func() { telemetry.End(span, err) }()
+
 	row, err := r.q.CreateBottle(ctx, ocealis.CreateBottleParams{
-		SenderID:         pgtype.Int4{Int32: params.SenderID, Valid: true},
-		Nickname:         params.Nickname,
-		MessageText:      params.MessageText,
-		BottleStyle:      pgtype.Int4{Int32: params.BottleStyle, Valid: true},
-		StartLat:         pgtype.Float8{Float64: params.StartLat, Valid: true},
-		StartLng:         pgtype.Float8{Float64: params.StartLng, Valid: true},
-		Status:           string(params.Status),
-		IsRelease:        pgtype.Bool{Bool: params.IsReleased, Valid: true},
-		ScheduledRelease: params.ScheduledRelease,
+		SenderID:    pgtype.Int4{Int32: params.SenderID, Valid: true},
+		Nickname:    params.Nickname,
+		MessageText: params.MessageText,
+		BottleStyle: pgtype.Int4{Int32: params.BottleStyle, Valid: true},
+		StartLat:    pgtype.Float8{Float64: params.StartLat, Valid: true},
+		StartLng:    pgtype.Float8{Float64: params.StartLng, Valid: true},
+		Status:      string(params.Status),
+		IsRelease:   pgtype.Bool{Bool: params.IsReleased, Valid: true},
+		VisibleAt:   params.VisibleAt,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := r.q.CreateBottleFingerprint(ctx, ocealis.CreateBottleFingerprintParams{
+		BottleID:    row.ID,
+		Fingerprint: int64(params.MessageFingerprint),
+	}); err != nil {
+		return nil, fmt.Errorf("store fingerprint: %w", err)
+	}
+	language := params.Language
+	if language.Config == "" {
+		language = lang.Undetermined
+	}
+	if err := r.q.CreateBottleSearch(ctx, ocealis.CreateBottleSearchParams{
+		BottleID:    row.ID,
+		Language:    language.Code,
+		Config:      language.Config,
+		MessageText: row.MessageText,
+	}); err != nil {
+		return nil, fmt.Errorf("index message: %w", err)
+	}
 	return mapBottle(row), nil
 }
 
-func (r *postgresBottleRepo) GetByID(ctx context.Context, id int32) (*domain.Bottle, error) {
+func (r *postgresBottleRepo) GetByID(ctx context.Context, id int32) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.GetByID")
+	defer func() { telemetry.End(span, err) }()
+
 	row, err := r.q.GetBottle(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -89,7 +165,10 @@ func (r *postgresBottleRepo) GetByID(ctx context.Context, id int32) (*domain.Bot
 	return mapBottle(row), nil
 }
 
-func (r *postgresBottleRepo) UpdateStatus(ctx context.Context, id int32, status domain.BottleStatus) (*domain.Bottle, error) {
+func (r *postgresBottleRepo) UpdateStatus(ctx context.Context, id int32, status domain.BottleStatus) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.UpdateStatus")
+	defer func() { telemetry.End(span, err) }()
+
 	row, err := r.q.UpdateBottleStatus(ctx, ocealis.UpdateBottleStatusParams{
 		ID:     id,
 		Status: string(status),
@@ -100,7 +179,10 @@ func (r *postgresBottleRepo) UpdateStatus(ctx context.Context, id int32, status
 	return mapBottle(row), nil
 }
 
-func (r *postgresBottleRepo) UpdatePosition(ctx context.Context, id int32, lat, lng float64, status domain.BottleStatus) (*domain.Bottle, error) {
+func (r *postgresBottleRepo) UpdatePosition(ctx context.Context, id int32, lat, lng float64, status domain.BottleStatus) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.UpdatePosition")
+	defer func() { telemetry.End(span, err) }()
+
 	row, err := r.q.UpdateBottlePosition(ctx, ocealis.UpdateBottlePositionParams{
 		ID:         id,
 		CurrentLat: pgtype.Float8{Float64: lat, Valid: true},
@@ -113,7 +195,25 @@ func (r *postgresBottleRepo) UpdatePosition(ctx context.Context, id int32, lat,
 	return mapBottle(row), nil
 }
 
-func (r *postgresBottleRepo) ListActive(ctx context.Context) ([]domain.Bottle, error) {
+func (r *postgresBottleRepo) MoveDrifting(ctx context.Context, moves []BottleMove) (_ []int32, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.MoveDrifting")
+	defer func() { telemetry.End(span, err) }()
+
+	arg := ocealis.MoveDriftingBottlesParams{
+		Ids:  make([]int32, len(moves)),
+		Lats: make([]float64, len(moves)),
+		Lngs: make([]float64, len(moves)),
+	}
+	for i, m := range moves {
+		arg.Ids[i], arg.Lats[i], arg.Lngs[i] = m.ID, m.Lat, m.Lng
+	}
+	return r.q.MoveDriftingBottles(ctx, arg)
+}
+
+func (r *postgresBottleRepo) ListActive(ctx context.Context) (_ []domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.ListActive")
+	defer func() { telemetry.End(span, err) }()
+
 	rows, err := r.q.ListActiveDriftingBottles(ctx)
 	if err != nil {
 		return nil, err
@@ -125,7 +225,10 @@ func (r *postgresBottleRepo) ListActive(ctx context.Context) ([]domain.Bottle, e
 	return bottles, nil
 }
 
-func (r *postgresBottleRepo) FindNearby(ctx context.Context, params FindNearbyParams) (*domain.CursorResult[domain.Bottle], error) {
+func (r *postgresBottleRepo) FindNearby(ctx context.Context, params FindNearbyParams) (_ *domain.CursorResult[domain.Bottle], err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.FindNearby")
+	defer func() { telemetry.End(span, err) }()
+
 	var cursorID int32
 	if params.Cursor != nil {
 		cursorID = *params.Cursor
@@ -164,12 +267,110 @@ func (r *postgresBottleRepo) FindNearby(ctx context.Context, params FindNearbyPa
 	return result, nil
 }
 
+func (r *postgresBottleRepo) RecentFingerprints(ctx context.Context, since time.Time) (_ []uint64, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.RecentFingerprints")
+	defer func() { telemetry.End(span, err) }()
+
+	rows, err := r.q.ListRecentFingerprints(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("list recent fingerprints: %w", err)
+	}
+	fps := make([]uint64, 0, len(rows))
+	for _, fp := range rows {
+		fps = append(fps, uint64(fp))
+	}
+	return fps, nil
+}
+
+func (r *postgresBottleRepo) Generation(ctx context.Context) (_ int32, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.Generation")
+	defer func() { telemetry.End(span, err) }()
+
+	gen, err := r.q.GetOceanGeneration(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get ocean generation: %w", err)
+	}
+	return gen, nil
+}
+
+func (r *postgresBottleRepo) BumpGeneration(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.BumpGeneration")
+	defer func() { telemetry.End(span, err) }()
+
+	if err = r.q.BumpOceanGeneration(ctx); err != nil {
+		return fmt.Errorf("bump ocean generation: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresBottleRepo) Search(ctx context.Context, params SearchParams) (_ *domain.CursorResult[domain.SearchHit], err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.Search")
+	defer func() { telemetry.End(span, err) }()
+
+	arg := ocealis.SearchBottlesParams{
+		Q:      params.Query,
+		MinLat: params.MinLat,
+		MaxLat: params.MaxLat,
+		MinLng: params.MinLng,
+		MaxLng: params.MaxLng,
+		Lim:    params.Limit + 1, // one extra to detect hasMore
+	}
+	if params.CursorID != nil && params.CursorRank != nil {
+		arg.CursorID = pgtype.Int4{Int32: *params.CursorID, Valid: true}
+		arg.CursorRank = pgtype.Float4{Float32: *params.CursorRank, Valid: true}
+	}
+	rows, err := r.q.SearchBottles(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("search bottles: %w", err)
+	}
+
+	hasMore := len(rows) > int(params.Limit)
+	if hasMore {
+		rows = rows[:params.Limit]
+	}
+	hits := make([]domain.SearchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, domain.SearchHit{Bottle: *mapBottle(row.Bottle), Rank: row.Rank})
+	}
+
+	result := &domain.CursorResult[domain.SearchHit]{Data: hits, HasMore: hasMore}
+	if hasMore && len(hits) > 0 {
+		last := hits[len(hits)-1]
+		result.NextCursor = &domain.Cursor{LastID: &last.ID, LastRank: &last.Rank}
+	}
+	return result, nil
+}
+
+func (r *postgresBottleRepo) SampleDrifting(ctx context.Context, params SampleParams) (_ []discovery.Candidate, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.SampleDrifting")
+	defer func() { telemetry.End(span, err) }()
+
+	rows, err := r.q.ListSampleCandidates(ctx, ocealis.ListSampleCandidatesParams{
+		FromKey: params.FromKey,
+		Lim:     params.Limit,
+		Basin:   params.Basin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list sample candidates: %w", err)
+	}
+	candidates := make([]discovery.Candidate, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, discovery.Candidate{
+			Bottle:     *mapBottle(row.Bottle),
+			Basin:      row.Basin,
+			ReReleases: row.ReReleases,
+			Stamps:     row.Stamps,
+		})
+	}
+	return candidates, nil
+}
+
 func mapBottle(row ocealis.Bottle) *domain.Bottle {
 	b := &domain.Bottle{
 		ID:          row.ID,
 		Nickname:    row.Nickname,
 		MessageText: row.MessageText,
-		Status:      domain.BottleStatus(row.Status),
+		Status:      domain.ParseBottleStatus(row.Status),
 	}
 
 	if row.SenderID.Valid {
@@ -198,9 +399,8 @@ func mapBottle(row ocealis.Bottle) *domain.Bottle {
 	if row.Hops.Valid {
 		b.Hops = row.Hops.Int32
 	}
-	if row.ScheduledRelease.Valid {
-		b.ScheduledRelease = row.ScheduledRelease.Time
-		b.VisibleAt = row.ScheduledRelease.Time // Mystery Delay end
+	if row.VisibleAt.Valid {
+		b.VisibleAt = row.VisibleAt.Time // Mystery Delay end
 	}
 	if row.IsRelease.Valid {
 		b.IsReleased = row.IsRelease.Bool
@@ -211,7 +411,10 @@ func mapBottle(row ocealis.Bottle) *domain.Bottle {
 	return b
 }
 
-func (r *postgresBottleRepo) ReleaseScheduled(ctx context.Context) ([]domain.Bottle, error) {
+func (r *postgresBottleRepo) ReleaseScheduled(ctx context.Context) (_ []domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleRepository.ReleaseScheduled")
+	defer func() { telemetry.End(span, err) }()
+
 	rows, err := r.q.ListScheduledBottles(ctx)
 	if err != nil {
 		return nil, err
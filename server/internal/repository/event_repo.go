@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Polqt/ocealis/db/ocealis"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -14,6 +17,8 @@ type CreateEventParams struct {
 	EventType domain.EventType
 	Lat       float64
 	Lng       float64
+	// Payload must match EventType; nil stores an empty payload.
+	Payload domain.EventPayload
 }
 
 type GetEventParams struct {
@@ -24,8 +29,17 @@ type GetEventParams struct {
 
 type EventRepository interface {
 	Create(ctx context.Context, params CreateEventParams) (*domain.BottleEvent, error)
+	// CreateBatch writes many events with one COPY; it returns rows written, not the events.
+	CreateBatch(ctx context.Context, params []CreateEventParams) (int64, error)
 	GetByBottleID(ctx context.Context, bottleID int32) ([]domain.BottleEvent, error)
 	GetPaginated(ctx context.Context, params GetEventParams) (*domain.CursorResult[domain.BottleEvent], error)
+	// GetHighlights returns every non-drift event plus about driftSamples evenly spaced drift events, oldest first.
+	GetHighlights(ctx context.Context, bottleID, driftSamples int32) ([]domain.BottleEvent, error)
+	// ListReplay returns the Journey points of every Cork seen inside the bbox during the
+	// window (plus each one's last position before it), ordered by bottle then time.
+	ListReplay(ctx context.Context, w replay.Window, bbox replay.BBox) ([]replay.Point, error)
+	// CompactDrift collapses drift events older than before into daily waypoints; returns rows deleted.
+	CompactDrift(ctx context.Context, before time.Time) (int64, error)
 	WithTx(q *ocealis.Queries) EventRepository
 }
 
@@ -41,21 +55,54 @@ func (r *postgresEventRepo) WithTx(q *ocealis.Queries) EventRepository {
 	return &postgresEventRepo{q: q}
 }
 
-func (r *postgresEventRepo) Create(ctx context.Context, params CreateEventParams) (*domain.BottleEvent, error) {
+func (r *postgresEventRepo) Create(ctx context.Context, params CreateEventParams) (_ *domain.BottleEvent, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.Create")
+	defer func() { telemetry.End(span, err) }()
+
+	payload, err := domain.MarshalEventPayload(params.EventType, params.Payload)
+	if err != nil {
+		return nil, err
+	}
+
 	row, err := r.q.CreateBottleEvent(ctx, ocealis.CreateBottleEventParams{
 		BottleID:  pgtype.Int4{Int32: params.BottleID, Valid: true},
 		EventType: string(params.EventType),
 		Lat:       pgtype.Float8{Float64: params.Lat, Valid: true},
 		Lng:       pgtype.Float8{Float64: params.Lng, Valid: true},
+		Payload:   payload,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return mapEvent(row), nil
+	return mapEvent(row)
 }
 
-func (r *postgresEventRepo) GetByBottleID(ctx context.Context, bottleID int32) ([]domain.BottleEvent, error) {
+func (r *postgresEventRepo) CreateBatch(ctx context.Context, params []CreateEventParams) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.CreateBatch")
+	defer func() { telemetry.End(span, err) }()
+
+	rows := make([]ocealis.CreateDriftEventsParams, len(params))
+	for i, p := range params {
+		payload, err := domain.MarshalEventPayload(p.EventType, p.Payload)
+		if err != nil {
+			return 0, fmt.Errorf("event for bottle %d: %w", p.BottleID, err)
+		}
+		rows[i] = ocealis.CreateDriftEventsParams{
+			BottleID:  pgtype.Int4{Int32: p.BottleID, Valid: true},
+			EventType: string(p.EventType),
+			Lat:       pgtype.Float8{Float64: p.Lat, Valid: true},
+			Lng:       pgtype.Float8{Float64: p.Lng, Valid: true},
+			Payload:   payload,
+		}
+	}
+	return r.q.CreateDriftEvents(ctx, rows)
+}
+
+func (r *postgresEventRepo) GetByBottleID(ctx context.Context, bottleID int32) (_ []domain.BottleEvent, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.GetByBottleID")
+	defer func() { telemetry.End(span, err) }()
+
 	rows, err := r.q.GetBottleEvents(ctx, pgtype.Int4{Int32: bottleID, Valid: true})
 	if err != nil {
 		return nil, err
@@ -63,13 +110,20 @@ func (r *postgresEventRepo) GetByBottleID(ctx context.Context, bottleID int32) (
 
 	events := make([]domain.BottleEvent, 0, len(rows))
 	for _, row := range rows {
-		events = append(events, *mapEvent(row))
+		e, err := mapEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
 	}
 
 	return events, nil
 }
 
-func (r *postgresEventRepo) GetPaginated(ctx context.Context, params GetEventParams) (*domain.CursorResult[domain.BottleEvent], error) {
+func (r *postgresEventRepo) GetPaginated(ctx context.Context, params GetEventParams) (_ *domain.CursorResult[domain.BottleEvent], err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.GetPaginated")
+	defer func() { telemetry.End(span, err) }()
+
 	var cursorID int32
 	if params.Cursor != nil {
 		cursorID = *params.Cursor
@@ -90,7 +144,11 @@ func (r *postgresEventRepo) GetPaginated(ctx context.Context, params GetEventPar
 
 	events := make([]domain.BottleEvent, 0, len(rows))
 	for _, row := range rows {
-		events = append(events, *mapEvent(row))
+		e, err := mapEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
 	}
 
 	result := &domain.CursorResult[domain.BottleEvent]{
@@ -107,11 +165,79 @@ func (r *postgresEventRepo) GetPaginated(ctx context.Context, params GetEventPar
 	return result, nil
 }
 
-func mapEvent(row ocealis.BottleEvent) *domain.BottleEvent {
+func (r *postgresEventRepo) GetHighlights(ctx context.Context, bottleID, driftSamples int32) (_ []domain.BottleEvent, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.GetHighlights")
+	defer func() { telemetry.End(span, err) }()
+
+	rows, err := r.q.GetJourneyHighlights(ctx, ocealis.GetJourneyHighlightsParams{
+		Samples:  driftSamples,
+		BottleID: pgtype.Int4{Int32: bottleID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get journey highlights: %w", err)
+	}
+
+	events := make([]domain.BottleEvent, 0, len(rows))
+	for _, row := range rows {
+		e, err := mapEvent(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	return events, nil
+}
+
+func (r *postgresEventRepo) CompactDrift(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.CompactDrift")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.q.CompactDriftEvents(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+}
+
+func (r *postgresEventRepo) ListReplay(ctx context.Context, w replay.Window, bbox replay.BBox) (_ []replay.Point, err error) {
+	ctx, span := telemetry.Start(ctx, "EventRepository.ListReplay")
+	defer func() { telemetry.End(span, err) }()
+
+	rows, err := r.q.ListReplayEvents(ctx, ocealis.ListReplayEventsParams{
+		FromAt:     pgtype.Timestamptz{Time: w.From, Valid: true},
+		ToAt:       pgtype.Timestamptz{Time: w.To, Valid: true},
+		MinLat:     bbox.MinLat,
+		MaxLat:     bbox.MaxLat,
+		MinLng:     bbox.MinLng,
+		MaxLng:     bbox.MaxLng,
+		MaxBottles: replay.MaxBottles,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list replay events: %w", err)
+	}
+
+	points := make([]replay.Point, 0, len(rows))
+	for _, row := range rows {
+		points = append(points, replay.Point{
+			BottleID:    row.BottleID.Int32,
+			BottleStyle: row.BottleStyle.Int32,
+			EventType:   domain.ParseEventType(row.EventType),
+			At:          row.CreatedAt.Time,
+			Lat:         row.Lat.Float64,
+			Lng:         row.Lng.Float64,
+			VisibleAt:   row.VisibleAt.Time,
+		})
+	}
+	return points, nil
+}
+
+func mapEvent(row ocealis.BottleEvent) (*domain.BottleEvent, error) {
 	e := &domain.BottleEvent{
 		ID:        row.ID,
-		EventType: domain.EventType(row.EventType),
+		EventType: domain.ParseEventType(row.EventType),
+	}
+
+	payload, err := domain.UnmarshalEventPayload(e.EventType, row.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("event %d: %w", row.ID, err)
 	}
+	e.Payload = payload
 
 	if row.BottleID.Valid {
 		e.BottleID = row.BottleID.Int32
@@ -126,5 +252,5 @@ func mapEvent(row ocealis.BottleEvent) *domain.BottleEvent {
 		e.CreatedAt = row.CreatedAt.Time
 	}
 
-	return e
+	return e, nil
 }
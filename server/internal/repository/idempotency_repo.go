@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IdempotencyRepository interface {
+	// Claim reserves key for requestHash until expiresAt.
+	// When the key is already held, claimed is false and existing is the stored record.
+	Claim(ctx context.Context, key, requestHash string, expiresAt time.Time) (existing *domain.IdempotentResponse, claimed bool, err error)
+	// Complete stores the response to replay for later retries.
+	Complete(ctx context.Context, key string, statusCode int, body []byte) error
+	// Release forgets a claim whose request failed, so the client may retry it.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired prunes keys past their replay window.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type postgresIdempotencyRepo struct {
+	q *ocealis.Queries
+}
+
+func NewIdempotencyRepository(q *ocealis.Queries) IdempotencyRepository {
+	return &postgresIdempotencyRepo{q: q}
+}
+
+func (r *postgresIdempotencyRepo) Claim(ctx context.Context, key, requestHash string, expiresAt time.Time) (_ *domain.IdempotentResponse, _ bool, err error) {
+	ctx, span := telemetry.Start(ctx, "IdempotencyRepository.Claim")
+	defer func() { telemetry.End(span, err) }()
+
+	for attempt := 0; ; attempt++ {
+		_, err = r.q.ClaimIdempotencyKey(ctx, ocealis.ClaimIdempotencyKeyParams{
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if err == nil {
+			return nil, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, fmt.Errorf("claim idempotency key: %w", err)
+		}
+
+		// Conflict on a live key — hand back what is stored.
+		row, err := r.q.GetIdempotencyKey(ctx, key)
+		switch {
+		case err == nil:
+			return mapIdempotentResponse(row), false, nil
+		case !errors.Is(err, pgx.ErrNoRows):
+			return nil, false, fmt.Errorf("get idempotency key: %w", err)
+		case attempt == 1:
+			// Released twice under us: report it in flight so the client retries later.
+			return &domain.IdempotentResponse{RequestHash: requestHash}, false, nil
+		}
+		// The holder failed and released the key between the two queries; claim again.
+	}
+}
+
+func (r *postgresIdempotencyRepo) Complete(ctx context.Context, key string, statusCode int, body []byte) (err error) {
+	ctx, span := telemetry.Start(ctx, "IdempotencyRepository.Complete")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.q.CompleteIdempotencyKey(ctx, ocealis.CompleteIdempotencyKeyParams{
+		Key:          key,
+		StatusCode:   pgtype.Int4{Int32: int32(statusCode), Valid: true},
+		ResponseBody: body,
+	})
+}
+
+func (r *postgresIdempotencyRepo) Release(ctx context.Context, key string) (err error) {
+	ctx, span := telemetry.Start(ctx, "IdempotencyRepository.Release")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.q.DeleteIdempotencyKey(ctx, key)
+}
+
+func (r *postgresIdempotencyRepo) DeleteExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "IdempotencyRepository.DeleteExpired")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.q.DeleteExpiredIdempotencyKeys(ctx)
+}
+
+func mapIdempotentResponse(row ocealis.IdempotencyKey) *domain.IdempotentResponse {
+	res := &domain.IdempotentResponse{
+		RequestHash: row.RequestHash,
+		Body:        row.ResponseBody,
+	}
+	if row.StatusCode.Valid {
+		res.StatusCode = int(row.StatusCode.Int32)
+	}
+	return res
+}
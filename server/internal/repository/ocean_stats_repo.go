@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/geo"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OceanStatsRepository reads the trigger-kept counters from migrations 00010 and 00011.
+type OceanStatsRepository interface {
+	// Stats reads every counter; Casts24h and Stamps24h sum the hourly buckets from since.
+	Stats(ctx context.Context, since time.Time) (*domain.OceanStats, error)
+	// PruneActivity drops hourly activity buckets older than before.
+	PruneActivity(ctx context.Context, before time.Time) (int64, error)
+	// Notable reads the top limit drifting Bottles in each Journey ranking.
+	Notable(ctx context.Context, limit int32) (*domain.NotableRankings, error)
+}
+
+type postgresOceanStatsRepo struct {
+	q *ocealis.Queries
+}
+
+func NewOceanStatsRepository(q *ocealis.Queries) OceanStatsRepository {
+	return &postgresOceanStatsRepo{q: q}
+}
+
+func (r *postgresOceanStatsRepo) Stats(ctx context.Context, since time.Time) (_ *domain.OceanStats, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanStatsRepository.Stats")
+	defer func() { telemetry.End(span, err) }()
+
+	counters, err := r.q.ListOceanCounters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list ocean counters: %w", err)
+	}
+	activity, err := r.q.SumOceanActivity(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("sum ocean activity: %w", err)
+	}
+
+	stats := &domain.OceanStats{Basins: make(map[string]int64, len(geo.Basins))}
+	for _, b := range geo.Basins {
+		stats.Basins[b] = 0
+	}
+	for _, c := range counters {
+		n := int64(math.Round(c.Value))
+		switch kind, key, _ := strings.Cut(c.Name, ":"); kind {
+		case "status":
+			// Until 00016 rewrites them, pre-rename rows count under status:scheduled.
+			switch domain.ParseBottleStatus(key) {
+			case domain.BottleStatusDrifting:
+				stats.Drifting += n
+			case domain.BottleStatusMysteryDelay:
+				stats.MysteryDelay += n
+			case domain.BottleStatusSunk:
+				stats.Sunk += n
+			}
+		case "basin":
+			stats.Basins[key] = n
+		case "km_drifted":
+			stats.KmDrifted = math.Round(c.Value*10) / 10
+		}
+	}
+	for _, a := range activity {
+		switch domain.EventType(a.Kind) {
+		case domain.EventTypeCast:
+			stats.Casts24h = a.N
+		case domain.EventTypeStamp:
+			stats.Stamps24h = a.N
+		}
+	}
+	return stats, nil
+}
+
+func (r *postgresOceanStatsRepo) PruneActivity(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanStatsRepository.PruneActivity")
+	defer func() { telemetry.End(span, err) }()
+
+	return r.q.DeleteOceanActivityBefore(ctx, pgtype.Timestamptz{Time: before, Valid: true})
+}
+
+func (r *postgresOceanStatsRepo) Notable(ctx context.Context, limit int32) (_ *domain.NotableRankings, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanStatsRepository.Notable")
+	defer func() { telemetry.End(span, err) }()
+
+	rows, err := r.q.ListNotableBottles(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list notable bottles: %w", err)
+	}
+
+	out := &domain.NotableRankings{
+		LongestVoyages: []domain.NotableBottle{},
+		MostReReleased: []domain.NotableBottle{},
+		MostStamped:    []domain.NotableBottle{},
+		MostBasins:     []domain.NotableBottle{},
+		Oldest:         []domain.NotableBottle{},
+	}
+	for _, row := range rows {
+		basins := row.Basins
+		if basins == nil {
+			basins = []string{}
+		}
+		b := domain.NotableBottle{
+			BottleID:    row.ID,
+			BottleStyle: row.BottleStyle.Int32,
+			Lat:         row.CurrentLat.Float64,
+			Lng:         row.CurrentLng.Float64,
+			CastAt:      row.CreatedAt.Time,
+			DistanceKm:  math.Round(row.DistanceKm*10) / 10,
+			ReReleases:  row.ReReleases,
+			Stamps:      row.Stamps,
+			Basins:      basins,
+		}
+		switch row.Ranking {
+		case "longest_voyages":
+			out.LongestVoyages = append(out.LongestVoyages, b)
+		case "most_re_released":
+			out.MostReReleased = append(out.MostReReleased, b)
+		case "most_stamped":
+			out.MostStamped = append(out.MostStamped, b)
+		case "most_basins":
+			out.MostBasins = append(out.MostBasins, b)
+		case "oldest":
+			out.Oldest = append(out.Oldest, b)
+		}
+	}
+	return out, nil
+}
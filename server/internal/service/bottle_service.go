@@ -6,13 +6,20 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Polqt/ocealis/db"
 	"github.com/Polqt/ocealis/db/ocealis"
 	"github.com/Polqt/ocealis/internal/cast"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/lang"
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/Polqt/ocealis/internal/translate"
 	"github.com/Polqt/ocealis/ws"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -22,6 +29,8 @@ var (
 	ErrBottleNotFound       = errors.New("bottle not found")
 	ErrAlreadyDiscovered    = errors.New("bottle already discovered")
 	ErrSenderCannotDiscover = errors.New("sender cannot discover their own bottle")
+	// ErrTranslationDisabled means no Translator is configured.
+	ErrTranslationDisabled = errors.New("translation not configured")
 )
 
 type CreateBottleInput struct {
@@ -40,12 +49,55 @@ type DiscoverBottleInput struct {
 	UserLng    float64
 }
 
+type ReleaseBottleInput struct {
+	BottleID int32
+	UserID   int32
+	Lat      float64
+	Lng      float64
+	// Nickname of the finder doing the Re-release; optional.
+	Nickname string
+}
+
 type BottleService interface {
 	CreateBottle(ctx context.Context, input CreateBottleInput) (*domain.Bottle, error)
 	GetBottle(ctx context.Context, id int32) (*domain.Bottle, error)
+	// BottleVersion changes whenever GetBottle or GetJourney would answer differently;
+	// 0 means unknown. It is the Ocean generation, which moves after every committed
+	// write; a Bottle's own newest event ID can commit out of order.
+	// Conditional GETs compare it before loading anything else.
+	BottleVersion(ctx context.Context, id int32) (int32, error)
 	GetJourney(ctx context.Context, bottleID int32) (*domain.Journey, error)
+	// GetJourneyHighlights is the short Journey: Cast, Stamps, Re-releases, Sink and
+	// about driftSamples evenly spaced drift waypoints.
+	GetJourneyHighlights(ctx context.Context, bottleID int32, driftSamples int) (*domain.Journey, error)
 	DiscoverBottle(ctx context.Context, input DiscoverBottleInput) (*domain.Journey, error)
-	ReleaseBottle(ctx context.Context, bottleID, userID int32, lat, lng float64) (*domain.Bottle, error)
+	ReleaseBottle(ctx context.Context, input ReleaseBottleInput) (*domain.Bottle, error)
+	// TranslateBottle is GetBottle with the Message also rendered in target, an
+	// ISO 639-1 code.
+	TranslateBottle(ctx context.Context, id int32, target string) (*domain.TranslatedBottle, error)
+}
+
+// BottleOption tunes a bottle service; tests use it to pin Mystery Delays.
+type BottleOption func(*bottleService)
+
+// WithBottleClock replaces time.Now for Cast.
+func WithBottleClock(now func() time.Time) BottleOption {
+	return func(s *bottleService) { s.now = now }
+}
+
+// WithBottleTx replaces the transaction runner.
+func WithBottleTx(tx TxFunc) BottleOption {
+	return func(s *bottleService) { s.inTx = tx }
+}
+
+// WithBottleRand sets the source Mystery Delays are drawn from.
+func WithBottleRand(src rand.Source) BottleOption {
+	return func(s *bottleService) { s.rng = rand.New(src) }
+}
+
+// WithTranslator enables TranslateBottle; without one it reports ErrTranslationDisabled.
+func WithTranslator(t translate.Translator) BottleOption {
+	return func(s *bottleService) { s.translator = t }
 }
 
 type bottleService struct {
@@ -53,6 +105,13 @@ type bottleService struct {
 	bottles repository.BottleRepository
 	events  repository.EventRepository
 	bc      *ws.Broadcaster
+	inTx    TxFunc
+	now     func() time.Time
+
+	translator translate.Translator
+
+	rngMu sync.Mutex // *rand.Rand is not safe for concurrent Casts
+	rng   *rand.Rand
 }
 
 func NewBottleService(
@@ -60,19 +119,43 @@ func NewBottleService(
 	bottles repository.BottleRepository,
 	events repository.EventRepository,
 	bc *ws.Broadcaster,
+	opts ...BottleOption,
 ) BottleService {
-	return &bottleService{pool: pool, bottles: bottles, events: events, bc: bc}
+	s := &bottleService{
+		pool:    pool,
+		bottles: bottles,
+		events:  events,
+		bc:      bc,
+		now:     time.Now,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	s.inTx = func(ctx context.Context, fn func(q *ocealis.Queries) error) error {
+		return db.WithTransaction(ctx, s.pool, fn)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *bottleService) CreateBottle(ctx context.Context, input CreateBottleInput) (*domain.Bottle, error) {
-	plan, err := cast.Prepare(input.Nickname, input.MessageText, input.StartLat, input.StartLng, time.Now(), rand.New(rand.NewSource(time.Now().UnixNano())))
+func (s *bottleService) CreateBottle(ctx context.Context, input CreateBottleInput) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.CreateBottle")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := styles.Castable(input.BottleStyle); err != nil {
+		return nil, err
+	}
+
+	s.rngMu.Lock()
+	plan, err := cast.Prepare(input.Nickname, input.MessageText, input.StartLat, input.StartLng, s.now(), s.rng)
+	s.rngMu.Unlock()
 	if err != nil {
 		return nil, err
 	}
 
 	var bottle *domain.Bottle
 
-	err = db.WithTransaction(ctx, s.pool, func(q *ocealis.Queries) error {
+	err = s.inTx(ctx, func(q *ocealis.Queries) error {
 		bottlesTx := s.bottles.WithTx(q)
 		eventsTx := s.events.WithTx(q)
 
@@ -87,10 +170,12 @@ func (s *bottleService) CreateBottle(ctx context.Context, input CreateBottleInpu
 			IsScheduled: true,
 			Status:      plan.Status,
 			IsReleased:  plan.IsReleased,
-			ScheduledRelease: pgtype.Timestamptz{
+			VisibleAt: pgtype.Timestamptz{
 				Time:  plan.VisibleAt,
 				Valid: true,
 			},
+			MessageFingerprint: plan.Fingerprint,
+			Language:           plan.Language,
 		})
 		if err != nil {
 			return fmt.Errorf("create bottle:%w", err)
@@ -102,6 +187,7 @@ func (s *bottleService) CreateBottle(ctx context.Context, input CreateBottleInpu
 			EventType: domain.EventTypeCast,
 			Lat:       plan.Lat,
 			Lng:       plan.Lng,
+			Payload:   domain.CastPayload{Nickname: plan.Nickname, BottleStyle: input.BottleStyle},
 		}); err != nil {
 			return fmt.Errorf("create cast event:%w", err)
 		}
@@ -111,20 +197,71 @@ func (s *bottleService) CreateBottle(ctx context.Context, input CreateBottleInpu
 	if err != nil {
 		return nil, err
 	}
+	advanceGeneration(ctx, s.bottles)
 
+	metrics.BottleActions.WithLabelValues("cast").Inc()
 	// No broadcast during Mystery Delay — Cork appears after scheduler flip.
 	return bottle, nil
 }
 
-func (s *bottleService) GetBottle(ctx context.Context, id int32) (*domain.Bottle, error) {
+func (s *bottleService) GetBottle(ctx context.Context, id int32) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.GetBottle")
+	defer func() { telemetry.End(span, err) }()
+
 	bottle, err := s.bottles.GetByID(ctx, id)
 	if err != nil {
 		return nil, ErrBottleNotFound
 	}
+	metrics.BottleActions.WithLabelValues("open").Inc()
 	return bottle, nil
 }
 
-func (s *bottleService) GetJourney(ctx context.Context, bottleID int32) (*domain.Journey, error) {
+func (s *bottleService) BottleVersion(ctx context.Context, _ int32) (_ int32, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.BottleVersion")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.bottles.Generation(ctx)
+}
+
+func (s *bottleService) TranslateBottle(ctx context.Context, id int32, target string) (_ *domain.TranslatedBottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.TranslateBottle")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.translator == nil {
+		return nil, ErrTranslationDisabled
+	}
+	bottle, err := s.GetBottle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Detection is deterministic, so this is the language the Cast indexed it under.
+	detected := lang.Detect(bottle.MessageText)
+	out := &domain.TranslatedBottle{Bottle: *bottle, Translation: domain.Translation{
+		Lang:        target,
+		SourceLang:  detected.Code,
+		MessageText: bottle.MessageText,
+	}}
+	// "pt-BR" readers can read a Message already in "pt".
+	if primary, _, _ := strings.Cut(target, "-"); strings.EqualFold(primary, detected.Code) {
+		return out, nil
+	}
+	source := detected.Code
+	if detected == lang.Undetermined {
+		source = translate.Auto
+	}
+	text, err := s.translator.Translate(ctx, bottle.MessageText, source, target)
+	if err != nil {
+		return nil, fmt.Errorf("translate bottle:%w", err)
+	}
+	out.Translation.MessageText = text
+	return out, nil
+}
+
+func (s *bottleService) GetJourney(ctx context.Context, bottleID int32) (_ *domain.Journey, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.GetJourney")
+	defer func() { telemetry.End(span, err) }()
+
 	bottle, err := s.bottles.GetByID(ctx, bottleID)
 	if err != nil {
 		return nil, ErrBottleNotFound
@@ -146,7 +283,29 @@ func (s *bottleService) GetJourney(ctx context.Context, bottleID int32) (*domain
 	return &domain.Journey{Bottle: bottle, Events: events}, nil
 }
 
-func (s *bottleService) DiscoverBottle(ctx context.Context, input DiscoverBottleInput) (*domain.Journey, error) {
+func (s *bottleService) GetJourneyHighlights(ctx context.Context, bottleID int32, driftSamples int) (_ *domain.Journey, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.GetJourneyHighlights")
+	defer func() { telemetry.End(span, err) }()
+
+	bottle, err := s.bottles.GetByID(ctx, bottleID)
+	if err != nil {
+		return nil, ErrBottleNotFound
+	}
+	if driftSamples < 1 {
+		driftSamples = 1
+	}
+
+	events, err := s.events.GetHighlights(ctx, bottleID, int32(driftSamples))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Journey{Bottle: bottle, Events: events}, nil
+}
+
+func (s *bottleService) DiscoverBottle(ctx context.Context, input DiscoverBottleInput) (_ *domain.Journey, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.DiscoverBottle")
+	defer func() { telemetry.End(span, err) }()
+
 	// Validation: bottle must exist, not already discovered, and discoverer cannot be sender, no mutation risk.
 	bottle, err := s.bottles.GetByID(ctx, input.BottleID)
 	if err != nil {
@@ -161,7 +320,7 @@ func (s *bottleService) DiscoverBottle(ctx context.Context, input DiscoverBottle
 		return nil, ErrAlreadyDiscovered
 	}
 
-	err = db.WithTransaction(ctx, s.pool, func(q *ocealis.Queries) error {
+	err = s.inTx(ctx, func(q *ocealis.Queries) error {
 		bottlesTx := s.bottles.WithTx(q)
 		eventsTx := s.events.WithTx(q)
 
@@ -183,33 +342,43 @@ func (s *bottleService) DiscoverBottle(ctx context.Context, input DiscoverBottle
 	if err != nil {
 		return nil, fmt.Errorf("discover bottle:%w", err)
 	}
+	advanceGeneration(ctx, s.bottles)
 
 	s.bc.BroadcastDiscovered(bottle.ID)
 	return s.GetJourney(ctx, input.BottleID)
 }
 
-func (s *bottleService) ReleaseBottle(ctx context.Context, bottleID, userID int32, lat, lng float64) (*domain.Bottle, error) {
-	bottle, err := s.bottles.GetByID(ctx, bottleID)
+func (s *bottleService) ReleaseBottle(ctx context.Context, input ReleaseBottleInput) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "BottleService.ReleaseBottle")
+	defer func() { telemetry.End(span, err) }()
+
+	nickname, err := cast.CleanNickname(input.Nickname)
+	if err != nil {
+		return nil, err
+	}
+
+	bottle, err := s.bottles.GetByID(ctx, input.BottleID)
 	if err != nil {
 		return nil, ErrBottleNotFound
 	}
 
 	var updated *domain.Bottle
 
-	err = db.WithTransaction(ctx, s.pool, func(q *ocealis.Queries) error {
+	err = s.inTx(ctx, func(q *ocealis.Queries) error {
 		bottlesTx := s.bottles.WithTx(q)
 		eventsTx := s.events.WithTx(q)
 
 		if _, err := eventsTx.Create(ctx, repository.CreateEventParams{
 			BottleID:  bottle.ID,
 			EventType: domain.EventTypeReReleased,
-			Lat:       lat,
-			Lng:       lng,
+			Lat:       input.Lat,
+			Lng:       input.Lng,
+			Payload:   domain.ReReleasePayload{Nickname: nickname},
 		}); err != nil {
 			return fmt.Errorf("create re-release event:%w", err)
 		}
 
-		updated, err = bottlesTx.UpdatePosition(ctx, bottle.ID, lat, lng, domain.BottleStatusDrifting)
+		updated, err = bottlesTx.UpdatePosition(ctx, bottle.ID, input.Lat, input.Lng, domain.BottleStatusDrifting)
 		if err != nil {
 			return fmt.Errorf("update bottle position:%w", err)
 		}
@@ -219,7 +388,9 @@ func (s *bottleService) ReleaseBottle(ctx context.Context, bottleID, userID int3
 	if err != nil {
 		return nil, fmt.Errorf("release bottle:%w", err)
 	}
+	advanceGeneration(ctx, s.bottles)
 
+	metrics.BottleActions.WithLabelValues("re_release").Inc()
 	s.bc.BroadcastReleased(updated.ID)
 	return updated, nil
 }
@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/cast"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/drift"
+	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/ws"
+	"go.uber.org/zap"
+)
+
+func TestDriftTickIsReproducibleFromSeedAndClock(t *testing.T) {
+	at := time.Date(2026, 5, 4, 9, 15, 0, 0, time.UTC)
+	run := func() []repository.BottleMove {
+		store := &driftStore{active: []domain.Bottle{
+			{ID: 11, CurrentLat: 35, CurrentLng: 150, Status: domain.BottleStatusDrifting},
+			{ID: 12, CurrentLat: -20, CurrentLng: 80, Status: domain.BottleStatusDrifting},
+		}}
+		svc := service.NewDriftService(nil, &driftBottles{store: store}, &driftEvents{store: store},
+			ws.NewBroadcaster(ws.NewHub(), zap.NewNop()), zap.NewNop(),
+			service.WithDriftTx(store.tx),
+			service.WithDriftSeed(2026),
+			service.WithDriftClock(func() time.Time { return at }),
+		)
+		if err := svc.Tick(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return store.moves
+	}
+
+	first, second := run(), run()
+	if len(first) != 2 || first[0] != second[0] || first[1] != second[1] {
+		t.Fatalf("same seed and clock must give the same moves: %+v vs %+v", first, second)
+	}
+	// Any one Bottle's step can be recomputed from the model alone.
+	want := drift.Model{Seed: 2026}.Step(12, drift.TickIndex(at), -20, 80, styles.Leeway(0))
+	if first[1].Lat != want.Lat || first[1].Lng != want.Lng {
+		t.Fatalf("bottle 12 moved to (%v, %v), model says (%v, %v)", first[1].Lat, first[1].Lng, want.Lat, want.Lng)
+	}
+}
+
+// castBottles records what Cast would insert.
+type castBottles struct {
+	fakeBottles
+	created []repository.CreateBottleParams
+}
+
+func (f *castBottles) Create(_ context.Context, p repository.CreateBottleParams) (*domain.Bottle, error) {
+	f.created = append(f.created, p)
+	return &domain.Bottle{ID: int32(len(f.created)), VisibleAt: p.VisibleAt.Time}, nil
+}
+func (f *castBottles) WithTx(*ocealis.Queries) repository.BottleRepository { return f }
+
+func TestMysteryDelayIsReproducibleWithInjectedClockAndRand(t *testing.T) {
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	cast1 := func() time.Time {
+		bottles := &castBottles{}
+		svc := service.NewBottleService(nil, bottles, &journeyEventsRepo{}, nil,
+			service.WithBottleTx(func(_ context.Context, fn func(*ocealis.Queries) error) error { return fn(nil) }),
+			service.WithBottleClock(func() time.Time { return now }),
+			service.WithBottleRand(rand.NewSource(7)),
+		)
+		b, err := svc.CreateBottle(context.Background(), service.CreateBottleInput{Nickname: "tern", MessageText: "same every time"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.VisibleAt
+	}
+
+	first := cast1()
+	if first != cast1() {
+		t.Fatal("same clock and rand source must give the same Mystery Delay")
+	}
+	if delay := first.Sub(now); delay < cast.MysteryMin || delay > cast.MysteryMax {
+		t.Fatalf("Mystery Delay %v outside [%v, %v]", delay, cast.MysteryMin, cast.MysteryMax)
+	}
+}
+
+func TestCastRejectsReservedAndUnknownStyles(t *testing.T) {
+	bottles := &castBottles{}
+	svc := service.NewBottleService(nil, bottles, &journeyEventsRepo{}, nil,
+		service.WithBottleTx(func(_ context.Context, fn func(*ocealis.Queries) error) error { return fn(nil) }),
+	)
+	for style, want := range map[int32]error{styles.Seed: styles.ErrReservedStyle, 42: styles.ErrUnknownStyle} {
+		_, err := svc.CreateBottle(context.Background(), service.CreateBottleInput{Nickname: "tern", MessageText: "hi", BottleStyle: style})
+		if !errors.Is(err, want) {
+			t.Fatalf("style %d: err %v, want %v", style, err, want)
+		}
+	}
+	if len(bottles.created) != 0 {
+		t.Fatalf("rejected Casts must not insert; got %+v", bottles.created)
+	}
+}
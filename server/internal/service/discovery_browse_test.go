@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/domain"
 	"github.com/Polqt/ocealis/internal/service"
 )
 
@@ -32,3 +34,32 @@ func TestBrowseMapShowsSeedsWhenOceanEmpty(t *testing.T) {
 		t.Fatalf("want Seed Corks; got %+v", out.Corks)
 	}
 }
+
+func TestBrowseMapReusesResultsUntilTheOceanMoves(t *testing.T) {
+	repo := &fakeBottles{generation: 7, rows: []domain.Bottle{
+		{ID: 1, Status: domain.BottleStatusDrifting, IsReleased: true, CurrentLat: 12, CurrentLng: -35},
+	}}
+	svc := service.NewDiscoveryService(repo, service.WithMapCache(discovery.NewMapCache(8)))
+	browse := func(minLat, maxLat float64) discovery.MapResult {
+		t.Helper()
+		out, err := svc.BrowseMap(context.Background(), service.BrowseMapInput{
+			MinLat: minLat, MaxLat: maxLat, MinLng: -40.5, MaxLng: -30.5, Zoom: 6.4,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	first := browse(10.2, 20.2)
+	browse(10.3, 20.3) // a small pan lands in the same grid cells
+	if repo.listed != 1 || len(first.Corks) != 1 {
+		t.Fatalf("want one scan for both pans, got %d; corks %+v", repo.listed, first.Corks)
+	}
+
+	repo.generation++ // a drift tick wrote events
+	repo.rows[0].CurrentLat = 13
+	if moved := browse(10.2, 20.2); repo.listed != 2 || moved.Corks[0].Lat != 13 {
+		t.Fatalf("new generation must rescan: scans %d, corks %+v", repo.listed, moved.Corks)
+	}
+}
@@ -2,10 +2,12 @@ package service_test
 
 import (
 	"context"
+	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/discovery"
 	"github.com/Polqt/ocealis/internal/domain"
 	"github.com/Polqt/ocealis/internal/repository"
 	"github.com/Polqt/ocealis/internal/service"
@@ -13,7 +15,12 @@ import (
 
 // fakeBottles returns whatever FindNearby is given — discovery must still hide Mystery Delay.
 type fakeBottles struct {
-	rows []domain.Bottle
+	rows   []domain.Bottle
+	sample []discovery.Candidate
+	params repository.SampleParams
+	// generation is the Ocean generation; listed counts ListActive scans.
+	generation int32
+	listed     int
 }
 
 func (f *fakeBottles) Create(context.Context, repository.CreateBottleParams) (*domain.Bottle, error) {
@@ -26,13 +33,34 @@ func (f *fakeBottles) UpdateStatus(context.Context, int32, domain.BottleStatus)
 func (f *fakeBottles) UpdatePosition(context.Context, int32, float64, float64, domain.BottleStatus) (*domain.Bottle, error) {
 	return nil, nil
 }
-func (f *fakeBottles) ListActive(context.Context) ([]domain.Bottle, error) { return nil, nil }
+func (f *fakeBottles) MoveDrifting(context.Context, []repository.BottleMove) ([]int32, error) {
+	return nil, nil
+}
+func (f *fakeBottles) ListActive(context.Context) ([]domain.Bottle, error) {
+	f.listed++
+	return f.rows, nil
+}
 func (f *fakeBottles) ReleaseScheduled(context.Context) ([]domain.Bottle, error) {
 	return nil, nil
 }
 func (f *fakeBottles) FindNearby(context.Context, repository.FindNearbyParams) (*domain.CursorResult[domain.Bottle], error) {
 	return &domain.CursorResult[domain.Bottle]{Data: f.rows}, nil
 }
+func (f *fakeBottles) RecentFingerprints(context.Context, time.Time) ([]uint64, error) {
+	return nil, nil
+}
+func (f *fakeBottles) Search(context.Context, repository.SearchParams) (*domain.CursorResult[domain.SearchHit], error) {
+	return &domain.CursorResult[domain.SearchHit]{}, nil
+}
+func (f *fakeBottles) SampleDrifting(_ context.Context, params repository.SampleParams) ([]discovery.Candidate, error) {
+	f.params = params
+	return f.sample, nil
+}
+func (f *fakeBottles) Generation(context.Context) (int32, error) { return f.generation, nil }
+func (f *fakeBottles) BumpGeneration(context.Context) error {
+	f.generation++
+	return nil
+}
 func (f *fakeBottles) WithTx(*ocealis.Queries) repository.BottleRepository { return f }
 
 func TestMysteryDelayBottleInvisibleToNearby(t *testing.T) {
@@ -68,3 +96,35 @@ func TestMysteryDelayBottleInvisibleToNearby(t *testing.T) {
 		t.Fatalf("Mystery Delay must be invisible; got %+v", out.Data)
 	}
 }
+
+func TestRandomSkipsMysteryDelayAndPrefersCallerBasin(t *testing.T) {
+	repo := &fakeBottles{sample: []discovery.Candidate{
+		{Bottle: domain.Bottle{ID: 1, MessageText: "secret", Status: domain.BottleStatusMysteryDelay}, Basin: "north_pacific"},
+		{Bottle: domain.Bottle{ID: 2, Status: domain.BottleStatusDrifting, IsReleased: true}, Basin: "north_pacific"},
+	}}
+	svc := service.NewDiscoveryService(repo, service.WithDiscoveryRand(rand.NewSource(7)))
+	lat, lng := 30.0, -140.0
+	for range 50 {
+		b, err := svc.Random(context.Background(), service.RandomInput{Lat: &lat, Lng: &lng})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.ID != 2 {
+			t.Fatalf("Mystery Delay must never be picked; got %d", b.ID)
+		}
+	}
+	if repo.params.Basin != "north_pacific" || repo.params.Limit <= 0 || repo.params.FromKey < 0 || repo.params.FromKey >= 1 {
+		t.Fatalf("sample params %+v", repo.params)
+	}
+}
+
+func TestRandomFallsBackToSeedsOnEmptyOcean(t *testing.T) {
+	svc := service.NewDiscoveryService(&fakeBottles{}, service.WithDiscoveryRand(rand.NewSource(1)))
+	b, err := svc.Random(context.Background(), service.RandomInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID >= 0 || !b.IsSeed {
+		t.Fatalf("want a Seed Bottle, got %+v", b)
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand"
+	"sync"
+	"time"
 
 	"github.com/Polqt/ocealis/internal/discovery"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/geo"
+	"github.com/Polqt/ocealis/internal/replay"
 	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/Polqt/ocealis/util"
 )
 
@@ -33,6 +39,13 @@ type BottleWithDistance struct {
 	DistanceKm float64 `json:"distance_km"`
 }
 
+// MarshalJSON adds distance_km to the Bottle's own rendering.
+func (b BottleWithDistance) MarshalJSON() ([]byte, error) {
+	return domain.MarshalBottleWith(b.Bottle, struct {
+		DistanceKm float64 `json:"distance_km"`
+	}{b.DistanceKm})
+}
+
 type BrowseMapInput struct {
 	MinLat float64
 	MaxLat float64
@@ -41,35 +54,185 @@ type BrowseMapInput struct {
 	Zoom   float64
 }
 
+// SearchInput finds Messages matching Query, optionally inside BBox. Cursor is the
+// previous page's NextCursor.
+type SearchInput struct {
+	Query  string
+	BBox   *replay.BBox
+	Cursor *domain.Cursor
+	Limit  int32
+}
+
+// RandomInput optionally places the caller so Corks in their basin are preferred.
+type RandomInput struct {
+	Lat *float64
+	Lng *float64
+}
+
 type DiscoveryService interface {
 	FindNearby(ctx context.Context, input FindNearbyInput) (*domain.CursorResult[BottleWithDistance], error)
 	BrowseMap(ctx context.Context, input BrowseMapInput) (discovery.MapResult, error)
+	// MapGeneration changes whenever BrowseMap could answer differently.
+	MapGeneration(ctx context.Context) (int32, error)
+	// Search full-text matches the Messages of visible Corks, best match first.
+	Search(ctx context.Context, input SearchInput) (*domain.CursorResult[domain.SearchHit], error)
+	// Random opens a serendipitous Cork, favouring quiet Bottles and the caller's basin.
+	Random(ctx context.Context, input RandomInput) (*domain.Bottle, error)
+}
+
+// sampleSize is how many Corks one Random draws from the sampling index per arm;
+// enough for the weights to matter, few enough to stay a short index walk.
+const sampleSize = 16
+
+// DiscoveryOption tunes a discovery service; tests use it to pin random picks.
+type DiscoveryOption func(*discoverService)
+
+// WithDiscoveryRand sets the source serendipity picks are drawn from.
+func WithDiscoveryRand(src rand.Source) DiscoveryOption {
+	return func(s *discoverService) { s.rng = rand.New(src) }
+}
+
+// WithMapCache replaces the map response cache, e.g. to size it from config.
+func WithMapCache(c *discovery.MapCache) DiscoveryOption {
+	return func(s *discoverService) { s.maps = c }
 }
 
 type discoverService struct {
 	bottles repository.BottleRepository
+	maps    *discovery.MapCache
+
+	rngMu sync.Mutex // *rand.Rand is not safe for concurrent picks
+	rng   *rand.Rand
 }
 
-func NewDiscoveryService(bottles repository.BottleRepository) DiscoveryService {
-	return &discoverService{bottles: bottles}
+func NewDiscoveryService(bottles repository.BottleRepository, opts ...DiscoveryOption) DiscoveryService {
+	s := &discoverService{
+		bottles: bottles,
+		maps:    discovery.NewMapCache(discovery.DefaultMapCacheSize),
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *discoverService) BrowseMap(ctx context.Context, input BrowseMapInput) (discovery.MapResult, error) {
+func (s *discoverService) BrowseMap(ctx context.Context, input BrowseMapInput) (_ discovery.MapResult, err error) {
+	ctx, span := telemetry.Start(ctx, "DiscoveryService.BrowseMap")
+	defer func() { telemetry.End(span, err) }()
+
+	key, vp := discovery.Snap(input.Zoom, discovery.Viewport{
+		MinLat: input.MinLat,
+		MaxLat: input.MaxLat,
+		MinLng: input.MinLng,
+		MaxLng: input.MaxLng,
+	})
+	// Read before the Bottles: a result is never filed under a newer generation than
+	// the state it saw.
+	key.Generation, err = s.bottles.Generation(ctx)
+	if err != nil {
+		return discovery.MapResult{}, fmt.Errorf("ocean generation: %w", err)
+	}
+	if cached, ok := s.maps.Get(key); ok {
+		return cached, nil
+	}
+
 	active, err := s.bottles.ListActive(ctx)
 	if err != nil {
 		return discovery.MapResult{}, fmt.Errorf("list active bottles: %w", err)
 	}
 	// Seeds always in Ocean — no Mystery Delay, sea never empty.
 	all := append(discovery.Seeds(), active...)
-	return discovery.QueryOcean(input.Zoom, discovery.Viewport{
-		MinLat: input.MinLat,
-		MaxLat: input.MaxLat,
-		MinLng: input.MinLng,
-		MaxLng: input.MaxLng,
-	}, all), nil
+	result := discovery.QueryOcean(input.Zoom, vp, all)
+	s.maps.Put(key, result)
+	return result, nil
+}
+
+func (s *discoverService) MapGeneration(ctx context.Context) (_ int32, err error) {
+	ctx, span := telemetry.Start(ctx, "DiscoveryService.MapGeneration")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.bottles.Generation(ctx)
 }
 
-func (s *discoverService) FindNearby(ctx context.Context, input FindNearbyInput) (*domain.CursorResult[BottleWithDistance], error) {
+func (s *discoverService) Search(ctx context.Context, input SearchInput) (_ *domain.CursorResult[domain.SearchHit], err error) {
+	ctx, span := telemetry.Start(ctx, "DiscoveryService.Search")
+	defer func() { telemetry.End(span, err) }()
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	bbox := replay.BBox{MinLng: -180, MinLat: -90, MaxLng: 180, MaxLat: 90}
+	if input.BBox != nil {
+		bbox = *input.BBox
+	}
+	params := repository.SearchParams{
+		Query:  input.Query,
+		MinLat: bbox.MinLat,
+		MaxLat: bbox.MaxLat,
+		MinLng: bbox.MinLng,
+		MaxLng: bbox.MaxLng,
+		Limit:  limit,
+	}
+	if c := input.Cursor; c != nil {
+		params.CursorID, params.CursorRank = c.LastID, c.LastRank
+	}
+
+	result, err := s.bottles.Search(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("search bottles: %w", err)
+	}
+	// Defense in depth: Mystery Delay bottles stay invisible even if SQL drifts.
+	visible := result.Data[:0]
+	for _, hit := range result.Data {
+		if hit.Status == domain.BottleStatusDrifting && hit.IsReleased {
+			visible = append(visible, hit)
+		}
+	}
+	result.Data = visible
+	return result, nil
+}
+
+func (s *discoverService) Random(ctx context.Context, input RandomInput) (_ *domain.Bottle, err error) {
+	ctx, span := telemetry.Start(ctx, "DiscoveryService.Random")
+	defer func() { telemetry.End(span, err) }()
+
+	basin := ""
+	if input.Lat != nil && input.Lng != nil {
+		basin = geo.Basin(*input.Lat, *input.Lng)
+	}
+
+	s.rngMu.Lock()
+	fromKey := s.rng.Float64()
+	s.rngMu.Unlock()
+
+	sampled, err := s.bottles.SampleDrifting(ctx, repository.SampleParams{FromKey: fromKey, Limit: sampleSize, Basin: basin})
+	if err != nil {
+		return nil, fmt.Errorf("sample drifting bottles: %w", err)
+	}
+	// Defense in depth: Mystery Delay bottles stay invisible even if SQL drifts.
+	candidates := sampled[:0]
+	for _, c := range sampled {
+		if c.Bottle.Status == domain.BottleStatusDrifting && c.Bottle.IsReleased {
+			candidates = append(candidates, c)
+		}
+	}
+
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	if pick, ok := discovery.Pick(candidates, basin, s.rng); ok {
+		return &pick.Bottle, nil
+	}
+	// Seeds keep the sea from ever coming up empty.
+	seeds := discovery.Seeds()
+	return &seeds[s.rng.Intn(len(seeds))], nil
+}
+
+func (s *discoverService) FindNearby(ctx context.Context, input FindNearbyInput) (_ *domain.CursorResult[BottleWithDistance], err error) {
+	ctx, span := telemetry.Start(ctx, "DiscoveryService.FindNearby")
+	defer func() { telemetry.End(span, err) }()
+
 	radius := input.RadiusKm
 	if radius == 0 {
 		radius = DiscoverRadiusKm
@@ -3,56 +3,77 @@ package service
 import (
 	"context"
 	"fmt"
-	"math"
-	"math/rand"
+	"time"
 
 	"github.com/Polqt/ocealis/db"
 	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/cast"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/drift"
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/Polqt/ocealis/internal/repository"
-	"github.com/Polqt/ocealis/util"
+	"github.com/Polqt/ocealis/internal/styles"
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/Polqt/ocealis/ws"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
-const DriftTickHours = 0.25 // 15 minutes = 6 hours simulated ocean drift
+// DefaultDriftBatchSize is how many Bottles share one drift transaction:
+// one COPY of their drift events and one bulk position update.
+const DefaultDriftBatchSize = 1000
 
-// CurrentZone maps a region of the ocean to a dominant current direction and speed.
-// This is a simplified gyre model, real ocean currents follow these
-// Broad circular patterns (gyres) driven by wind and the Coriolis effect, but with lots of local variation.
-type currentZone struct {
-	minLat, maxLat float64
-	minLng, maxLng float64
-	bearing        float64 // degrees, 0 = north, 90 = east, etc.
-	speedKmH       float64
+type DriftService interface {
+	Tick(ctx context.Context) error
+	ReleaseScheduled(ctx context.Context) error
 }
 
-var oceanZones = []currentZone{
-	{minLat: 0, maxLat: 60, minLng: -80, maxLng: 0, bearing: 45, speedKmH: 2.5},
-	// South Atlantic Gyre (counter-clockwise)
-	{minLat: -60, maxLat: 0, minLng: -60, maxLng: 20, bearing: 225, speedKmH: 2.0},
-	// North Pacific Gyre (clockwise)
-	{minLat: 0, maxLat: 65, minLng: 120, maxLng: -120, bearing: 60, speedKmH: 2.8},
-	// South Pacific Gyre (counter-clockwise)
-	{minLat: -60, maxLat: 0, minLng: 150, maxLng: -70, bearing: 210, speedKmH: 2.2},
-	// Indian Ocean Gyre
-	{minLat: -60, maxLat: 25, minLng: 40, maxLng: 120, bearing: 270, speedKmH: 1.8},
-	// Default fallback — gentle random drift
-	{minLat: -90, maxLat: 90, minLng: -180, maxLng: 180, bearing: 0, speedKmH: 0.5},
+// TxFunc runs fn inside one transaction; db.WithTransaction over a pool is the default.
+type TxFunc func(ctx context.Context, fn func(q *ocealis.Queries) error) error
+
+// advanceGeneration tells map caches and ETags that a write has committed. A failed bump
+// does not undo the write; caches serve the older Ocean until the next write moves it.
+func advanceGeneration(ctx context.Context, bottles repository.BottleRepository) {
+	_ = bottles.BumpGeneration(context.WithoutCancel(ctx))
 }
 
-type DriftService interface {
-	Tick(ctx context.Context) error
-	ReleaseScheduled(ctx context.Context) error
+// DriftOption tunes a drift service; tests and benchmarks use it to run without Postgres.
+type DriftOption func(*driftService)
+
+// WithDriftBatchSize sets how many Bottles each tick transaction covers.
+func WithDriftBatchSize(n int) DriftOption {
+	return func(s *driftService) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithDriftClock replaces time.Now; the tick index each Bottle's noise is keyed on comes from it.
+func WithDriftClock(now func() time.Time) DriftOption {
+	return func(s *driftService) { s.now = now }
+}
+
+// WithDriftSeed picks the noise stream. The same seed replays the same paths.
+func WithDriftSeed(seed uint64) DriftOption {
+	return func(s *driftService) { s.model.Seed = seed }
+}
+
+// WithDriftTx replaces the transaction runner.
+func WithDriftTx(tx TxFunc) DriftOption {
+	return func(s *driftService) { s.inTx = tx }
 }
 
 type driftService struct {
-	pool    *pgxpool.Pool
-	bottles repository.BottleRepository
-	events  repository.EventRepository
-	bc      *ws.Broadcaster
-	log     *zap.Logger
+	pool      *pgxpool.Pool
+	bottles   repository.BottleRepository
+	events    repository.EventRepository
+	bc        *ws.Broadcaster
+	log       *zap.Logger
+	batchSize int
+	inTx      TxFunc
+	now       func() time.Time
+	model     drift.Model
 }
 
 func NewDriftService(
@@ -61,96 +82,150 @@ func NewDriftService(
 	events repository.EventRepository,
 	bc *ws.Broadcaster,
 	log *zap.Logger,
+	opts ...DriftOption,
 ) DriftService {
-	return &driftService{pool: pool, bottles: bottles, events: events, bc: bc, log: log}
+	s := &driftService{
+		pool:      pool,
+		bottles:   bottles,
+		events:    events,
+		bc:        bc,
+		log:       log,
+		batchSize: DefaultDriftBatchSize,
+		now:       time.Now,
+	}
+	s.inTx = func(ctx context.Context, fn func(q *ocealis.Queries) error) error {
+		return db.WithTransaction(ctx, s.pool, fn)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *driftService) Tick(ctx context.Context) error {
-	s.log.Info("drift tick fired")
+// Tick moves every active Bottle one step, a batch at a time. A failed batch rolls
+// back on its own and is reported once the rest of the tick has run.
+func (s *driftService) Tick(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "DriftService.Tick")
+	defer func() { telemetry.End(span, err) }()
+
+	start := time.Now()
+	defer func() { metrics.DriftTickDuration.Observe(time.Since(start).Seconds()) }()
+	at := s.now()
 
 	activeBots, err := s.bottles.ListActive(ctx)
 	if err != nil {
 		return fmt.Errorf("list active bottles: %w", err)
 	}
 
-	for i := range activeBots {
-		b := &activeBots[i]
-		if err := s.driftOne(ctx, b, func(e domain.BottleEvent) {
-			s.bc.BroadcastDrift(ws.DriftPayload{
-				BottleID:    e.BottleID,
-				Lat:         e.Lat,
-				Lng:         e.Lng,
-				Hops:        b.Hops,
-				BottleStyle: b.BottleStyle,
-				Timestamp:   e.CreatedAt,
-			})
-		}); err != nil {
-			// Log and continue — one bad bottle doesn’t stop the others.
-			s.log.Error("driftOne failed", zap.Int32("bottle_id", b.ID), zap.Error(err))
+	failed := 0
+	for lo := 0; lo < len(activeBots); lo += s.batchSize {
+		batch := activeBots[lo:min(lo+s.batchSize, len(activeBots))]
+		if err := s.driftBatch(ctx, batch, at); err != nil {
+			// Log and continue — one bad batch doesn’t stop the others.
+			failed += len(batch)
+			metrics.DriftBatchFailures.Inc()
+			s.log.Error("drift batch failed",
+				zap.Int32("first_bottle_id", batch[0].ID),
+				zap.Int("bottles", len(batch)),
+				zap.Error(err))
 		}
 	}
+
+	s.log.Info("drift tick finished",
+		zap.Int("bottles", len(activeBots)),
+		zap.Int("failed", failed),
+		zap.Duration("took", time.Since(start)))
+	if failed > 0 {
+		return fmt.Errorf("drift tick: %d of %d bottles not moved", failed, len(activeBots))
+	}
 	return nil
 }
 
-func (s *driftService) driftOne(ctx context.Context, bottle *domain.Bottle, onDrift func(domain.BottleEvent)) error {
-	bearing, speed := dominantCurrent(bottle.CurrentLat, bottle.CurrentLng)
-
-	// Add +=10 degrees to random perturbation to make it less predictable, so path looks organic, not like perfect mathematical circles.
-	bearing += rand.Float64()*20 - 10
-	bearing = math.Mod(bearing+360, 360)
-
-	newLat, newLng := util.ApplyDrift(bottle.CurrentLat, bottle.CurrentLng, speed, bearing, DriftTickHours)
+// driftBatch computes the batch's new positions in Go, then writes positions and drift
+// events in one transaction. Drift is not a hop, so hops stay as they are. The batch
+// was listed before the transaction, so Bottles claimed or Sunk since are left out: the
+// bulk UPDATE skips them, and only the Bottles it moved get an event or a broadcast.
+func (s *driftService) driftBatch(ctx context.Context, batch []domain.Bottle, at time.Time) error {
+	tick := drift.TickIndex(at)
+	moves := make([]repository.BottleMove, len(batch))
+	events := make([]repository.CreateEventParams, len(batch))
+	for i := range batch {
+		moves[i], events[i] = s.driftStep(&batch[i], tick)
+	}
 
-	// Bug fix: drift events must be typed "drift", not "discovered".
-	event, err := s.events.Create(ctx, repository.CreateEventParams{
-		BottleID:  bottle.ID,
-		EventType: domain.EventTypeDrift,
-		Lat:       newLat,
-		Lng:       newLng,
+	moved := make(map[int32]bool, len(batch))
+	err := s.inTx(ctx, func(q *ocealis.Queries) error {
+		ids, err := s.bottles.WithTx(q).MoveDrifting(ctx, moves)
+		if err != nil {
+			return fmt.Errorf("move bottles: %w", err)
+		}
+		for _, id := range ids {
+			moved[id] = true
+		}
+		drifted := events[:0:0]
+		for _, e := range events {
+			if moved[e.BottleID] {
+				drifted = append(drifted, e)
+			}
+		}
+		if _, err := s.events.WithTx(q).CreateBatch(ctx, drifted); err != nil {
+			return fmt.Errorf("copy drift events: %w", err)
+		}
+		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("drift bottle %d: %w", bottle.ID, err)
+		return err
 	}
+	advanceGeneration(ctx, s.bottles)
 
-	// Persist the new coordinates so the next tick starts from the right position.
-	_, _ = s.bottles.UpdatePosition(ctx, bottle.ID, newLat, newLng, domain.BottleStatusDrifting)
-
-	if onDrift != nil {
-		onDrift(*event)
+	metrics.DriftBottlesMoved.Add(float64(len(moved)))
+	for i := range batch {
+		if !moved[batch[i].ID] {
+			continue
+		}
+		s.bc.BroadcastDrift(ws.DriftPayload{
+			BottleID:    batch[i].ID,
+			Lat:         moves[i].Lat,
+			Lng:         moves[i].Lng,
+			Hops:        batch[i].Hops,
+			BottleStyle: batch[i].BottleStyle,
+			Timestamp:   at,
+		})
 	}
-
-	s.log.Info("bottle drifted", zap.Int32("bottle_id", bottle.ID), zap.Float64("lat", newLat), zap.Float64("lng", newLng))
-
 	return nil
 }
 
-func dominantCurrent(lat, lng float64) (bearing, speed float64) {
-	for _, z := range oceanZones {
-		latIn := lat >= z.minLat && lat <= z.maxLat
-		lngIn := false
-		if z.minLng <= z.maxLng {
-			lngIn = lng >= z.minLng && lng <= z.maxLng
-		} else {
-			// Zone wraps across the antimeridian (+=180), e.g. North Pacific
-			lngIn = lng >= z.minLng || lng <= z.maxLng
-		}
-		if latIn && lngIn {
-			return z.bearing, z.speedKmH
+// driftStep is one tick of one Bottle: where it ends up and the drift event recording it.
+// It does not beach Bottles: geo's land boxes are too coarse to Sink anything on, and
+// every Shoreline drop sits just outside one (see drift.Step.Ashore).
+func (s *driftService) driftStep(bottle *domain.Bottle, tick int64) (repository.BottleMove, repository.CreateEventParams) {
+	step := s.model.Step(bottle.ID, tick, bottle.CurrentLat, bottle.CurrentLng, styles.Leeway(bottle.BottleStyle))
+
+	return repository.BottleMove{ID: bottle.ID, Lat: step.Lat, Lng: step.Lng},
+		repository.CreateEventParams{
+			BottleID:  bottle.ID,
+			EventType: domain.EventTypeDrift,
+			Lat:       step.Lat,
+			Lng:       step.Lng,
+			Payload: domain.DriftPayload{
+				BearingDeg: step.BearingDeg,
+				SpeedKmH:   step.SpeedKmH,
+				DistanceKm: step.DistanceKm,
+			},
 		}
-	}
-	// Should never happen since the last zone is a global fallback, but just in case:
-	last := oceanZones[len(oceanZones)-1]
-	return last.bearing, last.speedKmH
 }
 
-func (s *driftService) ReleaseScheduled(ctx context.Context) error {
+func (s *driftService) ReleaseScheduled(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "DriftService.ReleaseScheduled")
+	defer func() { telemetry.End(span, err) }()
+
 	due, err := s.bottles.ReleaseScheduled(ctx)
 	if err != nil {
 		return fmt.Errorf("list scheduled bottles:%w", err)
 	}
 
 	for _, bottle := range due {
-		err := db.WithTransaction(ctx, s.pool, func(q *ocealis.Queries) error {
+		err := s.inTx(ctx, func(q *ocealis.Queries) error {
 			bottlesTx := s.bottles.WithTx(q)
 			eventsTx := s.events.WithTx(q)
 
@@ -159,6 +234,7 @@ func (s *driftService) ReleaseScheduled(ctx context.Context) error {
 				EventType: domain.EventTypeCast,
 				Lat:       bottle.StartLat,
 				Lng:       bottle.StartLng,
+				Payload:   domain.CastPayload{Nickname: payloadNickname(bottle.Nickname), BottleStyle: bottle.BottleStyle},
 			}); err != nil {
 				return err
 			}
@@ -170,10 +246,23 @@ func (s *driftService) ReleaseScheduled(ctx context.Context) error {
 			s.log.Error("release scheduled bottle failed", zap.Int32("bottle_id", bottle.ID), zap.Error(err))
 			continue
 		}
+		advanceGeneration(ctx, s.bottles)
 
+		metrics.ScheduledReleaseLag.Observe(s.now().Sub(bottle.VisibleAt).Seconds())
 		s.bc.BroadcastReleased(bottle.ID)
 		s.log.Info("scheduled bottle released", zap.Int32("bottle_id", bottle.ID))
 	}
 
 	return nil
 }
+
+// payloadNickname is nickname as a payload accepts it. Bottles cast before Unicode
+// hygiene may hold one cast.CleanNickname now refuses; their Cast event goes unsigned
+// rather than failing the release.
+func payloadNickname(nickname string) string {
+	clean, err := cast.CleanNickname(nickname)
+	if err != nil {
+		return ""
+	}
+	return clean
+}
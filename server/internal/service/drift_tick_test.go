@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/db"
+	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/drift"
+	"github.com/Polqt/ocealis/internal/geo"
+	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// driftStore stages one transaction's writes and keeps them only if it commits.
+type driftStore struct {
+	active   []domain.Bottle
+	failID   int32
+	hopBumps int
+	// generationBumps counts BumpGeneration calls, one per committed batch.
+	generationBumps int
+	// stopped holds Bottles claimed or Sunk after ListActive; MoveDrifting skips them.
+	stopped map[int32]bool
+
+	pendingMoves  []repository.BottleMove
+	pendingEvents []repository.CreateEventParams
+	moves         []repository.BottleMove
+	events        []repository.CreateEventParams
+}
+
+func (s *driftStore) tx(_ context.Context, fn func(*ocealis.Queries) error) error {
+	s.pendingMoves, s.pendingEvents = nil, nil
+	if err := fn(nil); err != nil {
+		return err
+	}
+	s.moves = append(s.moves, s.pendingMoves...)
+	s.events = append(s.events, s.pendingEvents...)
+	return nil
+}
+
+type driftBottles struct {
+	fakeBottles
+	store *driftStore
+}
+
+func (f *driftBottles) ListActive(context.Context) ([]domain.Bottle, error) {
+	return f.store.active, nil
+}
+func (f *driftBottles) MoveDrifting(_ context.Context, moves []repository.BottleMove) ([]int32, error) {
+	var ids []int32
+	for _, m := range moves {
+		if !f.store.stopped[m.ID] {
+			f.store.pendingMoves = append(f.store.pendingMoves, m)
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids, nil
+}
+func (f *driftBottles) UpdatePosition(context.Context, int32, float64, float64, domain.BottleStatus) (*domain.Bottle, error) {
+	f.store.hopBumps++
+	return nil, nil
+}
+func (f *driftBottles) BumpGeneration(context.Context) error {
+	f.store.generationBumps++
+	return nil
+}
+func (f *driftBottles) WithTx(*ocealis.Queries) repository.BottleRepository { return f }
+
+type driftEvents struct {
+	journeyEventsRepo
+	store *driftStore
+}
+
+func (f *driftEvents) CreateBatch(_ context.Context, params []repository.CreateEventParams) (int64, error) {
+	for _, p := range params {
+		if p.BottleID == f.store.failID {
+			return 0, errors.New("copy failed")
+		}
+	}
+	f.store.pendingEvents = append(f.store.pendingEvents, params...)
+	return int64(len(params)), nil
+}
+func (f *driftEvents) WithTx(*ocealis.Queries) repository.EventRepository { return f }
+
+func newDriftTestService(store *driftStore, batchSize int) service.DriftService {
+	return service.NewDriftService(nil,
+		&driftBottles{store: store},
+		&driftEvents{store: store},
+		ws.NewBroadcaster(ws.NewHub(), zap.NewNop()),
+		zap.NewNop(),
+		service.WithDriftBatchSize(batchSize),
+		service.WithDriftTx(store.tx),
+	)
+}
+
+func TestDriftTickCommitsPerBatchWithoutCountingHops(t *testing.T) {
+	store := &driftStore{failID: 3}
+	for id := int32(1); id <= 5; id++ {
+		store.active = append(store.active, domain.Bottle{ID: id, CurrentLat: 30, CurrentLng: -40, Hops: 2, Status: domain.BottleStatusDrifting})
+	}
+
+	err := newDriftTestService(store, 2).Tick(context.Background())
+	if err == nil {
+		t.Fatal("want the failed batch reported")
+	}
+
+	// Batch [3 4] rolled back; [1 2] and [5] still committed.
+	var moved []int32
+	for _, m := range store.moves {
+		moved = append(moved, m.ID)
+		if m.Lat == 30 && m.Lng == -40 {
+			t.Errorf("bottle %d did not move", m.ID)
+		}
+	}
+	if len(moved) != 3 || moved[0] != 1 || moved[1] != 2 || moved[2] != 5 {
+		t.Fatalf("moved %v, want [1 2 5]", moved)
+	}
+	if len(store.events) != 3 {
+		t.Fatalf("want one drift event per moved bottle, got %d", len(store.events))
+	}
+	for _, e := range store.events {
+		if _, ok := e.Payload.(domain.DriftPayload); e.EventType != domain.EventTypeDrift || !ok {
+			t.Fatalf("want typed drift events, got %+v", e)
+		}
+	}
+	if store.hopBumps != 0 {
+		t.Fatalf("drift must not count as a hop, UpdatePosition called %d times", store.hopBumps)
+	}
+	if store.generationBumps != 2 {
+		t.Fatalf("want the generation bumped after each of 2 committed batches, got %d", store.generationBumps)
+	}
+}
+
+func TestDriftTickSkipsBottlesNoLongerDrifting(t *testing.T) {
+	store := &driftStore{stopped: map[int32]bool{2: true}}
+	for id := int32(1); id <= 3; id++ {
+		store.active = append(store.active, domain.Bottle{ID: id, CurrentLat: 30, CurrentLng: -40, Status: domain.BottleStatusDrifting})
+	}
+	if err := newDriftTestService(store, 10).Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.moves) != 2 || len(store.events) != 2 {
+		t.Fatalf("moves %+v, events %+v: want bottles 1 and 3 only", store.moves, store.events)
+	}
+	for _, e := range store.events {
+		if e.BottleID == 2 {
+			t.Fatalf("claimed bottle 2 got a drift event: %+v", e)
+		}
+	}
+}
+
+func TestDriftTickKeepsShorelineCastsDrifting(t *testing.T) {
+	store := &driftStore{}
+	for i, city := range [][2]float64{
+		{40.71, -74.01},  // New York
+		{47.61, -122.33}, // Seattle
+		{35.68, 139.69},  // Tokyo
+		{-33.87, 151.21}, // Sydney
+		{51.51, -0.13},   // London
+		{25.76, -80.19},  // Miami
+	} {
+		lat, lng := geo.ResolveDrop(city[0], city[1])
+		store.active = append(store.active, domain.Bottle{ID: int32(i + 1), CurrentLat: lat, CurrentLng: lng, Status: domain.BottleStatusDrifting})
+	}
+
+	at := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	svc := service.NewDriftService(nil,
+		&driftBottles{store: store},
+		&driftEvents{store: store},
+		ws.NewBroadcaster(ws.NewHub(), zap.NewNop()),
+		zap.NewNop(),
+		service.WithDriftTx(store.tx),
+		service.WithDriftClock(func() time.Time { return at }),
+	)
+	const ticks = 2 * drift.TicksPerDay
+	for tick := 0; tick < ticks; tick++ {
+		store.moves = nil
+		if err := svc.Tick(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(store.moves) != len(store.active) {
+			t.Fatalf("tick %d moved %d of %d bottles", tick, len(store.moves), len(store.active))
+		}
+		for i, m := range store.moves {
+			store.active[i].CurrentLat, store.active[i].CurrentLng = m.Lat, m.Lng
+		}
+		at = at.Add(drift.TickInterval)
+	}
+	for _, e := range store.events {
+		if e.EventType != domain.EventTypeDrift {
+			t.Fatalf("bottle %d got a %s event; shore casts must keep drifting", e.BottleID, e.EventType)
+		}
+	}
+}
+
+func BenchmarkDriftTick100k(b *testing.B) {
+	store := &driftStore{}
+	for id := int32(1); id <= 100_000; id++ {
+		store.active = append(store.active, domain.Bottle{
+			ID:         id,
+			CurrentLat: float64(id%120) - 60,
+			CurrentLng: float64(id%360) - 180,
+			Status:     domain.BottleStatusDrifting,
+		})
+	}
+	svc := newDriftTestService(store, service.DefaultDriftBatchSize)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		store.moves, store.events = store.moves[:0], store.events[:0]
+		if err := svc.Tick(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDriftTick100kPostgres times the real COPY + unnest path. It needs a
+// throwaway database: OCEALIS_BENCH_DATABASE_URL=postgres://... go test -bench DriftTick100kPostgres
+func BenchmarkDriftTick100kPostgres(b *testing.B) {
+	url := os.Getenv("OCEALIS_BENCH_DATABASE_URL")
+	if url == "" {
+		b.Skip("OCEALIS_BENCH_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	migrator, err := db.NewMigrator(pool, zap.NewNop())
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO bottles (nickname, message_text, start_lat, start_lng, current_lat, current_lng, status, is_release)
+		SELECT 'bench', 'drift benchmark', lat, lng, lat, lng, 'drifting', TRUE
+		FROM (SELECT (g % 120) - 60 AS lat, (g % 360) - 180 AS lng FROM generate_series(1, 100000) g) s`); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		_, _ = pool.Exec(ctx, `DELETE FROM bottle_events WHERE bottle_id IN (SELECT id FROM bottles WHERE nickname = 'bench')`)
+		_, _ = pool.Exec(ctx, `DELETE FROM bottles WHERE nickname = 'bench'`)
+	})
+
+	q := ocealis.New(pool)
+	svc := service.NewDriftService(pool,
+		repository.NewBottleRepository(q),
+		repository.NewEventRepository(q),
+		ws.NewBroadcaster(ws.NewHub(), zap.NewNop()),
+		zap.NewNop(),
+	)
+	for b.Loop() {
+		if err := svc.Tick(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/replay"
+	"github.com/Polqt/ocealis/internal/repository"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/Polqt/ocealis/ws"
+)
+
+// statsWindow is how far back Casts24h and Stamps24h reach.
+const statsWindow = 24 * time.Hour
+
+// OceanService answers whole-Ocean questions: what the sea looked like, and when.
+type OceanService interface {
+	// Replay loads every Cork seen in bbox during the window so frames can be built lazily.
+	Replay(ctx context.Context, w replay.Window, bbox replay.BBox) (*replay.Timeline, error)
+	// Stats is the Ocean-wide counts for the splash globe.
+	Stats(ctx context.Context) (*domain.OceanStats, error)
+	// PublishStats pushes Stats to WebSocket clients when they changed since the last push.
+	PublishStats(ctx context.Context) error
+	// Notable ranks drifting Bottles by distance, re-releases, Stamps, basins and age.
+	Notable(ctx context.Context, limit int32) (*domain.NotableRankings, error)
+}
+
+type oceanService struct {
+	events repository.EventRepository
+	stats  repository.OceanStatsRepository
+	bc     *ws.Broadcaster
+	now    func() time.Time
+
+	mu        sync.Mutex
+	published *domain.OceanStats
+}
+
+func NewOceanService(events repository.EventRepository, stats repository.OceanStatsRepository, bc *ws.Broadcaster) OceanService {
+	return &oceanService{events: events, stats: stats, bc: bc, now: time.Now}
+}
+
+func (s *oceanService) Replay(ctx context.Context, w replay.Window, bbox replay.BBox) (_ *replay.Timeline, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanService.Replay")
+	defer func() { telemetry.End(span, err) }()
+
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	points, err := s.events.ListReplay(ctx, w, bbox)
+	if err != nil {
+		return nil, err
+	}
+	return replay.NewTimeline(w, bbox, points), nil
+}
+
+func (s *oceanService) Stats(ctx context.Context) (_ *domain.OceanStats, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanService.Stats")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.stats.Stats(ctx, s.now().Add(-statsWindow))
+}
+
+func (s *oceanService) PublishStats(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "OceanService.PublishStats")
+	defer func() { telemetry.End(span, err) }()
+
+	stats, err := s.Stats(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats.Equal(s.published) {
+		return nil
+	}
+	s.published = stats
+	s.bc.BroadcastOceanStats(stats)
+	return nil
+}
+
+func (s *oceanService) Notable(ctx context.Context, limit int32) (_ *domain.NotableRankings, err error) {
+	ctx, span := telemetry.Start(ctx, "OceanService.Notable")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.stats.Notable(ctx, limit)
+}
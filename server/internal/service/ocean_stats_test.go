@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/ws"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type statsRepo struct {
+	stats domain.OceanStats
+	since time.Time
+}
+
+func (r *statsRepo) Stats(_ context.Context, since time.Time) (*domain.OceanStats, error) {
+	r.since = since
+	s := r.stats
+	return &s, nil
+}
+
+func (r *statsRepo) PruneActivity(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func (r *statsRepo) Notable(context.Context, int32) (*domain.NotableRankings, error) {
+	return &domain.NotableRankings{}, nil
+}
+
+// wsClient connects one real WebSocket client to hub and waits until it is registered.
+func wsClient(t *testing.T, hub *ws.Hub) *websocket.Conn {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/ws", ws.NewDriftHandler(hub, zap.NewNop()))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	for deadline := time.Now().Add(2 * time.Second); hub.ClientCount() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("client never registered with the hub")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return conn
+}
+
+func TestPublishStatsPushesOnlyChanges(t *testing.T) {
+	hub := ws.NewHub()
+	conn := wsClient(t, hub)
+	repo := &statsRepo{stats: domain.OceanStats{Drifting: 3, Basins: map[string]int64{"north_pacific": 3}}}
+	svc := service.NewOceanService(nil, repo, ws.NewBroadcaster(hub, zap.NewNop()))
+	ctx := context.Background()
+
+	if err := svc.PublishStats(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ago := time.Since(repo.since); ago < 24*time.Hour || ago > 24*time.Hour+time.Minute {
+		t.Fatalf("activity window starts %v ago, want 24h", ago)
+	}
+	if err := svc.PublishStats(ctx); err != nil { // unchanged — no second push
+		t.Fatal(err)
+	}
+	repo.stats = domain.OceanStats{Drifting: 4, Basins: map[string]int64{"north_pacific": 4}}
+	if err := svc.PublishStats(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int64
+	_ = conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var msg struct {
+			Type    ws.MessageType    `json:"type"`
+			Payload domain.OceanStats `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != ws.MsgOceanStats {
+			t.Fatalf("unexpected message %s (%v)", data, err)
+		}
+		got = append(got, msg.Payload.Basins["north_pacific"])
+	}
+	if len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Fatalf("want pushes [3 4], got %v", got)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/discovery"
 	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/replay"
 	"github.com/Polqt/ocealis/internal/repository"
 	"github.com/Polqt/ocealis/internal/service"
 )
@@ -29,6 +31,9 @@ func (r *openBottleRepo) UpdateStatus(context.Context, int32, domain.BottleStatu
 func (r *openBottleRepo) UpdatePosition(context.Context, int32, float64, float64, domain.BottleStatus) (*domain.Bottle, error) {
 	return nil, nil
 }
+func (r *openBottleRepo) MoveDrifting(context.Context, []repository.BottleMove) ([]int32, error) {
+	return nil, nil
+}
 func (r *openBottleRepo) ListActive(context.Context) ([]domain.Bottle, error) { return nil, nil }
 func (r *openBottleRepo) ReleaseScheduled(context.Context) ([]domain.Bottle, error) {
 	return nil, nil
@@ -36,6 +41,17 @@ func (r *openBottleRepo) ReleaseScheduled(context.Context) ([]domain.Bottle, err
 func (r *openBottleRepo) FindNearby(context.Context, repository.FindNearbyParams) (*domain.CursorResult[domain.Bottle], error) {
 	return nil, nil
 }
+func (r *openBottleRepo) RecentFingerprints(context.Context, time.Time) ([]uint64, error) {
+	return nil, nil
+}
+func (r *openBottleRepo) Search(context.Context, repository.SearchParams) (*domain.CursorResult[domain.SearchHit], error) {
+	return &domain.CursorResult[domain.SearchHit]{}, nil
+}
+func (r *openBottleRepo) SampleDrifting(context.Context, repository.SampleParams) ([]discovery.Candidate, error) {
+	return nil, nil
+}
+func (r *openBottleRepo) Generation(context.Context) (int32, error)           { return 0, nil }
+func (r *openBottleRepo) BumpGeneration(context.Context) error                { return nil }
 func (r *openBottleRepo) WithTx(*ocealis.Queries) repository.BottleRepository { return r }
 
 type journeyEventsRepo struct {
@@ -45,13 +61,23 @@ type journeyEventsRepo struct {
 func (r *journeyEventsRepo) Create(context.Context, repository.CreateEventParams) (*domain.BottleEvent, error) {
 	return nil, nil
 }
+func (r *journeyEventsRepo) CreateBatch(context.Context, []repository.CreateEventParams) (int64, error) {
+	return 0, nil
+}
 func (r *journeyEventsRepo) GetByBottleID(context.Context, int32) ([]domain.BottleEvent, error) {
 	return r.events, nil
 }
 func (r *journeyEventsRepo) GetPaginated(context.Context, repository.GetEventParams) (*domain.CursorResult[domain.BottleEvent], error) {
 	return nil, nil
 }
-func (r *journeyEventsRepo) WithTx(*ocealis.Queries) repository.EventRepository { return r }
+func (r *journeyEventsRepo) GetHighlights(context.Context, int32, int32) ([]domain.BottleEvent, error) {
+	return r.events, nil
+}
+func (r *journeyEventsRepo) ListReplay(context.Context, replay.Window, replay.BBox) ([]replay.Point, error) {
+	return nil, nil
+}
+func (r *journeyEventsRepo) CompactDrift(context.Context, time.Time) (int64, error) { return 0, nil }
+func (r *journeyEventsRepo) WithTx(*ocealis.Queries) repository.EventRepository     { return r }
 
 func TestOpenDoesNotClaimOrRemoveBottle(t *testing.T) {
 	bottle := &domain.Bottle{
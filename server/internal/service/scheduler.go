@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"github.com/Polqt/ocealis/internal/telemetry"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
@@ -12,6 +13,14 @@ type Scheduler struct {
 	drift DriftService
 	log   *zap.Logger
 	ctx   context.Context
+	jobs  []scheduledJob
+}
+
+// scheduledJob is housekeeping registered with AddJob alongside drift.
+type scheduledJob struct {
+	spec string
+	name string
+	run  func(ctx context.Context) error
 }
 
 func NewScheduler(drift DriftService, log *zap.Logger) *Scheduler {
@@ -22,6 +31,11 @@ func NewScheduler(drift DriftService, log *zap.Logger) *Scheduler {
 	}
 }
 
+// AddJob registers a housekeeping job on a cron spec. Call before Start.
+func (s *Scheduler) AddJob(spec, name string, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, scheduledJob{spec: spec, name: name, run: run})
+}
+
 func (s *Scheduler) Start(ctx context.Context) {
 	s.ctx = ctx
 
@@ -29,9 +43,7 @@ func (s *Scheduler) Start(ctx context.Context) {
 	// they just have different release times.
 	// So we can use the same scheduler to check for both.
 	if _, err := s.cron.AddFunc("*/15 * * * *", func() {
-		if err := s.drift.Tick(s.ctx); err != nil {
-			s.log.Error("drift tick failed", zap.Error(err))
-		}
+		s.run("drift_tick", "drift tick failed", s.drift.Tick)
 	}); err != nil {
 		s.log.Error("failed to register drift tick", zap.Error(err))
 		return
@@ -41,18 +53,36 @@ func (s *Scheduler) Start(ctx context.Context) {
 	// be missed if the server restarts between ticks.
 	// It runs every minute and checks for any scheduled releases that are due.
 	if _, err := s.cron.AddFunc("* * * * *", func() {
-		if err := s.drift.ReleaseScheduled(s.ctx); err != nil {
-			s.log.Error("scheduled release failed", zap.Error(err))
-		}
+		s.run("release_scheduled", "scheduled release failed", s.drift.ReleaseScheduled)
 	}); err != nil {
 		s.log.Error("failed to register scheduled release job", zap.Error(err))
 		return
 	}
 
+	for _, job := range s.jobs {
+		job := job
+		if _, err := s.cron.AddFunc(job.spec, func() {
+			s.run(job.name, "scheduled job failed", job.run)
+		}); err != nil {
+			s.log.Error("failed to register scheduled job", zap.String("job", job.name), zap.Error(err))
+		}
+	}
+
 	s.cron.Start()
 	s.log.Info("scheduler started - drift tick every 15 mins.")
 }
 
+// run executes one job under its own root span so ticks show up in traces,
+// and logs failures with the span's trace id.
+func (s *Scheduler) run(name, failMsg string, job func(ctx context.Context) error) {
+	ctx, span := telemetry.Start(s.ctx, "scheduler "+name)
+	err := job(ctx)
+	telemetry.End(span, err)
+	if err != nil {
+		telemetry.Logger(ctx, s.log).Error(failMsg, zap.String("job", name), zap.Error(err))
+	}
+}
+
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
 	s.log.Info("scheduler stopped")
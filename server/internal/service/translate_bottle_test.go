@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/internal/translate"
+)
+
+// recordingTranslator notes the languages it was asked for.
+type recordingTranslator struct {
+	translate.Dictionary
+	source, target string
+}
+
+func (r *recordingTranslator) Translate(ctx context.Context, text, source, target string) (string, error) {
+	r.source, r.target = source, target
+	return r.Dictionary.Translate(ctx, text, source, target)
+}
+
+func TestTranslateBottleKeepsOriginalAndSkipsSameLanguage(t *testing.T) {
+	bottle := &domain.Bottle{ID: 4, MessageText: "the sea is calm and we are far from home"}
+	tr := &recordingTranslator{Dictionary: translate.Dictionary{
+		"es": {bottle.MessageText: "el mar está en calma y estamos lejos de casa"},
+	}}
+	svc := service.NewBottleService(nil, &openBottleRepo{bottle: bottle}, &journeyEventsRepo{}, nil, service.WithTranslator(tr))
+
+	out, err := svc.TranslateBottle(context.Background(), 4, "es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.MessageText != bottle.MessageText || out.Translation.MessageText != "el mar está en calma y estamos lejos de casa" {
+		t.Fatalf("got %+v", out)
+	}
+	if out.Translation.SourceLang != "en" || tr.source != "en" || tr.target != "es" {
+		t.Fatalf("source %q, translator asked %q→%q", out.Translation.SourceLang, tr.source, tr.target)
+	}
+
+	tr.target = ""
+	out, err = svc.TranslateBottle(context.Background(), 4, "en-GB")
+	if err != nil || out.Translation.MessageText != bottle.MessageText || tr.target != "" {
+		t.Fatalf("same-language read must not call the translator: %+v %v (asked %q)", out, err, tr.target)
+	}
+
+	if _, err := svc.TranslateBottle(context.Background(), 4, "ja"); !errors.Is(err, translate.ErrUnsupported) {
+		t.Fatalf("err = %v", err)
+	}
+
+	unconfigured := service.NewBottleService(nil, &openBottleRepo{bottle: bottle}, &journeyEventsRepo{}, nil)
+	if _, err := unconfigured.TranslateBottle(context.Background(), 4, "es"); !errors.Is(err, service.ErrTranslationDisabled) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package styles is the bottle_style catalog: what each style is called, which client
+// asset draws it, how it rides the wind and whether Visitors may Cast it. The catalog
+// is embedded JSON so the API, the drift engine and the simulate command agree on it.
+package styles
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+//go:embed styles.json
+var stylesJSON []byte
+
+// Seed is the reserved style Ocealis Seed Bottles are drawn with.
+const Seed int32 = 10
+
+var (
+	ErrUnknownStyle  = errors.New("unknown bottle style")
+	ErrReservedStyle = errors.New("bottle style is reserved")
+)
+
+// Style is one catalog entry.
+type Style struct {
+	ID       int32  `json:"id"`
+	Name     string `json:"name"`
+	AssetKey string `json:"asset_key"`
+	// Leeway scales the current's speed: heavy glass lags it, light glass catches the wind.
+	Leeway float64 `json:"leeway"`
+	// Reserved styles are Ocealis's own and cannot be Cast.
+	Reserved bool `json:"reserved"`
+}
+
+var catalog = sync.OnceValue(func() []Style {
+	var all []Style
+	if err := json.Unmarshal(stylesJSON, &all); err != nil {
+		panic("styles: embedded styles.json: " + err.Error())
+	}
+	return all
+})
+
+// All lists every style in id order. Callers must not modify the result.
+func All() []Style {
+	return catalog()
+}
+
+// Get looks a style up by id.
+func Get(id int32) (Style, bool) {
+	for _, s := range catalog() {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Style{}, false
+}
+
+// Castable reports why a Visitor may not Cast style id, or nil when they may.
+func Castable(id int32) error {
+	s, ok := Get(id)
+	switch {
+	case !ok:
+		return ErrUnknownStyle
+	case s.Reserved:
+		return ErrReservedStyle
+	}
+	return nil
+}
+
+// Leeway is id's drift leeway; Bottles of a style no longer in the catalog drift at 1.
+func Leeway(id int32) float64 {
+	if s, ok := Get(id); ok {
+		return s.Leeway
+	}
+	return 1
+}
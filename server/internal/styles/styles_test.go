@@ -0,0 +1,46 @@
+package styles_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/styles"
+)
+
+func TestCatalogIsWellFormed(t *testing.T) {
+	all := styles.All()
+	if len(all) == 0 {
+		t.Fatal("empty catalog")
+	}
+	keys := map[string]bool{}
+	for i, s := range all {
+		if s.ID != int32(i) {
+			t.Fatalf("style %d listed at %d; ids must be contiguous from 0", s.ID, i)
+		}
+		if s.Name == "" || s.AssetKey == "" || keys[s.AssetKey] {
+			t.Fatalf("style %d: blank or duplicate name/asset key %+v", s.ID, s)
+		}
+		keys[s.AssetKey] = true
+		if s.Leeway < 0.5 || s.Leeway > 1.5 {
+			t.Fatalf("style %d: leeway %v out of range", s.ID, s.Leeway)
+		}
+	}
+	if seed, ok := styles.Get(styles.Seed); !ok || !seed.Reserved {
+		t.Fatalf("Seed style must exist and be reserved, got %+v", seed)
+	}
+}
+
+func TestCastable(t *testing.T) {
+	if err := styles.Castable(0); err != nil {
+		t.Fatalf("style 0: %v", err)
+	}
+	if err := styles.Castable(styles.Seed); !errors.Is(err, styles.ErrReservedStyle) {
+		t.Fatalf("Seed style: %v", err)
+	}
+	if err := styles.Castable(-1); !errors.Is(err, styles.ErrUnknownStyle) {
+		t.Fatalf("style -1: %v", err)
+	}
+	if styles.Leeway(99) != 1 {
+		t.Fatal("unknown styles drift at leeway 1")
+	}
+}
@@ -0,0 +1,118 @@
+// Package telemetry wires OpenTelemetry tracing for handlers, services, repositories and jobs.
+// Spans go through the global TracerProvider so packages only need Tracer().
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const tracerName = "github.com/Polqt/ocealis"
+
+// Tracer is the Ocealis tracer on the global provider (no-op until Setup/Install).
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Setup picks an exporter from env and installs it globally:
+//
+//	OTEL_EXPORTER_OTLP_ENDPOINT set → OTLP/HTTP
+//	OTEL_TRACES_EXPORTER=stdout     → pretty JSON on stdout
+//	otherwise                       → none (spans are dropped)
+//
+// The returned shutdown flushes pending spans.
+func Setup(ctx context.Context, log *zap.Logger) (func(context.Context) error, error) {
+	var exp sdktrace.SpanExporter
+	switch {
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" && os.Getenv("OTEL_TRACES_EXPORTER") != "none":
+		otlp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+		exp = otlp
+	case strings.EqualFold(os.Getenv("OTEL_TRACES_EXPORTER"), "stdout"):
+		stdout, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("stdout exporter: %w", err)
+		}
+		exp = stdout
+	default:
+		log.Info("tracing disabled — set OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_TRACES_EXPORTER=stdout")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tp := NewProvider(exp)
+	Install(tp)
+	log.Info("tracing enabled", zap.String("exporter", fmt.Sprintf("%T", exp)))
+	return tp.Shutdown, nil
+}
+
+// NewProvider builds a batching provider around exp.
+// Tests use sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracetest.NewInMemoryExporter())) instead.
+func NewProvider(exp sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("ocealis"))),
+	)
+}
+
+// Install makes tp the global provider with W3C trace-context propagation.
+func Install(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Start opens a span on the Ocealis tracer.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span (if any) and ends it. Use with a named error return:
+//
+//	ctx, span := telemetry.Start(ctx, "x")
+//	defer func() { telemetry.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// LogFields returns trace_id/span_id for the span in ctx, or nothing outside a trace.
+func LogFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// Logger decorates log with the trace ids in ctx so zap lines join up with spans.
+func Logger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	fields := LogFields(ctx)
+	if len(fields) == 0 {
+		return log
+	}
+	return log.With(fields...)
+}
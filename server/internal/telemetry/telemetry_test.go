@@ -0,0 +1,41 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerCarriesTraceIDsAndEndRecordsErrors(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	telemetry.Install(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	if fields := telemetry.LogFields(context.Background()); fields != nil {
+		t.Fatalf("want no fields outside a trace, got %v", fields)
+	}
+
+	ctx, span := telemetry.Start(context.Background(), "scheduler drift_tick")
+	core, logs := observer.New(zap.InfoLevel)
+	telemetry.Logger(ctx, zap.New(core)).Info("tick")
+	telemetry.End(span, errors.New("db down"))
+
+	entry := logs.All()[0].ContextMap()
+	if entry["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Fatalf("want trace_id on log line, got %v", entry)
+	}
+	got := exp.GetSpans()
+	if len(got) != 1 || got[0].Status.Code != codes.Error || got[0].Status.Description != "db down" {
+		t.Fatalf("want one errored span, got %+v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package translate
+
+import (
+	"context"
+
+	"github.com/Polqt/ocealis/internal/lru"
+	"github.com/Polqt/ocealis/internal/metrics"
+)
+
+// DefaultCacheSize bounds a Cache built with a non-positive size.
+const DefaultCacheSize = 1024
+
+// Cache is a Translator that remembers the most recent results of the one it wraps.
+// Messages never change after Cast, so an entry is good until it is evicted.
+type Cache struct {
+	next    Translator
+	entries *lru.Cache[cacheKey, string]
+}
+
+type cacheKey struct {
+	text, source, target string
+}
+
+func NewCache(next Translator, size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{next: next, entries: lru.New[cacheKey, string](size)}
+}
+
+// Translate serves a cached translation or asks the wrapped Translator. Failures are
+// not cached, so a backend that comes back is used on the next request.
+func (c *Cache) Translate(ctx context.Context, text, source, target string) (string, error) {
+	key := cacheKey{text: text, source: source, target: target}
+	if out, ok := c.entries.Get(key); ok {
+		metrics.Translations.WithLabelValues("hit").Inc()
+		return out, nil
+	}
+
+	out, err := c.next.Translate(ctx, text, source, target)
+	if err != nil {
+		metrics.Translations.WithLabelValues("error").Inc()
+		return "", err
+	}
+	metrics.Translations.WithLabelValues("translate").Inc()
+	c.entries.Add(key, out)
+	return out, nil
+}
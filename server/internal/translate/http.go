@@ -0,0 +1,71 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds one translation request when HTTP.Client is nil.
+const DefaultTimeout = 5 * time.Second
+
+// HTTP is a LibreTranslate-compatible client: POST {BaseURL}/translate. Point BaseURL
+// at a self-hosted server, or at a local stub speaking the same JSON.
+type HTTP struct {
+	BaseURL string
+	// APIKey is sent as api_key when the server requires one.
+	APIKey string
+	Client *http.Client
+}
+
+type libreRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+func (h *HTTP) Translate(ctx context.Context, text, source, target string) (string, error) {
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	body, err := json.Marshal(libreRequest{Q: text, Source: source, Target: target, Format: "text", APIKey: h.APIKey})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(h.BaseURL, "/")+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var out libreResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&out)
+	switch {
+	// LibreTranslate answers 400 for language pairs it has no model for.
+	case resp.StatusCode == http.StatusBadRequest:
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, out.Error)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%w: status %d %s", ErrUnavailable, resp.StatusCode, out.Error)
+	case decodeErr != nil:
+		return "", fmt.Errorf("%w: decode response: %v", ErrUnavailable, decodeErr)
+	}
+	return out.TranslatedText, nil
+}
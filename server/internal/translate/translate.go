@@ -0,0 +1,43 @@
+// Package translate renders Messages in a reader's language. Translator is the seam:
+// HTTP talks to a self-hosted LibreTranslate-compatible server, Noop and Dictionary
+// stand in for it in tests and local runs, and Cache keeps recent results in memory.
+package translate
+
+import (
+	"context"
+	"errors"
+)
+
+// Auto asks the Translator to detect the source language itself.
+const Auto = "auto"
+
+var (
+	// ErrUnsupported means the Translator cannot translate between the two languages.
+	ErrUnsupported = errors.New("translation not supported")
+	// ErrUnavailable means the translation backend failed or could not be reached.
+	ErrUnavailable = errors.New("translation unavailable")
+)
+
+// Translator translates text from source to target, both ISO 639-1 codes; source may
+// be Auto.
+type Translator interface {
+	Translate(ctx context.Context, text, source, target string) (string, error)
+}
+
+// Noop returns text unchanged, whatever the languages.
+type Noop struct{}
+
+func (Noop) Translate(_ context.Context, text, _, _ string) (string, error) {
+	return text, nil
+}
+
+// Dictionary translates from a fixed table: target language → source text → translation.
+// Text missing from the table is ErrUnsupported.
+type Dictionary map[string]map[string]string
+
+func (d Dictionary) Translate(_ context.Context, text, _, target string) (string, error) {
+	if out, ok := d[target][text]; ok {
+		return out, nil
+	}
+	return "", ErrUnsupported
+}
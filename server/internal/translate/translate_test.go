@@ -0,0 +1,107 @@
+package translate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polqt/ocealis/internal/translate"
+)
+
+// libreStub speaks LibreTranslate's /translate JSON from a Dictionary.
+func libreStub(t *testing.T, dict translate.Dictionary, apiKey string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q, Source, Target, Format string
+			APIKey                    string `json:"api_key"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/translate" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.APIKey != apiKey {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid api key"})
+			return
+		}
+		out, err := dict.Translate(r.Context(), req.Q, req.Source, req.Target)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": req.Target + " is not supported"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"translatedText": out})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPTranslatesAgainstLibreTranslateStub(t *testing.T) {
+	srv := libreStub(t, translate.Dictionary{"es": {"hello ocean": "hola océano"}}, "k")
+	ctx := context.Background()
+
+	tr := &translate.HTTP{BaseURL: srv.URL + "/", APIKey: "k"}
+	out, err := tr.Translate(ctx, "hello ocean", "en", "es")
+	if err != nil || out != "hola océano" {
+		t.Fatalf("Translate = %q, %v", out, err)
+	}
+	if _, err := tr.Translate(ctx, "hello ocean", "en", "xx"); !errors.Is(err, translate.ErrUnsupported) {
+		t.Fatalf("unsupported pair: err = %v", err)
+	}
+	if _, err := (&translate.HTTP{BaseURL: srv.URL}).Translate(ctx, "hello ocean", "en", "es"); !errors.Is(err, translate.ErrUnavailable) {
+		t.Fatalf("rejected key: err = %v", err)
+	}
+	srv.Close()
+	if _, err := tr.Translate(ctx, "hello ocean", "en", "es"); !errors.Is(err, translate.ErrUnavailable) {
+		t.Fatalf("server down: err = %v", err)
+	}
+}
+
+type countingTranslator struct {
+	calls int
+	err   error
+}
+
+func (c *countingTranslator) Translate(_ context.Context, text, _, target string) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	return target + ":" + text, nil
+}
+
+func TestCacheServesRepeatsAndEvictsLeastRecent(t *testing.T) {
+	ctx := context.Background()
+	next := &countingTranslator{}
+	cache := translate.NewCache(next, 2)
+
+	for range 3 {
+		if out, _ := cache.Translate(ctx, "a", "en", "fr"); out != "fr:a" {
+			t.Fatalf("got %q", out)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("repeats must be cached, got %d calls", next.calls)
+	}
+	_, _ = cache.Translate(ctx, "a", "en", "de") // different target, different entry
+	_, _ = cache.Translate(ctx, "b", "en", "fr") // evicts a→fr
+	_, _ = cache.Translate(ctx, "a", "en", "fr")
+	if next.calls != 4 {
+		t.Fatalf("want least recent entry evicted, got %d calls", next.calls)
+	}
+
+	failing := &countingTranslator{err: translate.ErrUnavailable}
+	cache = translate.NewCache(failing, 2)
+	for range 2 {
+		if _, err := cache.Translate(ctx, "a", "en", "fr"); !errors.Is(err, translate.ErrUnavailable) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if failing.calls != 2 {
+		t.Fatalf("failures must not be cached, got %d calls", failing.calls)
+	}
+}
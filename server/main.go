@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,8 +14,13 @@ import (
 	"github.com/Polqt/ocealis/api/middleware"
 	"github.com/Polqt/ocealis/db"
 	dbGen "github.com/Polqt/ocealis/db/ocealis"
+	"github.com/Polqt/ocealis/internal/card"
+	"github.com/Polqt/ocealis/internal/discovery"
+	"github.com/Polqt/ocealis/internal/metrics"
 	"github.com/Polqt/ocealis/internal/repository"
 	"github.com/Polqt/ocealis/internal/service"
+	"github.com/Polqt/ocealis/internal/telemetry"
+	"github.com/Polqt/ocealis/internal/translate"
 	"github.com/Polqt/ocealis/util"
 	"github.com/Polqt/ocealis/ws"
 	"github.com/gofiber/fiber/v3"
@@ -29,31 +35,89 @@ func main() {
 		_ = log.Sync()
 	}()
 
+	// simulate runs the drift engine offline — no database, no tracing.
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := runSimulate(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			log.Fatal("simulate error", zap.Error(err))
+		}
+		return
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), log)
+	if err != nil {
+		log.Fatal("tracing setup error", zap.Error(err))
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
+
 	if err := db.Connect(log); err != nil {
 		log.Fatal("database connection error", zap.Error(err))
 	}
 	defer db.Pool.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), log, os.Args[2:]); err != nil {
+			log.Fatal("migrate error", zap.Error(err))
+		}
+		return
+	}
+	if util.EnvBool("MIGRATE_ON_START", false) {
+		if err := migrateOnStart(context.Background(), log); err != nil {
+			log.Fatal("migrate on start error", zap.Error(err))
+		}
+	}
+	metrics.RegisterPool(db.Pool)
+
 	queries := dbGen.New(db.Pool)
 
 	bottleRepo := repository.NewBottleRepository(queries)
 	eventRepo := repository.NewEventRepository(queries)
+	idempotencyRepo := repository.NewIdempotencyRepository(queries)
+	oceanStatsRepo := repository.NewOceanStatsRepository(queries)
 	// userRepo / JWT login quarantined — not product v1 (PRD US28).
 
 	hub := ws.NewHub()
 	broadcaster := ws.NewBroadcaster(hub, log)
 
-	bottleSvc := service.NewBottleService(db.Pool, bottleRepo, eventRepo, broadcaster)
-	driftSvc := service.NewDriftService(db.Pool, bottleRepo, eventRepo, broadcaster, log)
-	discoverySvc := service.NewDiscoveryService(bottleRepo)
-
-	turnstile := &middleware.Turnstile{Secret: util.EnvString("TURNSTILE_SECRET", "")}
+	// TRANSLATE_URL points at a LibreTranslate-compatible server; unset, ?lang= answers 501.
+	var bottleOpts []service.BottleOption
+	if translateURL := util.EnvString("TRANSLATE_URL", ""); translateURL != "" {
+		translator := &translate.HTTP{BaseURL: translateURL, APIKey: util.EnvString("TRANSLATE_API_KEY", "")}
+		bottleOpts = append(bottleOpts, service.WithTranslator(
+			translate.NewCache(translator, util.EnvInt("TRANSLATE_CACHE_SIZE", translate.DefaultCacheSize))))
+	}
+	bottleSvc := service.NewBottleService(db.Pool, bottleRepo, eventRepo, broadcaster, bottleOpts...)
+	driftSvc := service.NewDriftService(db.Pool, bottleRepo, eventRepo, broadcaster, log,
+		service.WithDriftSeed(uint64(util.EnvInt("DRIFT_SEED", 0))))
+	discoverySvc := service.NewDiscoveryService(bottleRepo,
+		service.WithMapCache(discovery.NewMapCache(util.EnvInt("MAP_CACHE_SIZE", discovery.DefaultMapCacheSize))))
+	oceanSvc := service.NewOceanService(eventRepo, oceanStatsRepo, broadcaster)
+
+	// CAST_VERIFIER=pow swaps Cloudflare Turnstile for the self-hosted hashcash challenge.
+	var turnstile middleware.TurnstileVerifier = &middleware.Turnstile{Secret: util.EnvString("TURNSTILE_SECRET", "")}
+	var challenge *handler.ChallengeHandler
+	if util.EnvString("CAST_VERIFIER", "turnstile") == "pow" {
+		pow := middleware.NewProofOfWork(util.EnvString("POW_SECRET", ""))
+		turnstile = pow
+		challenge = handler.NewChallengeHandler(pow)
+	}
+	dupes := middleware.NewNearDuplicateGuard(bottleRepo)
+	cards := card.NewCache(util.EnvInt("CARD_CACHE_SIZE", card.DefaultCacheSize))
+	share := handler.NewShareHandler(bottleSvc, cards, util.EnvString("PUBLIC_CLIENT_URL", "http://localhost:3000"))
 
 	h := api.Handlers{
-		Health:    handler.NewHealthHandler(db.Pool, hub),
-		Bottle:    handler.NewBottleHandler(bottleSvc, turnstile),
-		Event:     handler.NewEventHandler(eventRepo),
-		Discovery: handler.NewDiscoveryHandler(discoverySvc),
+		Health:      handler.NewHealthHandler(db.Pool, hub),
+		Bottle:      handler.NewBottleHandler(bottleSvc, turnstile, dupes),
+		Event:       handler.NewEventHandler(eventRepo),
+		Discovery:   handler.NewDiscoveryHandler(discoverySvc),
+		Ocean:       handler.NewOceanHandler(oceanSvc),
+		Share:       share,
+		Challenge:   challenge,
+		Idempotency: idempotencyRepo,
 	}
 
 	app := fiber.New(fiber.Config{
@@ -63,27 +127,42 @@ func main() {
 		ServerHeader:  "Ocealis",
 		ReadTimeout:   10 * time.Second,
 		WriteTimeout:  10 * time.Second,
-		ErrorHandler: func(c fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			msg := "Internal Server Error"
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-				msg = e.Message
-			}
-			return c.Status(code).JSON(fiber.Map{"error": msg})
-		},
+		ErrorHandler:  handler.ErrorHandler,
 	})
 
 	app.Use(recover.New())
+	app.Use(middleware.Metrics())
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestLogger(log))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: []string{util.EnvString("CORS_ALLOWED_ORIGINS", "http://localhost:3000")},
 		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "CF-Turnstile-Response"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "CF-Turnstile-Response", middleware.IdempotencyKeyHeader, middleware.WireVersionHeader},
 	}))
 
 	api.RegisterRoutes(app, h, hub, log)
 
 	scheduler := service.NewScheduler(driftSvc, log)
+	scheduler.AddJob("@hourly", "prune idempotency keys", func(ctx context.Context) error {
+		_, err := idempotencyRepo.DeleteExpired(ctx)
+		return err
+	})
+	// Drift older than a week collapses into one waypoint per Bottle per day.
+	compactAfter := util.EnvInt("DRIFT_COMPACT_AFTER_DAYS", 7)
+	scheduler.AddJob("@daily", "compact drift events", func(ctx context.Context) error {
+		removed, err := eventRepo.CompactDrift(ctx, time.Now().AddDate(0, 0, -compactAfter))
+		if err != nil || removed == 0 {
+			return err
+		}
+		log.Info("compacted drift events", zap.Int64("removed", removed))
+		return bottleRepo.BumpGeneration(ctx) // compacted Journeys render differently
+	})
+	// Splash-globe counts change with every drift tick; clients get a push when they do.
+	scheduler.AddJob("@every 30s", "publish ocean stats", oceanSvc.PublishStats)
+	scheduler.AddJob("@daily", "prune ocean activity", func(ctx context.Context) error {
+		_, err := oceanStatsRepo.PruneActivity(ctx, time.Now().Add(-48*time.Hour))
+		return err
+	})
 	appCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Polqt/ocealis/internal/drift"
+	"github.com/Polqt/ocealis/internal/export"
+	"github.com/Polqt/ocealis/internal/geo"
+	"github.com/Polqt/ocealis/internal/styles"
+)
+
+const simulateUsage = "usage: ocealis simulate --from lat,lng --days N [--style S] [--format geojson|csv] [--ensemble N] [--seed X] [--cell DEG] [--start RFC3339] [--bottle-id ID] [--out FILE]"
+
+type simulateOptions struct {
+	lat, lng float64
+	days     int
+	style    int
+	format   string
+	ensemble int
+	seed     uint64
+	cellDeg  float64
+	start    time.Time
+	bottleID int
+	out      string
+}
+
+// runSimulate handles `ocealis simulate`: the scheduler's drift engine, offline.
+// Data goes to --out (or stdout); the human summary goes to stderr.
+func runSimulate(args []string, stdout, stderr io.Writer) error {
+	opts, err := parseSimulateFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	if opts.out != "" {
+		f, err := os.Create(opts.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	model := drift.Model{Seed: opts.seed}
+	ticks := opts.days * drift.TicksPerDay
+
+	if opts.ensemble > 0 {
+		ens := model.SimulateEnsemble(opts.ensemble, opts.lat, opts.lng, styles.Leeway(int32(opts.style)), opts.start, ticks, opts.cellDeg)
+		fmt.Fprintf(stderr, "%d bottles from %.4f,%.4f over %d days: %.1f%% beached\n",
+			opts.ensemble, opts.lat, opts.lng, opts.days, 100*ens.BeachedShare())
+		for i, c := range ens.Cells {
+			if i == 5 {
+				break
+			}
+			fmt.Fprintf(stderr, "  %5.1f%%  lat %.1f..%.1f  lng %.1f..%.1f\n",
+				100*c.Share, c.MinLat, c.MinLat+c.Size, c.MinLng, c.MinLng+c.Size)
+		}
+		if opts.format == "csv" {
+			return writeHeatmapCSV(w, ens)
+		}
+		return writeHeatmapGeoJSON(w, ens)
+	}
+
+	track := model.Simulate(int32(opts.bottleID), opts.lat, opts.lng, styles.Leeway(int32(opts.style)), opts.start, ticks)
+	end := track.End()
+	verdict := "still drifting"
+	if track.Beached {
+		verdict = "beached"
+	}
+	fmt.Fprintf(stderr, "%s at %.4f,%.4f on %s after %.0f km (%d ticks)\n",
+		verdict, end.Lat, end.Lng, end.At.Format(time.RFC3339), track.DistanceKm, len(track.Points)-1)
+	if opts.format == "csv" {
+		return writeTrackCSV(w, track)
+	}
+	return writeTrackGeoJSON(w, track, opts)
+}
+
+func parseSimulateFlags(args []string, stderr io.Writer) (simulateOptions, error) {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, simulateUsage)
+		fs.PrintDefaults()
+	}
+
+	var opts simulateOptions
+	from := fs.String("from", "", "Cast point as lat,lng")
+	start := fs.String("start", "", "Cast time, RFC3339 (default now); pin it for reproducible runs")
+	fs.IntVar(&opts.days, "days", 30, "wall-clock days to simulate; each day is 96 scheduler ticks")
+	fs.IntVar(&opts.style, "style", 0, "bottle style id (GET /api/v1/styles); sets drift leeway and is recorded on the output")
+	fs.StringVar(&opts.format, "format", "geojson", "geojson or csv")
+	fs.IntVar(&opts.ensemble, "ensemble", 0, "Monte Carlo members; >0 outputs a destination heatmap instead of a path")
+	fs.Uint64Var(&opts.seed, "seed", 0, "drift noise seed (DRIFT_SEED on the server)")
+	fs.Float64Var(&opts.cellDeg, "cell", 1, "heatmap cell size in degrees")
+	fs.IntVar(&opts.bottleID, "bottle-id", 1, "bottle id the noise is keyed on; use a real id to replay its Journey")
+	fs.StringVar(&opts.out, "out", "", "write data here instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	var err error
+	if opts.lat, opts.lng, err = parseLatLng(*from); err != nil {
+		return opts, err
+	}
+	// Cast does the same: an inland point goes out from the nearest Shoreline drop.
+	opts.lat, opts.lng = geo.ResolveDrop(opts.lat, opts.lng)
+	opts.start = time.Now().UTC()
+	if *start != "" {
+		if opts.start, err = time.Parse(time.RFC3339, *start); err != nil {
+			return opts, fmt.Errorf("--start: %w", err)
+		}
+	}
+	_, knownStyle := styles.Get(int32(opts.style))
+	switch {
+	case opts.days < 1:
+		return opts, errors.New("--days must be at least 1")
+	case !knownStyle:
+		return opts, fmt.Errorf("--style %d: unknown bottle style", opts.style)
+	case opts.format != "geojson" && opts.format != "csv":
+		return opts, fmt.Errorf("--format %q: want geojson or csv", opts.format)
+	case opts.ensemble < 0:
+		return opts, errors.New("--ensemble must not be negative")
+	case opts.cellDeg <= 0 || opts.cellDeg > 90:
+		return opts, errors.New("--cell must be in (0, 90]")
+	}
+	return opts, nil
+}
+
+func parseLatLng(s string) (lat, lng float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("--from must be lat,lng")
+	}
+	if lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("--from: bad latitude %q", parts[0])
+	}
+	if lng, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil || lng < -180 || lng > 180 {
+		return 0, 0, fmt.Errorf("--from: bad longitude %q", parts[1])
+	}
+	return lat, lng, nil
+}
+
+func writeFeatureCollection(w io.Writer, features []export.Feature) error {
+	return json.NewEncoder(w).Encode(export.NewFeatureCollection(features))
+}
+
+func writeTrackGeoJSON(w io.Writer, t drift.Track, opts simulateOptions) error {
+	path := make([]export.Vertex, len(t.Points))
+	for i, p := range t.Points {
+		path[i] = export.Vertex{Lng: p.Lng, Lat: p.Lat, At: p.At}
+	}
+
+	end := t.End()
+	return writeFeatureCollection(w, []export.Feature{
+		{
+			Type:     "Feature",
+			Geometry: export.LineGeometry(export.SplitAntimeridian(path)),
+			Properties: map[string]any{
+				"bottle_id":    t.BottleID,
+				"bottle_style": opts.style,
+				"seed":         opts.seed,
+				"start":        opts.start.Format(time.RFC3339),
+				"ticks":        len(t.Points) - 1,
+				"distance_km":  math.Round(t.DistanceKm*10) / 10,
+				"beached":      t.Beached,
+			},
+		},
+		{
+			Type:     "Feature",
+			Geometry: export.Geometry{Type: "Point", Coordinates: [2]float64{end.Lng, end.Lat}},
+			Properties: map[string]any{
+				"at":      end.At.Format(time.RFC3339),
+				"beached": t.Beached,
+			},
+		},
+	})
+}
+
+func writeTrackCSV(w io.Writer, t drift.Track) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"tick", "at", "lat", "lng"})
+	for _, p := range t.Points {
+		_ = cw.Write([]string{
+			strconv.FormatInt(p.Tick, 10),
+			p.At.Format(time.RFC3339),
+			strconv.FormatFloat(p.Lat, 'f', 5, 64),
+			strconv.FormatFloat(p.Lng, 'f', 5, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeHeatmapGeoJSON(w io.Writer, ens drift.Ensemble) error {
+	features := make([]export.Feature, 0, len(ens.Cells))
+	for _, c := range ens.Cells {
+		minLng, minLat, maxLng, maxLat := c.MinLng, c.MinLat, c.MinLng+c.Size, c.MinLat+c.Size
+		features = append(features, export.Feature{
+			Type: "Feature",
+			Geometry: export.Geometry{Type: "Polygon", Coordinates: [][][2]float64{{
+				{minLng, minLat}, {maxLng, minLat}, {maxLng, maxLat}, {minLng, maxLat}, {minLng, minLat},
+			}}},
+			Properties: map[string]any{
+				"count":   c.Count,
+				"beached": c.Beached,
+				"share":   c.Share,
+			},
+		})
+	}
+	return writeFeatureCollection(w, features)
+}
+
+func writeHeatmapCSV(w io.Writer, ens drift.Ensemble) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"min_lat", "min_lng", "size_deg", "count", "beached", "share"})
+	for _, c := range ens.Cells {
+		_ = cw.Write([]string{
+			strconv.FormatFloat(c.MinLat, 'f', -1, 64),
+			strconv.FormatFloat(c.MinLng, 'f', -1, 64),
+			strconv.FormatFloat(c.Size, 'f', -1, 64),
+			strconv.Itoa(c.Count),
+			strconv.Itoa(c.Beached),
+			strconv.FormatFloat(c.Share, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
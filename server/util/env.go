@@ -24,3 +24,16 @@ func EnvInt(key string, fallback int) int {
 	}
 	return n
 }
+
+func EnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
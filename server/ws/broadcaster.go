@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Polqt/ocealis/internal/domain"
+	"github.com/Polqt/ocealis/internal/geo"
 	"go.uber.org/zap"
 )
 
@@ -14,6 +16,7 @@ const (
 	MsgBottleDrift      MessageType = "bottle_drift"
 	MsgBottleDiscovered MessageType = "bottle_discovered"
 	MsgBottleReleased   MessageType = "bottle_released"
+	MsgOceanStats       MessageType = "ocean_stats"
 )
 
 // Message is the json envelope every connected client receives.
@@ -33,6 +36,20 @@ type DriftPayload struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// BottlePayload names the Bottle a bottle_discovered or bottle_released message is about.
+type BottlePayload struct {
+	BottleID int32 `json:"bottle_id"`
+}
+
+// Payloads is the wire catalog: the payload each MessageType carries. The OpenAPI
+// document describes the stream from it.
+var Payloads = map[MessageType]any{
+	MsgBottleDrift:      DriftPayload{},
+	MsgBottleDiscovered: BottlePayload{},
+	MsgBottleReleased:   BottlePayload{},
+	MsgOceanStats:       domain.OceanStats{},
+}
+
 // Broadcaster wraps hub with typed, domain-specific messages and payloads, so that other parts of the server can broadcast messages without worrying about the underlying WebSocket implementation.
 // Services call broadcaster to broadcast messages to all connected clients, and the broadcaster translates them into the appropriate format for the hub to send. This separation of concerns allows for cleaner code and easier maintenance.
 type Broadcaster struct {
@@ -62,12 +79,17 @@ func (b *Broadcaster) BroadcastDrift(payload DriftPayload) {
 
 func (b *Broadcaster) BroadcastDiscovered(bottleID int32) {
 	topic := fmt.Sprintf("bottle:%d", bottleID)
-	b.broadcastTopic(topic, MsgBottleDiscovered, map[string]int32{"bottle_id": bottleID})
+	b.broadcastTopic(topic, MsgBottleDiscovered, BottlePayload{BottleID: bottleID})
 }
 
 func (b *Broadcaster) BroadcastReleased(bottleID int32) {
 	// New bottles broadcast globally — everyone might want to see a new bottle appear
-	b.broadcast(MsgBottleReleased, map[string]int32{"bottle_id": bottleID})
+	b.broadcast(MsgBottleReleased, BottlePayload{BottleID: bottleID})
+}
+
+// BroadcastOceanStats pushes the splash globe's counts to every client.
+func (b *Broadcaster) BroadcastOceanStats(stats *domain.OceanStats) {
+	b.broadcast(MsgOceanStats, stats)
 }
 
 func (b *Broadcaster) broadcastTopic(_ string, msgType MessageType, payload any) {
@@ -90,21 +112,7 @@ func (b *Broadcaster) broadcast(msgType MessageType, payload any) {
 	b.hub.Broadcast(data)
 }
 
-// regionForCoords determines the ocean region for given coordinates.
-// This is a simple heuristic and can be improved with more accurate geospatial data if needed.
+// regionForCoords is the region:<basin> topic for given coordinates.
 func regionForCoords(lat, lng float64) string {
-	switch {
-	case lat >= 0 && lng >= -80 && lng <= 0:
-		return "region:north_atlantic"
-	case lat < 0 && lng >= -60 && lng <= 20:
-		return "region:south_atlantic"
-	case lat >= 0 && (lng >= 120 || lng <= -120):
-		return "region:north_pacific"
-	case lat < 0 && (lng >= 150 || lng <= -70):
-		return "region:south_pacific"
-	case lat >= -60 && lat <= 25 && lng >= 40 && lng <= 120:
-		return "region:indian_ocean"
-	default:
-		return "region:other"
-	}
+	return "region:" + geo.Basin(lat, lng)
 }
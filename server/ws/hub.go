@@ -1,6 +1,10 @@
 package ws
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/Polqt/ocealis/internal/metrics"
+)
 
 // Hub maintains the set of all active WebSocket connections and broadcasts messages to the connections.
 type Hub struct {
@@ -20,6 +24,7 @@ func (h *Hub) Register(c *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	h.clients[c] = struct{}{}
+	metrics.WSClients.Set(float64(len(h.clients)))
 }
 
 func (h *Hub) Unregister(c *Client) {
@@ -28,6 +33,7 @@ func (h *Hub) Unregister(c *Client) {
 
 	// Remove from global client list
 	delete(h.clients, c)
+	metrics.WSClients.Set(float64(len(h.clients)))
 
 	// Remove from all topic subscriptions
 	for topic, subs := range h.topics {
@@ -74,6 +80,7 @@ func (h *Hub) Broadcast(msg []byte) {
 		select {
 		case c.send <- msg:
 		default:
+			metrics.WSDroppedSends.WithLabelValues("broadcast").Inc()
 		}
 	}
 }
@@ -92,6 +99,7 @@ func (h *Hub) BroadcastTopic(topic string, msg []byte) {
 		select {
 		case c.send <- msg:
 		default:
+			metrics.WSDroppedSends.WithLabelValues("topic").Inc()
 		}
 	}
 }